@@ -0,0 +1,231 @@
+package eventale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// Aggregate is an event-sourced domain model. Its state is derived by applying
+// the events of its stream in order.
+type Aggregate interface {
+	// Apply mutates the state of the aggregate according to ev. ev is the
+	// decoded value of an event of a registered type, or the raw *Event when
+	// the type is not registered.
+	Apply(ev any) error
+	// Version returns the version of the stream the aggregate was loaded at.
+	Version() uint64
+	SetVersion(v uint64)
+	// Uncommitted returns the events recorded since the aggregate was loaded
+	// or last saved.
+	Uncommitted() []any
+	ClearUncommitted()
+}
+
+// AggregateBase implements the bookkeeping parts of Aggregate. Embed it in
+// domain models, which then only need to implement Apply.
+type AggregateBase struct {
+	version     uint64
+	uncommitted []any
+}
+
+func (b *AggregateBase) Version() uint64 {
+	return b.version
+}
+
+func (b *AggregateBase) SetVersion(v uint64) {
+	b.version = v
+}
+
+func (b *AggregateBase) Uncommitted() []any {
+	return b.uncommitted
+}
+
+func (b *AggregateBase) ClearUncommitted() {
+	b.uncommitted = nil
+}
+
+// Record applies ev to agg, and keeps it as uncommitted until agg is saved.
+// agg is normally the aggregate embedding b.
+func (b *AggregateBase) Record(agg Aggregate, ev any) error {
+	if err := agg.Apply(ev); err != nil {
+		return err
+	}
+	b.uncommitted = append(b.uncommitted, ev)
+	return nil
+}
+
+// Repository loads and saves aggregates of type T, each stored in a stream
+// named "<category>-<id>".
+type Repository[T Aggregate] struct {
+	client        *Client
+	category      string
+	newFn         func() T
+	onConflict    func(attempt int, err error) bool
+	snapshotEvery uint64
+}
+
+// NewRepository creates a repository of aggregates created with newFn. Events
+// are encoded and decoded using the registry of c.
+func NewRepository[T Aggregate](c *Client, category string, newFn func() T, options ...repositoryOpt) *Repository[T] {
+	var opts repositoryOpts
+	for _, opt := range options {
+		opt.apply(&opts)
+	}
+	return &Repository[T]{
+		client:        c,
+		category:      category,
+		newFn:         newFn,
+		onConflict:    opts.onConflict,
+		snapshotEvery: opts.snapshotEvery,
+	}
+}
+
+// Stream returns the name of the stream holding the events of aggregate id.
+func (r *Repository[T]) Stream(id string) string {
+	return r.category + "-" + id
+}
+
+// Load rehydrates aggregate id by applying all events of its stream. When
+// snapshots are enabled, the latest snapshot is restored first, and only the
+// events after it are applied. An aggregate without events is returned at
+// version 0.
+func (r *Repository[T]) Load(ctx context.Context, id string) (T, error) {
+	agg := r.newFn()
+	snapshotter, canSnapshot := any(agg).(Snapshotter)
+
+	var (
+		events []*Event
+		err    error
+	)
+	if r.snapshotEvery > 0 && canSnapshot {
+		var snap *Snapshot
+		snap, events, err = r.client.LoadSnapshot(ctx, r.Stream(id))
+		if err != nil {
+			var zero T
+			return zero, fmt.Errorf("load %s: %w", r.Stream(id), err)
+		}
+		if snap != nil {
+			if snap.Value == nil {
+				var zero T
+				return zero, fmt.Errorf("load %s: snapshot type %q: %w", r.Stream(id), snap.Type, ErrEventTypeUnknown)
+			}
+			if err := snapshotter.RestoreSnapshot(snap.Value); err != nil {
+				var zero T
+				return zero, fmt.Errorf("load %s: restore snapshot: %w", r.Stream(id), err)
+			}
+			agg.SetVersion(snap.Version)
+		}
+	} else {
+		events, err = r.client.ReadStream(ctx, r.Stream(id))
+		if err != nil {
+			var zero T
+			return zero, fmt.Errorf("load %s: %w", r.Stream(id), err)
+		}
+	}
+	for _, ev := range events {
+		if err := agg.Apply(eventValue(ev)); err != nil {
+			var zero T
+			return zero, fmt.Errorf("load %s: apply event %d: %w", r.Stream(id), ev.Version, err)
+		}
+		agg.SetVersion(ev.Version)
+	}
+	return agg, nil
+}
+
+// Save appends the uncommitted events of agg, expecting the stream to still
+// be at the version agg was loaded at. ErrWrongExpectedVersion is returned if
+// other events were appended in the meantime. When the append crosses a
+// multiple of the snapshot interval, a snapshot of agg is written as well.
+func (r *Repository[T]) Save(ctx context.Context, id string, agg T) error {
+	events := agg.Uncommitted()
+	if len(events) == 0 {
+		return nil
+	}
+	prev := agg.Version()
+	res, err := r.client.Append(ctx, r.Stream(id), Exact(prev), events...)
+	if err != nil {
+		return fmt.Errorf("save %s: %w", r.Stream(id), err)
+	}
+	agg.SetVersion(res.Version)
+	agg.ClearUncommitted()
+
+	snapshotter, canSnapshot := any(agg).(Snapshotter)
+	if r.snapshotEvery == 0 || !canSnapshot || prev/r.snapshotEvery == res.Version/r.snapshotEvery {
+		return nil
+	}
+	// The events are saved at this point, so failing to snapshot is not an
+	// error of the save. The next load simply reads more events.
+	state, err := snapshotter.Snapshot()
+	if err == nil {
+		err = r.client.WriteSnapshot(ctx, r.Stream(id), res.Version, state)
+	}
+	if err != nil {
+		r.client.logger.Warn("Failed to write snapshot", slog.String("stream", r.Stream(id)), slog.String("error", err.Error()))
+	}
+	return nil
+}
+
+// Update loads aggregate id, calls fn with it and saves the events recorded by
+// fn. On concurrency conflicts, the repository's conflict callback decides
+// whether to start over from loading the aggregate.
+func (r *Repository[T]) Update(ctx context.Context, id string, fn func(agg T) error) (T, error) {
+	for attempt := 1; ; attempt++ {
+		agg, err := r.Load(ctx, id)
+		if err != nil {
+			return agg, err
+		}
+		if err := fn(agg); err != nil {
+			return agg, err
+		}
+		err = r.Save(ctx, id, agg)
+		if err == nil {
+			return agg, nil
+		}
+		if !errors.Is(err, ErrWrongExpectedVersion) || r.onConflict == nil || !r.onConflict(attempt, err) {
+			return agg, err
+		}
+	}
+}
+
+// eventValue returns the decoded value of ev, or ev itself when its type is
+// not registered.
+func eventValue(ev *Event) any {
+	if ev.Value != nil {
+		return ev.Value
+	}
+	return ev
+}
+
+// RetryOnConflict makes Repository.Update retry when saving fails due to a
+// concurrency conflict, as long as fn returns true. attempt starts at 1.
+func RetryOnConflict(fn func(attempt int, err error) bool) repositoryOpt {
+	return repositoryOptFunc(func(opts *repositoryOpts) {
+		opts.onConflict = fn
+	})
+}
+
+// SnapshotEvery makes the repository snapshot aggregates implementing
+// Snapshotter every n events, and restore them from the latest snapshot when
+// loading.
+func SnapshotEvery(n uint64) repositoryOpt {
+	return repositoryOptFunc(func(opts *repositoryOpts) {
+		opts.snapshotEvery = n
+	})
+}
+
+type repositoryOpts struct {
+	onConflict    func(attempt int, err error) bool
+	snapshotEvery uint64
+}
+
+type repositoryOpt interface {
+	apply(opts *repositoryOpts)
+}
+
+type repositoryOptFunc func(opts *repositoryOpts)
+
+func (f repositoryOptFunc) apply(opts *repositoryOpts) {
+	f(opts)
+}
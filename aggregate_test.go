@@ -0,0 +1,172 @@
+package eventale_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nohns/eventale"
+)
+
+type itemAdded struct {
+	SKU string `json:"sku"`
+}
+
+type orderState struct {
+	Placed bool     `json:"placed"`
+	Items  []string `json:"items"`
+}
+
+type order struct {
+	eventale.AggregateBase
+	placed  bool
+	items   []string
+	applied int
+}
+
+func (o *order) Apply(ev any) error {
+	o.applied++
+	switch ev := ev.(type) {
+	case *orderPlaced:
+		o.placed = true
+	case *itemAdded:
+		o.items = append(o.items, ev.SKU)
+	default:
+		return errors.New("unknown event")
+	}
+	return nil
+}
+
+func (o *order) Snapshot() (any, error) {
+	return &orderState{Placed: o.placed, Items: o.items}, nil
+}
+
+func (o *order) RestoreSnapshot(state any) error {
+	s := state.(*orderState)
+	o.placed, o.items = s.Placed, s.Items
+	return nil
+}
+
+func (o *order) AddItem(sku string) error {
+	if !o.placed {
+		return errors.New("order not placed")
+	}
+	return o.Record(o, &itemAdded{SKU: sku})
+}
+
+func orderClient(t *testing.T, addr string) *eventale.Client {
+	t.Helper()
+	reg := eventale.NewEventRegistry()
+	reg.RegisterJSON("OrderPlaced", orderPlaced{})
+	reg.RegisterJSON("ItemAdded", itemAdded{})
+	reg.RegisterJSON("OrderSnapshot", orderState{})
+	c, err := eventale.Dial(addr, eventale.WithLogger(discardLogger), eventale.WithRegistry(reg))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestRepositoryLoadSave(t *testing.T) {
+	ctx := context.Background()
+	_, addr := startServer(t)
+	repo := eventale.NewRepository(orderClient(t, addr), "order", func() *order { return &order{} })
+
+	o, err := repo.Load(ctx, "1")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if o.Version() != 0 {
+		t.Fatalf("expected new aggregate at version 0, got %d", o.Version())
+	}
+	if err := o.Record(o, &orderPlaced{OrderID: "1"}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if err := o.AddItem("sku-1"); err != nil {
+		t.Fatalf("add item: %v", err)
+	}
+	if err := repo.Save(ctx, "1", o); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if o.Version() != 2 || len(o.Uncommitted()) != 0 {
+		t.Fatalf("unexpected aggregate after save: version %d, %d uncommitted", o.Version(), len(o.Uncommitted()))
+	}
+
+	loaded, err := repo.Load(ctx, "1")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !loaded.placed || len(loaded.items) != 1 || loaded.Version() != 2 {
+		t.Fatalf("unexpected loaded aggregate %+v", loaded)
+	}
+}
+
+func TestRepositoryUpdateRetriesOnConflict(t *testing.T) {
+	ctx := context.Background()
+	_, addr := startServer(t)
+	c := orderClient(t, addr)
+	if _, err := c.Append(ctx, "order-1", 0, &orderPlaced{OrderID: "1"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	var conflicts int
+	repo := eventale.NewRepository(c, "order", func() *order { return &order{} },
+		eventale.RetryOnConflict(func(attempt int, err error) bool {
+			conflicts++
+			return attempt < 3
+		}),
+	)
+
+	attempts := 0
+	o, err := repo.Update(ctx, "1", func(o *order) error {
+		attempts++
+		if attempts == 1 {
+			// Someone else appends between load and save
+			if _, err := c.Append(ctx, "order-1", eventale.AnyVersion, &itemAdded{SKU: "other"}); err != nil {
+				return err
+			}
+		}
+		return o.AddItem("mine")
+	})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if attempts != 2 || conflicts != 1 {
+		t.Fatalf("expected 2 attempts and 1 conflict, got %d and %d", attempts, conflicts)
+	}
+	if o.Version() != 3 || len(o.items) != 2 {
+		t.Fatalf("unexpected aggregate after update: version %d, items %v", o.Version(), o.items)
+	}
+}
+
+func TestRepositorySnapshots(t *testing.T) {
+	ctx := context.Background()
+	_, addr := startServer(t)
+	repo := eventale.NewRepository(orderClient(t, addr), "order", func() *order { return &order{} },
+		eventale.SnapshotEvery(2),
+	)
+
+	o, _ := repo.Load(ctx, "1")
+	o.Record(o, &orderPlaced{OrderID: "1"})
+	if err := repo.Save(ctx, "1", o); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	for _, sku := range []string{"a", "b", "c", "d"} {
+		if _, err := repo.Update(ctx, "1", func(o *order) error { return o.AddItem(sku) }); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+	}
+
+	// Snapshot written at version 4, so only event 5 is applied
+	loaded, err := repo.Load(ctx, "1")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if loaded.applied != 1 {
+		t.Fatalf("expected 1 event applied after snapshot, got %d", loaded.applied)
+	}
+	if loaded.Version() != 5 || !loaded.placed || len(loaded.items) != 4 {
+		t.Fatalf("unexpected loaded aggregate: version %d, items %v", loaded.Version(), loaded.items)
+	}
+}
@@ -0,0 +1,53 @@
+package eventale_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nohns/eventale"
+)
+
+func TestReadAsOf(t *testing.T) {
+	ctx := context.Background()
+	_, addr := startServer(t)
+	c := orderClient(t, addr)
+
+	before := time.Now()
+	if _, err := c.Append(ctx, "order-1", eventale.NoStream, &orderPlaced{OrderID: "1"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	placed := time.Now()
+	if _, err := c.Append(ctx, "order-1", eventale.Exact(1), &itemAdded{SKU: "a"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if _, err := c.Append(ctx, "order-2", eventale.NoStream, &orderPlaced{OrderID: "2"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	events, err := c.ReadStream(ctx, "order-1")
+	if err != nil || len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d (err %v)", len(events), err)
+	}
+	if events[0].Committed.Before(before) || events[0].Committed.After(placed) || events[1].Committed.Before(events[0].Committed) {
+		t.Fatalf("unexpected commit times %v, %v", events[0].Committed, events[1].Committed)
+	}
+
+	events, err = c.ReadStream(ctx, "order-1", eventale.AsOf(placed))
+	if err != nil || len(events) != 1 || events[0].Type != "OrderPlaced" {
+		t.Fatalf("expected only the placed order, got %d events (err %v)", len(events), err)
+	}
+	if events, err := c.ReadStream(ctx, "order-1", eventale.AsOf(before)); err != nil || len(events) != 0 {
+		t.Fatalf("expected no events, got %d (err %v)", len(events), err)
+	}
+
+	for _, dir := range []eventale.Direction{eventale.Forwards, eventale.Backwards} {
+		res, err := c.ReadAll(ctx, 0, dir, eventale.Filter{}, eventale.AsOf(placed))
+		if err != nil {
+			t.Fatalf("read all: %v", err)
+		}
+		if len(res.Events) != 1 || res.Events[0].Stream != "order-1" || !res.End {
+			t.Fatalf("expected only the placed order, got %+v", res)
+		}
+	}
+}
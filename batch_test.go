@@ -0,0 +1,114 @@
+package eventale_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/nohns/eventale"
+)
+
+// startCountingServer serves an in-memory server counting the writes to its
+// connections.
+func startCountingServer(tb testing.TB) (*countingListener, string) {
+	tb.Helper()
+	lnr, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatalf("listen: %v", err)
+	}
+	counting := &countingListener{Listener: lnr}
+	srv := eventale.NewServer(lnr.Addr().String())
+	srv.Logger = discardLogger
+	go srv.Serve(counting)
+	tb.Cleanup(func() { srv.Close() })
+	return counting, lnr.Addr().String()
+}
+
+func TestSubscriptionBatches(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	counting, addr := startCountingServer(t)
+	c := dial(t, addr)
+
+	const n = 300
+	events := make([]any, n)
+	for i := range events {
+		events[i] = eventale.EventData{Type: "Ticked", Data: []byte(fmt.Sprint(i))}
+	}
+	if _, err := c.Append(ctx, "clock-1", eventale.NoStream, events...); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	// Caught up events are pushed in batches, and so are live events
+	writes := counting.writes.Load()
+	batched, err := c.Subscribe(ctx, "clock-1", eventale.After(0))
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	single, err := c.Subscribe(ctx, "clock-1", eventale.After(0), eventale.BatchSize(1))
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if _, err := c.Append(ctx, "clock-1", eventale.Exact(n), events...); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	for _, sub := range []*eventale.Subscription{batched, single} {
+		for i := 0; i < 2*n; i++ {
+			ev, err := sub.Recv(ctx)
+			if err != nil {
+				t.Fatalf("recv: %v", err)
+			}
+			if ev.Version != uint64(i+1) || string(ev.Data) != fmt.Sprint(i%n) {
+				t.Fatalf("expected event %d, got version %d with %q", i+1, ev.Version, ev.Data)
+			}
+		}
+	}
+	if w := counting.writes.Load() - writes; w >= n {
+		t.Fatalf("expected events coalesced, got %d writes for %d events", w, 4*n)
+	}
+}
+
+func BenchmarkSubscription(b *testing.B) {
+	for _, bench := range []struct {
+		name  string
+		batch int
+	}{
+		{name: "single", batch: 1},
+		{name: "batched", batch: 256},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			ctx := context.Background()
+			_, addr := startCountingServer(b)
+			c, err := eventale.Dial(addr, eventale.WithLogger(discardLogger))
+			if err != nil {
+				b.Fatalf("dial: %v", err)
+			}
+			defer c.Close()
+
+			// Events are appended up front, and pushed to the subscription
+			// catching up as fast as it receives them
+			events := make([]any, 500)
+			for i := range events {
+				events[i] = eventale.EventData{Type: "Ticked", Data: []byte(`{"tick":true}`)}
+			}
+			for n := 0; n < b.N; n += len(events) {
+				if _, err := c.Append(ctx, "clock-1", eventale.AnyVersion, events[:min(len(events), b.N-n)]...); err != nil {
+					b.Fatalf("append: %v", err)
+				}
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			sub, err := c.Subscribe(ctx, "clock-1", eventale.After(0), eventale.BatchSize(bench.batch))
+			if err != nil {
+				b.Fatalf("subscribe: %v", err)
+			}
+			for i := 0; i < b.N; i++ {
+				if _, err := sub.Recv(ctx); err != nil {
+					b.Fatalf("recv: %v", err)
+				}
+			}
+		})
+	}
+}
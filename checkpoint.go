@@ -0,0 +1,276 @@
+package eventale
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	eventalepb "github.com/nohns/eventale/gen/v1"
+	"github.com/nohns/eventale/internal/frame"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrLeaseLost is returned when saving the checkpoint of a consumer group
+// while another member holds its lease.
+var ErrLeaseLost = errors.New("lease lost")
+
+// CheckpointStore persists the position up to which a projection has
+// processed the events of all streams.
+type CheckpointStore interface {
+	// LoadCheckpoint returns the checkpoint of projection name, which is 0
+	// when none has been saved.
+	LoadCheckpoint(ctx context.Context, name string) (uint64, error)
+	SaveCheckpoint(ctx context.Context, name string, position uint64) error
+}
+
+// CheckpointLeaser is a CheckpointStore shared by the members of consumer
+// groups. Runners of a projection with the same name form a group, of which
+// only the member holding the lease processes events and saves the
+// checkpoint. The others stand by, and one of them takes over from the
+// checkpoint when the holder stops or fails to renew the lease.
+type CheckpointLeaser interface {
+	CheckpointStore
+	// AcquireLease acquires the lease of group name for ttl, or renews it
+	// when already held, and reports whether it is held.
+	AcquireLease(ctx context.Context, name string, ttl time.Duration) (bool, error)
+	// ReleaseLease releases the lease of group name, if held.
+	ReleaseLease(ctx context.Context, name string) error
+}
+
+// FileCheckpointStore keeps each checkpoint in a file named
+// "<name>.checkpoint" in Dir.
+type FileCheckpointStore struct {
+	Dir string
+}
+
+func (s FileCheckpointStore) LoadCheckpoint(ctx context.Context, name string) (uint64, error) {
+	b, err := os.ReadFile(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("load checkpoint: %v", err)
+	}
+	pos, err := strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("load checkpoint: %v", err)
+	}
+	return pos, nil
+}
+
+func (s FileCheckpointStore) SaveCheckpoint(ctx context.Context, name string, position uint64) error {
+	// Write to a temporary file first, so a crash never leaves a partially
+	// written checkpoint behind.
+	tmp := s.path(name) + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.FormatUint(position, 10)), 0o644); err != nil {
+		return fmt.Errorf("save checkpoint: %v", err)
+	}
+	if err := os.Rename(tmp, s.path(name)); err != nil {
+		return fmt.Errorf("save checkpoint: %v", err)
+	}
+	return nil
+}
+
+func (s FileCheckpointStore) path(name string) string {
+	return filepath.Join(s.Dir, name+".checkpoint")
+}
+
+// SQLiteCheckpointStore keeps checkpoints in a table of a SQLite database,
+// typically the same database holding the read model, so the read model and
+// checkpoint can be updated in one transaction.
+type SQLiteCheckpointStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteCheckpointStore creates the eventale_checkpoints table in db if it
+// does not exist. The SQLite driver must be registered by the caller.
+func NewSQLiteCheckpointStore(db *sql.DB) (*SQLiteCheckpointStore, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS eventale_checkpoints (
+		name     TEXT PRIMARY KEY,
+		position INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("create checkpoint table: %v", err)
+	}
+	return &SQLiteCheckpointStore{db: db}, nil
+}
+
+func (s *SQLiteCheckpointStore) LoadCheckpoint(ctx context.Context, name string) (uint64, error) {
+	var pos uint64
+	err := s.db.QueryRowContext(ctx, "SELECT position FROM eventale_checkpoints WHERE name = ?", name).Scan(&pos)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("load checkpoint: %v", err)
+	}
+	return pos, nil
+}
+
+func (s *SQLiteCheckpointStore) SaveCheckpoint(ctx context.Context, name string, position uint64) error {
+	_, err := s.db.ExecContext(ctx,
+		"INSERT INTO eventale_checkpoints (name, position) VALUES (?, ?) ON CONFLICT (name) DO UPDATE SET position = excluded.position",
+		name, position,
+	)
+	if err != nil {
+		return fmt.Errorf("save checkpoint: %v", err)
+	}
+	return nil
+}
+
+// Checkpoints returns a CheckpointStore keeping checkpoints on the server, so
+// a projection continues where it left off when restarted on another host.
+// To run a projection on several hosts at once, use ConsumerGroup instead.
+func (c *Client) Checkpoints() CheckpointStore {
+	return serverCheckpointStore{c: c}
+}
+
+type serverCheckpointStore struct {
+	c *Client
+}
+
+func (s serverCheckpointStore) LoadCheckpoint(ctx context.Context, name string) (uint64, error) {
+	var res eventalepb.WireCheckpoint
+	req := &eventalepb.WireReadCheckpointRequest{Name: name}
+	if err := s.c.call(ctx, frame.FrameKindReadCheckpoint, req, frame.FrameKindReadCheckpointResult, &res); err != nil {
+		return 0, fmt.Errorf("load checkpoint: %w", err)
+	}
+	return res.Position, nil
+}
+
+func (s serverCheckpointStore) SaveCheckpoint(ctx context.Context, name string, position uint64) error {
+	var res eventalepb.WireCheckpoint
+	req := &eventalepb.WireCheckpoint{Name: name, Position: position}
+	if err := s.c.call(ctx, frame.FrameKindWriteCheckpoint, req, frame.FrameKindCheckpointWritten, &res); err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// ConsumerGroup returns a CheckpointLeaser keeping checkpoints and leases of
+// consumer groups on the server, with member identifying the client in its
+// groups. Members must be unique within a group, e.g. the hostname. The
+// server rejects checkpoints saved by members not holding the lease, so a
+// member which lost it never overwrites the progress of its successor.
+func (c *Client) ConsumerGroup(member string) CheckpointLeaser {
+	return groupCheckpointStore{serverCheckpointStore: serverCheckpointStore{c: c}, member: member}
+}
+
+type groupCheckpointStore struct {
+	serverCheckpointStore
+	member string
+}
+
+func (s groupCheckpointStore) SaveCheckpoint(ctx context.Context, name string, position uint64) error {
+	var res eventalepb.WireCheckpoint
+	req := &eventalepb.WireCheckpoint{Name: name, Position: position, Member: s.member}
+	if err := s.c.call(ctx, frame.FrameKindWriteCheckpoint, req, frame.FrameKindCheckpointWritten, &res); err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (s groupCheckpointStore) AcquireLease(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	if ttl < time.Millisecond {
+		return false, fmt.Errorf("acquire lease: ttl %s below 1ms: %w", ttl, ErrBadRequest)
+	}
+	holder, err := s.lease(ctx, name, uint64(ttl.Milliseconds()))
+	if err != nil {
+		return false, fmt.Errorf("acquire lease: %w", err)
+	}
+	return holder == s.member, nil
+}
+
+func (s groupCheckpointStore) ReleaseLease(ctx context.Context, name string) error {
+	if _, err := s.lease(ctx, name, 0); err != nil {
+		return fmt.Errorf("release lease: %w", err)
+	}
+	return nil
+}
+
+// lease requests the lease of group name for ttl milliseconds, and returns
+// the member holding it.
+func (s groupCheckpointStore) lease(ctx context.Context, name string, ttl uint64) (string, error) {
+	var res eventalepb.WireCheckpointLease
+	req := &eventalepb.WireCheckpointLeaseRequest{Name: name, Member: s.member, Ttl: ttl}
+	if err := s.c.call(ctx, frame.FrameKindAcquireCheckpointLease, req, frame.FrameKindCheckpointLease, &res); err != nil {
+		return "", err
+	}
+	return res.Holder, nil
+}
+
+// checkpointLease is the lease of a consumer group, held by member until
+// expires unless renewed.
+type checkpointLease struct {
+	member  string
+	expires time.Time
+}
+
+// heldLease returns the unexpired lease of group name. s.leaseMu must be held.
+func (s *Server) heldLease(name string, now time.Time) (checkpointLease, bool) {
+	lease, ok := s.leases[name]
+	if ok && !now.Before(lease.expires) {
+		delete(s.leases, name)
+		return checkpointLease{}, false
+	}
+	return lease, ok
+}
+
+// handleCheckpointLease acquires, renews or releases the lease of a consumer
+// group for the member of the request, and responds with the member holding
+// it afterwards.
+func (s *Server) handleCheckpointLease(frm *frame.Frame) (*eventalepb.WireCheckpointLease, error) {
+	var req eventalepb.WireCheckpointLeaseRequest
+	if err := proto.Unmarshal(frm.Payload, &req); err != nil {
+		return nil, fmt.Errorf("decode checkpoint lease: %v: %w", err, errBadRequest)
+	}
+	if req.Name == "" || req.Member == "" {
+		return nil, fmt.Errorf("checkpoint lease without name or member: %w", errBadRequest)
+	}
+
+	s.leaseMu.Lock()
+	defer s.leaseMu.Unlock()
+	now := time.Now()
+	lease, held := s.heldLease(req.Name, now)
+	switch {
+	case req.Ttl == 0:
+		if held && lease.member == req.Member {
+			delete(s.leases, req.Name)
+			held = false
+		}
+	case !held || lease.member == req.Member:
+		if s.leases == nil {
+			s.leases = make(map[string]checkpointLease)
+		}
+		lease = checkpointLease{member: req.Member, expires: now.Add(time.Duration(req.Ttl) * time.Millisecond)}
+		s.leases[req.Name] = lease
+		held = true
+	}
+	res := &eventalepb.WireCheckpointLease{Name: req.Name}
+	if held {
+		res.Holder = lease.member
+	}
+	return res, nil
+}
+
+// writeCheckpoint writes the checkpoint of req, fenced by the lease of its
+// group: a member writes only while holding the lease, and clients outside
+// the group only while no member does.
+func (s *Server) writeCheckpoint(ctx context.Context, req *eventalepb.WireCheckpoint) error {
+	s.leaseMu.Lock()
+	defer s.leaseMu.Unlock()
+	lease, held := s.heldLease(req.Name, time.Now())
+	if held && lease.member != req.Member {
+		return fmt.Errorf("checkpoint %s leased by %s: %w", req.Name, lease.member, ErrLeaseLost)
+	}
+	if !held && req.Member != "" {
+		return fmt.Errorf("checkpoint %s not leased by %s: %w", req.Name, req.Member, ErrLeaseLost)
+	}
+	return s.store.WriteCheckpoint(ctx, req.Name, req.Position)
+}
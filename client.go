@@ -2,28 +2,91 @@ package eventale
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	eventalepb "github.com/nohns/eventale/gen/v1"
+	"github.com/nohns/eventale/internal/auth"
 	"github.com/nohns/eventale/internal/connection"
 	"github.com/nohns/eventale/internal/frame"
+	"github.com/nohns/eventale/internal/uuid"
 	"github.com/nohns/eventale/internal/wire"
 	"google.golang.org/protobuf/proto"
 )
 
 var _networkTimeout = 30 * time.Second
 
-type frameDecoder interface {
-	Decode(ctx context.Context) (*frame.Frame, error)
+const (
+	// The default maximum number of events pushed to a subscription per
+	// frame.
+	_defaultSubscriptionBatch = 256
+	// The default number of events a subscription can receive before
+	// granting more credit.
+	_defaultSubscriptionCredit = 1024
+)
+
+var (
+	// ErrClientClosed is returned from calls on a client after it was closed,
+	// or after its connection to the server was lost.
+	ErrClientClosed = errors.New("client closed")
+	// ErrWrongExpectedVersion is returned when appending to a stream which is
+	// not at the expected version, wrapped in a WrongExpectedVersionError.
+	ErrWrongExpectedVersion = errors.New("wrong expected version")
+	// ErrBadRequest is returned when the server rejects a malformed request.
+	ErrBadRequest = errors.New("bad request")
+	// ErrSubscriptionClosed is returned from Subscription.Recv after the
+	// subscription was closed.
+	ErrSubscriptionClosed = errors.New("subscription closed")
+	// ErrSubscriptionTooSlow is returned from Subscription.Recv after the
+	// server dropped the subscription for falling too far behind, see
+	// DropSlowSubscriptions.
+	ErrSubscriptionTooSlow = errors.New("subscription too slow")
+	// ErrStreamDeleted is returned when appending to or reading a stream
+	// which was hard deleted.
+	ErrStreamDeleted = errors.New("stream deleted")
+	// ErrFrameTooLarge is returned when a peer sends a frame exceeding the
+	// maximum frame size. The connection is closed, as the peer violated the
+	// protocol.
+	ErrFrameTooLarge = frame.ErrFrameTooLarge
+)
+
+// WrongExpectedVersionError is returned when writing to a stream which is not
+// at the expected version. It wraps ErrWrongExpectedVersion, and carries the
+// current version of the stream so callers can decide whether to retry.
+type WrongExpectedVersionError struct {
+	// Actual is the current version of the stream, 0 if it does not exist.
+	Actual uint64
+	msg    string
+}
+
+func (e *WrongExpectedVersionError) Error() string {
+	return e.msg
+}
+
+func (e *WrongExpectedVersionError) Unwrap() error {
+	return ErrWrongExpectedVersion
 }
 
 type Client struct {
-	conn  net.Conn
-	frmer frameDecoder
+	conn     *connection.Conn
+	registry *EventRegistry
+	logger   *slog.Logger
+
+	mu      sync.Mutex
+	pending map[string]chan *frame.Frame
+	subs    map[string]*Subscription
+	err     error
+	done    chan struct{}
 }
 
 func WithContext(ctx context.Context) dialOpt {
@@ -35,65 +98,496 @@ func WithContext(ctx context.Context) dialOpt {
 	})
 }
 
+// WithRegistry sets the registry used to encode appended values and decode
+// read events.
+func WithRegistry(r *EventRegistry) dialOpt {
+	return dialOptFunc(func(opts *dialOpts) {
+		opts.registry = r
+	})
+}
+
+// WithKey authenticates the client with key. The server must have authorized
+// the public key beforehand. Communication is encrypted when a key is used.
+func WithKey(key *rsa.PrivateKey) dialOpt {
+	return dialOptFunc(func(opts *dialOpts) {
+		opts.key = key
+	})
+}
+
+// WithLogger sets the logger of the client.
+func WithLogger(logger *slog.Logger) dialOpt {
+	return dialOptFunc(func(opts *dialOpts) {
+		opts.logger = logger
+	})
+}
+
+// WithMaxFrameSize sets the maximum payload size in bytes of frames the
+// client accepts from the server. The server splits larger payloads into
+// chunks. Defaults to 1 MiB.
+func WithMaxFrameSize(n int) dialOpt {
+	return dialOptFunc(func(opts *dialOpts) {
+		opts.maxFrameSize = n
+	})
+}
+
+// WithMaxPayloadSize sets the maximum size in bytes of a payload the server
+// splits into chunks, which the client reassembles in memory. The connection
+// is closed when the server sends a larger payload. Defaults to 64 MiB.
+func WithMaxPayloadSize(n int) dialOpt {
+	return dialOptFunc(func(opts *dialOpts) {
+		opts.maxPayloadSize = n
+	})
+}
+
+// WithFlushInterval sets how long frames sent by the client are buffered to
+// be coalesced with following frames into a single write. By default frames
+// are written once no more are queued, and never delayed.
+func WithFlushInterval(d time.Duration) dialOpt {
+	return dialOptFunc(func(opts *dialOpts) {
+		opts.flushInterval = d
+	})
+}
+
+// WithFlushSize sets the size in bytes of the write buffer of the connection.
+// Defaults to 32 KiB.
+func WithFlushSize(n int) dialOpt {
+	return dialOptFunc(func(opts *dialOpts) {
+		opts.flushSize = n
+	})
+}
+
+// Dial connects to the server at address, either a TCP host:port or the path
+// of a Unix socket given as "unix:///path/to/socket".
 func Dial(address string, options ...dialOpt) (*Client, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), _networkTimeout)
 	defer cancel()
 
 	opts := dialOpts{
-		ctx: ctx,
+		ctx:                  ctx,
+		compressionThreshold: frame.DefaultCompressionThreshold,
+		dial:                 dialNet,
+		logger: slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+			Level: slog.LevelDebug,
+		})),
 	}
 	for _, opt := range options {
 		opt.apply(&opts)
 	}
 
-	conn, err := net.Dial("tcp", address)
+	conn, err := opts.dial(opts.ctx, address)
 	if err != nil {
 		return nil, err
 	}
 
 	c := &connection.Conn{
-		NetConn: conn,
-		Logger: slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-			Level: slog.LevelDebug,
-		})),
+		NetConn:        conn,
+		Logger:         opts.logger,
+		MaxFrameSize:   opts.maxFrameSize,
+		MaxPayloadSize: opts.maxPayloadSize,
+		FlushInterval:  opts.flushInterval,
+		FlushSize:      opts.flushSize,
+	}
+	if err := handshake(opts.ctx, c, opts); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("client dial: %w", err)
 	}
 
-	// Send hello and receive server hello
-	fmt.Print("send client hello\n")
-	frm, err := frame.Make(frame.FrameKindClientHello, frame.WithProto(&eventalepb.WireClientHello{
+	client := &Client{
+		conn:     c,
+		registry: opts.registry,
+		logger:   opts.logger,
+		pending:  make(map[string]chan *frame.Frame),
+		subs:     make(map[string]*Subscription),
+		done:     make(chan struct{}),
+	}
+	go client.readLoop()
+	go client.heartbeatLoop()
+	return client, nil
+}
+
+// dialNet dials address over TCP, or the Unix socket at the path of a
+// "unix://" address.
+func dialNet(ctx context.Context, address string) (net.Conn, error) {
+	var d net.Dialer
+	if path, ok := strings.CutPrefix(address, "unix://"); ok {
+		return d.DialContext(ctx, "unix", path)
+	}
+	return d.DialContext(ctx, "tcp", address)
+}
+
+// handshake sends the client hello and waits for the server hello. If the
+// server sends back an encryption key, the connection is upgraded to use it,
+// and frames are compressed as negotiated.
+func handshake(ctx context.Context, c *connection.Conn, opts dialOpts) error {
+	hello := &eventalepb.WireClientHello{
 		ClientVersion: &eventalepb.SemanticVersion{
 			Major: 0,
 			Minor: 0,
 			Patch: 1,
 		},
-	}))
+	}
+	if opts.key != nil {
+		fp := auth.Fingerprint(&opts.key.PublicKey)
+		hello.Signature = fp[:]
+	}
+	for _, c := range opts.compressions {
+		hello.Compressions = append(hello.Compressions, eventalepb.WireCompression(c))
+	}
+	hello.MaxFrameSize = uint32(opts.maxFrameSize)
+
+	opts.logger.Debug("send client hello")
+	frm, err := frame.Make(frame.FrameKindClientHello, frame.WithID(uuid.IDer), frame.WithProto(hello))
 	if err != nil {
-		return nil, err
+		return err
 	}
-	if err := c.Send(context.Background(), frm); err != nil {
-		return nil, err
+	if err := c.Send(ctx, frm); err != nil {
+		return err
 	}
 
-	frm, err = frame.NewDecoder(conn).Decode()
+	frm, err = c.Recv(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("client dial: %v", err)
+		return err
+	}
+	if frm.Kind == frame.FrameKindError {
+		return errorFromFrame(frm)
 	}
 	if frm.Kind != frame.FrameKindServerHello {
-		return nil, fmt.Errorf("unexpected frame kind %d after client hello", frm.Kind)
+		return fmt.Errorf("unexpected frame kind %d after client hello", frm.Kind)
 	}
 	var srvhello eventalepb.WireServerHello
 	if err := proto.Unmarshal(frm.Payload, &srvhello); err != nil {
-		return nil, fmt.Errorf("client dial: %v", err)
+		return err
+	}
+	opts.logger.Debug("recv server hello", slog.String("version", wire.SemVerStr(srvhello.ServerVersion)))
+
+	if len(srvhello.EncryptionKey) > 0 {
+		if opts.key == nil {
+			return fmt.Errorf("server sent encryption key, but client has no key")
+		}
+		key, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, opts.key, srvhello.EncryptionKey, nil)
+		if err != nil {
+			return fmt.Errorf("rsa decrypt: %v", err)
+		}
+		if err := c.Upgrade(key); err != nil {
+			return err
+		}
+	}
+	if srvhello.MaxFrameSize > 0 {
+		c.SetPeerMaxFrameSize(int(srvhello.MaxFrameSize))
+	}
+	return c.SetCompression(frame.Compression(srvhello.Compression), opts.compressionThreshold)
+}
+
+// Close closes the connection to the server, ending all subscriptions.
+func (c *Client) Close() error {
+	err := c.conn.Close()
+	<-c.done
+	return err
+}
+
+// Append appends events to the end of stream. Each event is either an
+// EventData, or a value of a Go type registered in the client's registry. The
+// append fails with a *WrongExpectedVersionError unless stream is at
+// expectedVersion.
+//
+// Appending events whose IDs were already appended to stream returns the
+// result of the original append, so appends can safely be retried when the
+// response is lost. Give events stable IDs with WithEventID for this.
+//
+// Events are appended with the metadata carried by ctx, see
+// ContextWithMetadata and CausedBy.
+func (c *Client) Append(ctx context.Context, stream string, expectedVersion ExpectedVersion, events ...any) (*AppendResult, error) {
+	req, err := c.appendRequest(ctx, stream, expectedVersion, events)
+	if err != nil {
+		return nil, err
 	}
-	fmt.Printf("recv server hello - %s\n", wire.SemVerStr(srvhello.ServerVersion))
+	var res eventalepb.WireAppendResult
+	if err := c.call(ctx, frame.FrameKindAppend, req, frame.FrameKindAppendResult, &res); err != nil {
+		return nil, err
+	}
+	return &AppendResult{Version: res.Version, Position: res.Position}, nil
+}
 
-	return &Client{
-		conn: conn,
-	}, nil
+// appendRequest encodes events to be appended to stream, adding the metadata
+// carried by ctx.
+func (c *Client) appendRequest(ctx context.Context, stream string, expectedVersion ExpectedVersion, events []any) (*eventalepb.WireAppendRequest, error) {
+	md := MetadataFromContext(ctx)
+	req := &eventalepb.WireAppendRequest{
+		Stream:          stream,
+		ExpectedVersion: int64(expectedVersion),
+		Events:          make([]*eventalepb.WireEventData, len(events)),
+	}
+	for i, ev := range events {
+		data, err := c.registry.encode(ev)
+		if err != nil {
+			return nil, err
+		}
+		id, err := eventID(data.ID)
+		if err != nil {
+			return nil, err
+		}
+		req.Events[i] = &eventalepb.WireEventData{
+			Id:          id,
+			Type:        data.Type,
+			ContentType: data.ContentType,
+			Data:        data.Data,
+			Subject:     data.Subject,
+			Metadata:    data.Metadata.merge(md).toWire(),
+		}
+	}
+	return req, nil
+}
+
+// ReadStream reads the events of stream in order. By default all events are
+// read, see FromVersion and MaxCount to read a part of the stream, and AsOf to
+// read the stream as it was at an earlier time.
+func (c *Client) ReadStream(ctx context.Context, stream string, options ...readOpt) ([]*Event, error) {
+	var opts readOpts
+	for _, opt := range options {
+		opt.apply(&opts)
+	}
+
+	var events []*Event
+	from := opts.fromVersion
+	for opts.maxCount == 0 || len(events) < opts.maxCount {
+		req := &eventalepb.WireReadStreamRequest{Stream: stream, FromVersion: from, AsOf: opts.asOfWire()}
+		if opts.maxCount > 0 {
+			req.MaxCount = uint32(opts.maxCount - len(events))
+		}
+		var res eventalepb.WireReadStreamResult
+		if err := c.call(ctx, frame.FrameKindReadStream, req, frame.FrameKindReadStreamResult, &res); err != nil {
+			return nil, err
+		}
+		if len(res.Events) == 0 {
+			break
+		}
+		for _, pb := range res.Events {
+			ev := eventFromWire(pb)
+			if err := c.registry.decode(ev); err != nil {
+				return nil, err
+			}
+			events = append(events, ev)
+			from = ev.seq()
+		}
+	}
+	return events, nil
+}
+
+// Subscribe subscribes to events appended to stream, or to all streams when
+// stream is AllStreams. By default only events appended after subscribing are
+// received, see After to catch up on earlier events first.
+func (c *Client) Subscribe(ctx context.Context, stream string, options ...subscribeOpt) (*Subscription, error) {
+	opts := subscribeOpts{batchSize: _defaultSubscriptionBatch, credit: _defaultSubscriptionCredit}
+	for _, opt := range options {
+		opt.apply(&opts)
+	}
+
+	req := &eventalepb.WireSubscribeRequest{Stream: stream, From: opts.after, MaxBatch: uint32(opts.batchSize), Credit: uint32(opts.credit)}
+	frm, err := frame.Make(frame.FrameKindSubscribe, frame.WithID(uuid.IDer), frame.WithProto(req))
+	if err != nil {
+		return nil, err
+	}
+	sub := newSubscription(c, frm, opts.credit)
+
+	// Register the subscription before sending the request, so no events
+	// pushed right after the confirmation are lost.
+	c.mu.Lock()
+	c.subs[frm.ID.String()] = sub
+	c.mu.Unlock()
+
+	res, err := c.roundtrip(ctx, frm)
+	if err == nil && res.Kind != frame.FrameKindSubscribed {
+		err = fmt.Errorf("unexpected frame kind %d in response to subscribe", res.Kind)
+	}
+	if err != nil {
+		c.mu.Lock()
+		delete(c.subs, frm.ID.String())
+		c.mu.Unlock()
+		return nil, err
+	}
+	return sub, nil
+}
+
+// call sends a request frame of kind carrying req, and decodes the response
+// frame of kind reskind into res.
+func (c *Client) call(ctx context.Context, kind frame.FrameKind, req proto.Message, reskind frame.FrameKind, res proto.Message) error {
+	frm, err := frame.Make(kind, frame.WithID(uuid.IDer), frame.WithProto(req))
+	if err != nil {
+		return err
+	}
+	resfrm, err := c.roundtrip(ctx, frm)
+	if err != nil {
+		return err
+	}
+	if resfrm.Kind != reskind {
+		return fmt.Errorf("unexpected frame kind %d in response to %d", resfrm.Kind, kind)
+	}
+	return proto.Unmarshal(resfrm.Payload, res)
+}
+
+// roundtrip sends frm and waits for the frame responding to it. Error frames
+// are turned into errors.
+func (c *Client) roundtrip(ctx context.Context, frm *frame.Frame) (*frame.Frame, error) {
+	resc := make(chan *frame.Frame, 1)
+	id := frm.ID.String()
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return nil, c.err
+	}
+	c.pending[id] = resc
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if err := c.conn.Send(ctx, frm); err != nil {
+		return nil, err
+	}
+	select {
+	case res, ok := <-resc:
+		if !ok {
+			return nil, c.closeErr()
+		}
+		if res.Kind == frame.FrameKindError {
+			return nil, errorFromFrame(res)
+		}
+		return res, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// readLoop receives frames from the server and dispatches them to pending
+// requests and subscriptions until the connection is closed.
+func (c *Client) readLoop() {
+	defer close(c.done)
+	for {
+		frm, err := c.conn.Recv(context.Background())
+		if err != nil {
+			c.shutdown(err)
+			return
+		}
+		if frm.RespondsTo == nil {
+			// Heartbeats and other unsolicited frames
+			continue
+		}
+
+		id := frm.RespondsTo.String()
+		c.mu.Lock()
+		resc, isPending := c.pending[id]
+		sub, isSub := c.subs[id]
+		if isPending {
+			delete(c.pending, id)
+		}
+		c.mu.Unlock()
+
+		switch {
+		case isPending:
+			resc <- frm
+		case isSub:
+			sub.handleFrame(frm)
+		default:
+			c.logger.Debug("dropping frame for unknown request", slog.Int("kind", int(frm.Kind)))
+		}
+	}
+}
+
+// heartbeatLoop keeps the connection alive while no requests are sent.
+func (c *Client) heartbeatLoop() {
+	ticker := time.NewTicker(_networkTimeout / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			frm, err := frame.Make(frame.FrameKindHeartbeat)
+			if err != nil {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), _networkTimeout)
+			if err := c.conn.Send(ctx, frm); err != nil {
+				c.logger.Debug("heartbeat failed", slog.String("error", err.Error()))
+			}
+			cancel()
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// shutdown fails all pending requests and subscriptions with err.
+func (c *Client) shutdown(err error) {
+	if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+		err = ErrClientClosed
+	}
+	c.mu.Lock()
+	c.err = err
+	pending := c.pending
+	subs := c.subs
+	c.pending = make(map[string]chan *frame.Frame)
+	c.subs = make(map[string]*Subscription)
+	c.mu.Unlock()
+
+	for _, resc := range pending {
+		close(resc)
+	}
+	for _, sub := range subs {
+		sub.fail(err)
+	}
+}
+
+func (c *Client) closeErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		return ErrClientClosed
+	}
+	return c.err
+}
+
+// errorFromFrame decodes an error frame into an error, wrapping the sentinel
+// error matching its code.
+func errorFromFrame(frm *frame.Frame) error {
+	var pb eventalepb.WireError
+	if err := proto.Unmarshal(frm.Payload, &pb); err != nil {
+		return fmt.Errorf("decode error frame: %v", err)
+	}
+	switch pb.Code {
+	case eventalepb.WireErrorCode_WIRE_ERROR_CODE_WRONG_EXPECTED_VERSION:
+		return &WrongExpectedVersionError{Actual: pb.ActualVersion, msg: pb.Message}
+	case eventalepb.WireErrorCode_WIRE_ERROR_CODE_UNAUTHORIZED:
+		return fmt.Errorf("%s: %w", pb.Message, ErrUnauthorized)
+	case eventalepb.WireErrorCode_WIRE_ERROR_CODE_BAD_REQUEST:
+		return fmt.Errorf("%s: %w", pb.Message, ErrBadRequest)
+	case eventalepb.WireErrorCode_WIRE_ERROR_CODE_LEASE_LOST:
+		return fmt.Errorf("%s: %w", pb.Message, ErrLeaseLost)
+	case eventalepb.WireErrorCode_WIRE_ERROR_CODE_STREAM_DELETED:
+		return fmt.Errorf("%s: %w", pb.Message, ErrStreamDeleted)
+	case eventalepb.WireErrorCode_WIRE_ERROR_CODE_TRANSACTION_NOT_FOUND:
+		return fmt.Errorf("%s: %w", pb.Message, ErrTransactionNotFound)
+	case eventalepb.WireErrorCode_WIRE_ERROR_CODE_FRAME_TOO_LARGE:
+		return fmt.Errorf("%s: %w", pb.Message, ErrFrameTooLarge)
+	case eventalepb.WireErrorCode_WIRE_ERROR_CODE_SUBSCRIPTION_TOO_SLOW:
+		return fmt.Errorf("%s: %w", pb.Message, ErrSubscriptionTooSlow)
+	}
+	return errors.New(pb.Message)
 }
 
 type dialOpts struct {
-	ctx context.Context
+	ctx      context.Context
+	dial     func(ctx context.Context, address string) (net.Conn, error)
+	registry *EventRegistry
+	key      *rsa.PrivateKey
+	logger   *slog.Logger
+
+	compressions         []Compression
+	compressionThreshold int
+	maxFrameSize         int
+	maxPayloadSize       int
+	flushInterval        time.Duration
+	flushSize            int
 }
 
 type dialOpt interface {
@@ -105,3 +599,52 @@ type dialOptFunc func(opts *dialOpts)
 func (f dialOptFunc) apply(opts *dialOpts) {
 	f(opts)
 }
+
+// FromVersion makes ReadStream read events with a version greater than v.
+func FromVersion(v uint64) readOpt {
+	return readOptFunc(func(opts *readOpts) {
+		opts.fromVersion = v
+	})
+}
+
+// MaxCount makes ReadStream and ReadAll read at most n events.
+func MaxCount(n int) readOpt {
+	return readOptFunc(func(opts *readOpts) {
+		opts.maxCount = n
+	})
+}
+
+// AsOf makes ReadStream and ReadAll read only events committed before t,
+// showing streams as they were at that time. Stream metadata is applied as it
+// is now rather than as it was at t, so events expired or truncated since by
+// max age, max count or truncate-before are not read, and deleted streams
+// stay deleted.
+func AsOf(t time.Time) readOpt {
+	return readOptFunc(func(opts *readOpts) {
+		opts.asOf = t
+	})
+}
+
+type readOpts struct {
+	fromVersion uint64
+	maxCount    int
+	asOf        time.Time
+}
+
+// asOfWire returns the asOf time of a request, which is 0 when unset.
+func (opts readOpts) asOfWire() int64 {
+	if opts.asOf.IsZero() {
+		return 0
+	}
+	return opts.asOf.UnixNano()
+}
+
+type readOpt interface {
+	apply(opts *readOpts)
+}
+
+type readOptFunc func(opts *readOpts)
+
+func (f readOptFunc) apply(opts *readOpts) {
+	f(opts)
+}
@@ -1,4 +1,216 @@
 package eventale_test
 
-func TestDial() {
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nohns/eventale"
+)
+
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+type orderPlaced struct {
+	OrderID string `json:"orderId"`
+}
+
+// startServer serves an in-memory server on a random local port.
+func startServer(t *testing.T) (*eventale.Server, string) {
+	t.Helper()
+	lnr, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := eventale.NewServer(lnr.Addr().String())
+	srv.Logger = discardLogger
+	go srv.Serve(lnr)
+	t.Cleanup(func() { srv.Close() })
+	return srv, lnr.Addr().String()
+}
+
+func dial(t *testing.T, addr string) *eventale.Client {
+	t.Helper()
+	c, err := eventale.Dial(addr, eventale.WithLogger(discardLogger))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestDial(t *testing.T) {
+	_, addr := startServer(t)
+	dial(t, addr)
+}
+
+func TestDialWithKey(t *testing.T) {
+	srv, addr := startServer(t)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	srv.AuthorizeKey(&key.PublicKey)
+
+	if _, err := eventale.Dial(addr, eventale.WithLogger(discardLogger)); !errors.Is(err, eventale.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized without key, got %v", err)
+	}
+
+	c, err := eventale.Dial(addr, eventale.WithLogger(discardLogger), eventale.WithKey(key))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer c.Close()
+	// Requests after the handshake are encrypted
+	if _, err := c.Append(context.Background(), "order-1", 0, eventale.EventData{Type: "A"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+}
+
+func TestClientAppendReadStream(t *testing.T) {
+	ctx := context.Background()
+	_, addr := startServer(t)
+
+	reg := eventale.NewEventRegistry()
+	if err := reg.RegisterJSON("OrderPlaced", orderPlaced{}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	c, err := eventale.Dial(addr, eventale.WithLogger(discardLogger), eventale.WithRegistry(reg))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer c.Close()
+
+	res, err := c.Append(ctx, "order-1", 0,
+		&orderPlaced{OrderID: "1"},
+		eventale.EventData{Type: "OrderNoted", Data: []byte("raw")},
+	)
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if res.Version != 2 {
+		t.Fatalf("expected version 2, got %d", res.Version)
+	}
+	if _, err := c.Append(ctx, "order-1", 1, eventale.EventData{Type: "OrderNoted"}); !errors.Is(err, eventale.ErrWrongExpectedVersion) {
+		t.Fatalf("expected ErrWrongExpectedVersion, got %v", err)
+	}
+
+	events, err := c.ReadStream(ctx, "order-1")
+	if err != nil {
+		t.Fatalf("read stream: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	placed, ok := events[0].Value.(*orderPlaced)
+	if !ok || placed.OrderID != "1" {
+		t.Fatalf("unexpected decoded value %#v", events[0].Value)
+	}
+	// Unknown event types are passed through raw
+	if events[1].Value != nil || string(events[1].Data) != "raw" {
+		t.Fatalf("unexpected raw event %#v", events[1])
+	}
+}
+
+func TestClientConcurrentRequests(t *testing.T) {
+	ctx := context.Background()
+	srv, addr := startServer(t)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	srv.AuthorizeKey(&key.PublicKey)
+	c, err := eventale.Dial(addr, eventale.WithLogger(discardLogger), eventale.WithKey(key), eventale.WithCompression(eventale.Snappy))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer c.Close()
+
+	// Requests share the connection, so frames must be written whole
+	data := bytes.Repeat([]byte("x"), 100<<10)
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(stream string) {
+			defer wg.Done()
+			for v := uint64(0); v < 10; v++ {
+				if _, err := c.Append(ctx, stream, eventale.Exact(v), eventale.EventData{Type: "Uploaded", Data: data}); err != nil {
+					t.Errorf("append: %v", err)
+					return
+				}
+			}
+			if events, err := c.ReadStream(ctx, stream); err != nil || len(events) != 10 {
+				t.Errorf("expected 10 events, got %d (err %v)", len(events), err)
+			}
+		}(fmt.Sprintf("blob-%d", i))
+	}
+	wg.Wait()
+}
+
+func TestClientExpectedVersion(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, addr := startServer(t)
+	c := dial(t, addr)
+
+	ev := eventale.EventData{Type: "OrderNoted"}
+	var werr *eventale.WrongExpectedVersionError
+	if _, err := c.Append(ctx, "order-1", eventale.StreamExists, ev); !errors.As(err, &werr) || werr.Actual != 0 {
+		t.Fatalf("expected wrong expected version at 0, got %v", err)
+	}
+	if _, err := c.Append(ctx, "order-1", eventale.NoStream, ev, ev); err != nil {
+		t.Fatalf("append to no stream: %v", err)
+	}
+	if _, err := c.Append(ctx, "order-1", eventale.StreamExists, ev); err != nil {
+		t.Fatalf("append to existing stream: %v", err)
+	}
+	if _, err := c.Append(ctx, "order-1", eventale.Exact(3), ev); err != nil {
+		t.Fatalf("append at exact version: %v", err)
+	}
+	_, err := c.Append(ctx, "order-1", eventale.NoStream, ev)
+	if !errors.As(err, &werr) || werr.Actual != 4 || !errors.Is(err, eventale.ErrWrongExpectedVersion) {
+		t.Fatalf("expected wrong expected version at 4, got %v", err)
+	}
+}
+
+func TestClientSubscribe(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, addr := startServer(t)
+	c := dial(t, addr)
+
+	if _, err := c.Append(ctx, "order-1", eventale.AnyVersion, eventale.EventData{Type: "A"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	sub, err := c.Subscribe(ctx, eventale.AllStreams, eventale.After(0))
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	defer sub.Close()
+	if _, err := c.Append(ctx, "order-2", eventale.AnyVersion, eventale.EventData{Type: "B"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	for _, want := range []string{"A", "B"} {
+		ev, err := sub.Recv(ctx)
+		if err != nil {
+			t.Fatalf("recv: %v", err)
+		}
+		if ev.Type != want {
+			t.Fatalf("expected event %s, got %s", want, ev.Type)
+		}
+	}
+
+	sub.Close()
+	if _, err := sub.Recv(ctx); !errors.Is(err, eventale.ErrSubscriptionClosed) {
+		t.Fatalf("expected ErrSubscriptionClosed, got %v", err)
+	}
 }
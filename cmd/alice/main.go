@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/nohns/eventale"
 	"github.com/urfave/cli/v2"
 )
 
@@ -18,9 +19,30 @@ func main() {
 		Name:        "alice",
 		Usage:       "ahah test",
 		Description: desc,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "addr",
+				Value:   "127.0.0.1:9999",
+				Usage:   "address of the taled server",
+				EnvVars: []string{"ALICE_ADDR"},
+			},
+		},
+		Commands: []*cli.Command{
+			metadataCommand,
+			readCommand,
+		},
 	}
 
 	if err := app.Run(os.Args); err != nil {
 		fmt.Println(err)
 	}
 }
+
+// dial connects to the server given by the global flags.
+func dial(cctx *cli.Context) (*eventale.Client, error) {
+	c, err := eventale.Dial(cctx.String("addr"), eventale.WithContext(cctx.Context))
+	if err != nil {
+		return nil, fmt.Errorf("dial taled: %v", err)
+	}
+	return c, nil
+}
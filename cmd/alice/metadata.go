@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nohns/eventale"
+	"github.com/urfave/cli/v2"
+)
+
+var metadataCommand = &cli.Command{
+	Name:  "metadata",
+	Usage: "show or change the metadata of a stream",
+	Subcommands: []*cli.Command{
+		{
+			Name:      "get",
+			Usage:     "print the metadata of a stream",
+			ArgsUsage: "<stream>",
+			Action:    getMetadata,
+		},
+		{
+			Name:      "set",
+			Usage:     "replace the metadata of a stream",
+			ArgsUsage: "<stream>",
+			Flags: []cli.Flag{
+				&cli.DurationFlag{Name: "max-age", Usage: "expire events older than this"},
+				&cli.Uint64Flag{Name: "max-count", Usage: "keep only the last n events"},
+				&cli.Uint64Flag{Name: "truncate-before", Usage: "expire events before this version"},
+				&cli.StringSliceFlag{Name: "custom", Usage: "custom key=value pairs"},
+			},
+			Action: setMetadata,
+		},
+	},
+}
+
+func getMetadata(cctx *cli.Context) error {
+	stream := cctx.Args().First()
+	if stream == "" {
+		return fmt.Errorf("missing stream")
+	}
+	c, err := dial(cctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	meta, err := c.ReadStreamMetadata(cctx.Context, stream)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("max-age:         %s\n", meta.MaxAge)
+	fmt.Printf("max-count:       %d\n", meta.MaxCount)
+	fmt.Printf("truncate-before: %d\n", meta.TruncateBefore)
+	keys := make([]string, 0, len(meta.Custom))
+	for k := range meta.Custom {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Printf("%s=%s\n", k, meta.Custom[k])
+	}
+	return nil
+}
+
+func setMetadata(cctx *cli.Context) error {
+	stream := cctx.Args().First()
+	if stream == "" {
+		return fmt.Errorf("missing stream")
+	}
+	meta := eventale.StreamMetadata{
+		MaxAge:         cctx.Duration("max-age"),
+		MaxCount:       cctx.Uint64("max-count"),
+		TruncateBefore: cctx.Uint64("truncate-before"),
+	}
+	for _, kv := range cctx.StringSlice("custom") {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return fmt.Errorf("custom metadata %q is not of the form key=value", kv)
+		}
+		if meta.Custom == nil {
+			meta.Custom = make(map[string]string)
+		}
+		meta.Custom[k] = v
+	}
+
+	c, err := dial(cctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	return c.SetStreamMetadata(cctx.Context, stream, meta)
+}
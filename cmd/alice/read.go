@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nohns/eventale"
+	"github.com/urfave/cli/v2"
+)
+
+var readCommand = &cli.Command{
+	Name:      "read",
+	Usage:     "print the events of a stream, or of all streams with $all",
+	ArgsUsage: "<stream>",
+	Flags: []cli.Flag{
+		&cli.Uint64Flag{Name: "from", Usage: "read events after this version (position for $all)"},
+		&cli.IntFlag{Name: "max", Usage: "read at most n events"},
+		&cli.TimestampFlag{Name: "as-of", Layout: time.RFC3339, Usage: "read only events committed before this time, e.g. 2024-05-01T12:00:00Z"},
+	},
+	Action: read,
+}
+
+func read(cctx *cli.Context) error {
+	stream := cctx.Args().First()
+	if stream == "" {
+		return fmt.Errorf("missing stream")
+	}
+	// The zero time and a max of 0 read everything
+	var asOf time.Time
+	if ts := cctx.Timestamp("as-of"); ts != nil {
+		asOf = *ts
+	}
+	max, from := cctx.Int("max"), cctx.Uint64("from")
+
+	c, err := dial(cctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if stream != eventale.AllStreams {
+		events, err := c.ReadStream(cctx.Context, stream, eventale.FromVersion(from), eventale.MaxCount(max), eventale.AsOf(asOf))
+		if err != nil {
+			return err
+		}
+		for _, ev := range events {
+			printEvent(ev)
+		}
+		return nil
+	}
+
+	// Page through the log until the end, or until enough events were read
+	for n := 0; max == 0 || n < max; {
+		var page int
+		if max > 0 {
+			page = max - n
+		}
+		res, err := c.ReadAll(cctx.Context, from, eventale.Forwards, eventale.Filter{}, eventale.MaxCount(page), eventale.AsOf(asOf))
+		if err != nil {
+			return err
+		}
+		for _, ev := range res.Events {
+			printEvent(ev)
+		}
+		n += len(res.Events)
+		if res.End {
+			break
+		}
+		from = res.Position
+	}
+	return nil
+}
+
+func printEvent(ev *eventale.Event) {
+	data := "<binary>"
+	switch {
+	case ev.Shredded:
+		data = "<shredded>"
+	case utf8.Valid(ev.Data):
+		data = string(ev.Data)
+	}
+	fmt.Printf("%d\t%s@%d\t%s\t%s\t%s\n", ev.Position, ev.Stream, ev.Version, ev.Committed.Format(time.RFC3339Nano), ev.Type, data)
+}
@@ -1,26 +1,161 @@
 package main
 
-import "google.golang.org/protobuf/compiler/protogen"
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode"
+
+	eventalepb "github.com/nohns/eventale/gen/v1"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+const (
+	eventalePackage = protogen.GoImportPath("github.com/nohns/eventale")
+	protoPackage    = protogen.GoImportPath("google.golang.org/protobuf/proto")
+)
 
 func main() {
-	protogen.Options{}.Run(func(gen *protogen.Plugin) error {
-		for _, f := range gen.Files {
-			if !f.Generate {
-				continue
-			}
-			generateFile(gen, f)
+	protogen.Options{}.Run(run)
+}
+
+func run(gen *protogen.Plugin) error {
+	gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+	for _, f := range gen.Files {
+		if !f.Generate {
+			continue
+		}
+		if _, err := generateFile(gen, f); err != nil {
+			return err
 		}
-		return nil
-	})
+	}
+	return nil
+}
+
+// event is a message annotated with the (eventale.event) option.
+type event struct {
+	msg  *protogen.Message
+	name string
 }
 
-func generateFile(gen *protogen.Plugin, file *protogen.File) *protogen.GeneratedFile {
+func generateFile(gen *protogen.Plugin, file *protogen.File) (*protogen.GeneratedFile, error) {
 	filename := file.GeneratedFilenamePrefix + "_eventale_intern.pb.go"
 	g := gen.NewGeneratedFile(filename, file.GoImportPath)
+
+	events, err := collectEvents(file.Messages, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file.Desc.Path(), err)
+	}
+	// Nothing to generate for files without events
+	if len(events) == 0 {
+		g.Skip()
+		return g, nil
+	}
+
 	g.P("// Code generated by protoc-gen-go-eventale-internal. DO NOT EDIT.")
+	g.P("// source: ", file.Desc.Path())
 	g.P()
 	g.P("package ", file.GoPackageName)
 	g.P()
 
-	return g
+	for _, ev := range events {
+		generateEvent(g, ev)
+	}
+
+	g.P("// ", registerFuncName(file), " registers all events declared in ", file.Desc.Path(), " in r.")
+	g.P("func ", registerFuncName(file), "(r *", eventalePackage.Ident("EventRegistry"), ") error {")
+	g.P("for _, ev := range []", eventalePackage.Ident("ProtoEvent"), "{")
+	for _, ev := range events {
+		g.P("(*", ev.msg.GoIdent, ")(nil),")
+	}
+	g.P("} {")
+	g.P("if err := r.RegisterProto(ev); err != nil {")
+	g.P("return err")
+	g.P("}")
+	g.P("}")
+	g.P("return nil")
+	g.P("}")
+
+	return g, nil
+}
+
+func generateEvent(g *protogen.GeneratedFile, ev event) {
+	ident := ev.msg.GoIdent.GoName
+	constName := ident + "EventType"
+
+	g.P("// ", constName, " is the event type name of ", ident, " as stored in the event log.")
+	g.P("const ", constName, " = ", fmt.Sprintf("%q", ev.name))
+	g.P()
+	g.P("// EventType returns the event type name of ", ident, ".")
+	g.P("func (x *", ident, ") EventType() string {")
+	g.P("return ", constName)
+	g.P("}")
+	g.P()
+	g.P("// MarshalEvent encodes x as the payload of an event.")
+	g.P("func (x *", ident, ") MarshalEvent() ([]byte, error) {")
+	g.P("return ", protoPackage.Ident("Marshal"), "(x)")
+	g.P("}")
+	g.P()
+	g.P("// UnmarshalEvent decodes the payload of an event into x.")
+	g.P("func (x *", ident, ") UnmarshalEvent(b []byte) error {")
+	g.P("return ", protoPackage.Ident("Unmarshal"), "(b, x)")
+	g.P("}")
+	g.P()
+}
+
+// collectEvents walks msgs and their nested messages depth-first, returning
+// those annotated as events. seen is used to detect duplicate event type
+// names across the file.
+func collectEvents(msgs []*protogen.Message, seen map[string]string) ([]event, error) {
+	if seen == nil {
+		seen = make(map[string]string)
+	}
+	var events []event
+	for _, msg := range msgs {
+		if msg.Desc.IsMapEntry() {
+			continue
+		}
+		if proto.HasExtension(msg.Desc.Options(), eventalepb.E_Event) {
+			opts := proto.GetExtension(msg.Desc.Options(), eventalepb.E_Event).(*eventalepb.EventOptions)
+			name := opts.GetName()
+			if name == "" {
+				name = string(msg.Desc.FullName())
+			}
+			if other, ok := seen[name]; ok {
+				return nil, fmt.Errorf("event type %q used by both %s and %s", name, other, msg.Desc.FullName())
+			}
+			seen[name] = string(msg.Desc.FullName())
+			events = append(events, event{msg: msg, name: name})
+		}
+
+		nested, err := collectEvents(msg.Messages, seen)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, nested...)
+	}
+	return events, nil
+}
+
+// registerFuncName derives the name of the registration function from the
+// proto file name, so several files can share a Go package. E.g. the file
+// shop/v1/order_events.proto results in RegisterOrderEventsEvents.
+func registerFuncName(file *protogen.File) string {
+	base := strings.TrimSuffix(path.Base(file.Desc.Path()), ".proto")
+	var b strings.Builder
+	upper := true
+	for _, r := range base {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return "Register" + b.String() + "Events"
 }
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	eventalepb "github.com/nohns/eventale/gen/v1"
+	gengo "google.golang.org/protobuf/cmd/protoc-gen-go/internal_gengo"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func eventOpts(name string) *descriptorpb.MessageOptions {
+	opts := &descriptorpb.MessageOptions{}
+	proto.SetExtension(opts, eventalepb.E_Event, &eventalepb.EventOptions{Name: name})
+	return opts
+}
+
+// compile builds the generated files of res in a temporary module, along with
+// the code protoc-gen-go generates for gen and the files of extra, keyed by
+// path. The module requires this one, and files are placed by their import
+// path below example.com/shop.
+func compile(t *testing.T, gen *protogen.Plugin, res *pluginpb.CodeGeneratorResponse, extra map[string]string) {
+	t.Helper()
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatalf("module root: %v", err)
+	}
+	gomod, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		t.Fatalf("read go.mod: %v", err)
+	}
+	gosum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatalf("read go.sum: %v", err)
+	}
+
+	// Requiring the modules of this one keeps the build offline
+	files := map[string]string{
+		"go.mod": strings.Replace(string(gomod), "module github.com/nohns/eventale", "module example.com/shop", 1) +
+			"\nrequire github.com/nohns/eventale v0.0.0\n\nreplace github.com/nohns/eventale => " + root + "\n",
+		"go.sum": string(gosum),
+	}
+	for _, f := range gen.Files {
+		if f.Generate {
+			gengo.GenerateFile(gen, f)
+		}
+	}
+	for _, f := range append(gen.Response().File, res.File...) {
+		files[f.GetName()] = f.GetContent()
+	}
+	for name, content := range extra {
+		files[name] = content
+	}
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, strings.TrimPrefix(name, "example.com/shop/"))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	cmd := exec.Command(goBin, "vet", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generated code does not compile: %v\n%s", err, out)
+	}
+}
+
+func TestGenerateFile(t *testing.T) {
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("shop/v1/orders.proto"),
+		Package: proto.String("shop.v1"),
+		Syntax:  proto.String("proto3"),
+		Options: &descriptorpb.FileOptions{
+			GoPackage: proto.String("example.com/shop/gen/v1;shoppb"),
+		},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("OrderPlaced"), Options: eventOpts("")},
+			{Name: proto.String("OrderShipped"), Options: eventOpts("shop.Shipped")},
+			{Name: proto.String("Address")},
+			{
+				Name:       proto.String("Order"),
+				NestedType: []*descriptorpb.DescriptorProto{{Name: proto.String("Cancelled"), Options: eventOpts("")}},
+			},
+		},
+	}
+	req := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{file.GetName()},
+		ProtoFile:      []*descriptorpb.FileDescriptorProto{file},
+	}
+
+	gen, err := protogen.Options{}.New(req)
+	if err != nil {
+		t.Fatalf("new plugin: %v", err)
+	}
+	if err := run(gen); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	res := gen.Response()
+	if res.Error != nil {
+		t.Fatalf("response error: %s", res.GetError())
+	}
+	if len(res.File) != 1 {
+		t.Fatalf("expected 1 generated file, got %d", len(res.File))
+	}
+	if got := res.File[0].GetName(); got != "example.com/shop/gen/v1/orders_eventale_intern.pb.go" {
+		t.Errorf("unexpected file name %q", got)
+	}
+
+	content := res.File[0].GetContent()
+	for _, want := range []string{
+		`const OrderPlacedEventType = "shop.v1.OrderPlaced"`,
+		`const OrderShippedEventType = "shop.Shipped"`,
+		"func (x *OrderPlaced) MarshalEvent() ([]byte, error)",
+		"func (x *OrderShipped) UnmarshalEvent(b []byte) error",
+		"func RegisterOrdersEvents(r *eventale.EventRegistry) error",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("generated file missing %q", want)
+		}
+	}
+	if strings.Contains(content, "AddressEventType") {
+		t.Error("generated event code for message not annotated as event")
+	}
+
+	// The generated code compiles with the messages generated by
+	// protoc-gen-go, and registers them in an eventale.EventRegistry
+	compile(t, gen, res, map[string]string{"main.go": `package main
+
+import (
+	shoppb "example.com/shop/gen/v1"
+	"github.com/nohns/eventale"
+)
+
+var (
+	_ eventale.ProtoEvent = (*shoppb.OrderPlaced)(nil)
+	_ eventale.ProtoEvent = (*shoppb.Order_Cancelled)(nil)
+)
+
+func main() {
+	r := eventale.NewEventRegistry()
+	if err := shoppb.RegisterOrdersEvents(r); err != nil {
+		panic(err)
+	}
+	if _, err := r.New(shoppb.OrderShippedEventType); err != nil {
+		panic(err)
+	}
+}
+`})
+}
+
+func TestGenerateFileDuplicateEventType(t *testing.T) {
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("dup.proto"),
+		Package: proto.String("dup"),
+		Syntax:  proto.String("proto3"),
+		Options: &descriptorpb.FileOptions{
+			GoPackage: proto.String("example.com/dup"),
+		},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("A"), Options: eventOpts("same")},
+			{Name: proto.String("B"), Options: eventOpts("same")},
+		},
+	}
+	gen, err := protogen.Options{}.New(&pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{file.GetName()},
+		ProtoFile:      []*descriptorpb.FileDescriptorProto{file},
+	})
+	if err != nil {
+		t.Fatalf("new plugin: %v", err)
+	}
+	if err := run(gen); err == nil {
+		t.Fatal("expected error for duplicate event type name")
+	}
+}
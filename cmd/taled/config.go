@@ -0,0 +1,78 @@
+package main
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/nohns/eventale"
+)
+
+// config is the JSON configuration file of taled. Flags given on the command
+// line take precedence over it.
+type config struct {
+	Addr      string              `json:"addr"`
+	WSAddr    string              `json:"wsAddr"`
+	HTTPAddr  string              `json:"httpAddr"`
+	GRPCAddr  string              `json:"grpcAddr"`
+	Socket    string              `json:"socket"`
+	DB        string              `json:"db"`
+	LinkRules []eventale.LinkRule `json:"linkRules"`
+	// AuthorizedKeys are paths of PEM encoded RSA public keys, whose holders
+	// are allowed to connect. Once keys or users are authorized, clients
+	// without an authorized key are rejected on every listener, except for
+	// authorized users on the Unix socket.
+	AuthorizedKeys []string `json:"authorizedKeys"`
+	// AuthorizedUIDs are the users allowed to connect over the Unix socket
+	// without a key. Clients of the other listeners then need one of the
+	// AuthorizedKeys.
+	AuthorizedUIDs []uint32 `json:"authorizedUids"`
+}
+
+func loadConfig(path string) (config, error) {
+	var cfg config
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("read config: %v", err)
+	}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse config %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// loadPublicKey reads the PEM encoded RSA public key at path, in either PKCS
+// #1 or PKIX form.
+func loadPublicKey(path string) (*rsa.PublicKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read key: %v", err)
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("key %s is not PEM encoded", path)
+	}
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		pub, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse key %s: %v", path, err)
+		}
+		return pub, nil
+	case "PUBLIC KEY":
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse key %s: %v", path, err)
+		}
+		rsaPub, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("key %s is not an RSA key", path)
+		}
+		return rsaPub, nil
+	default:
+		return nil, fmt.Errorf("key %s is a %s, not a public key", path, block.Type)
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"log"
 	"log/slog"
 	"os"
@@ -9,12 +11,92 @@ import (
 )
 
 func main() {
+	addr := flag.String("addr", "127.0.0.1:9999", "address to listen on")
+	wsAddr := flag.String("ws-addr", "", "address to serve WebSocket connections on, if any")
+	httpAddr := flag.String("http-addr", "", "address to serve the HTTP/JSON gateway on, if any")
+	grpcAddr := flag.String("grpc-addr", "", "address to serve the gRPC service on, if any")
+	socket := flag.String("socket", "", "path of a Unix socket to listen on, if any")
+	dbPath := flag.String("db", "taled.db", "path of the SQLite database to persist events in")
+	configPath := flag.String("config", "", "path of a JSON config file")
+	flag.Parse()
+
+	var cfg config
+	if *configPath != "" {
+		var err error
+		if cfg, err = loadConfig(*configPath); err != nil {
+			log.Fatalf("Failed to start taled: %v", err)
+		}
+	}
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if cfg.Addr == "" || set["addr"] {
+		cfg.Addr = *addr
+	}
+	if set["ws-addr"] {
+		cfg.WSAddr = *wsAddr
+	}
+	if set["http-addr"] {
+		cfg.HTTPAddr = *httpAddr
+	}
+	if set["grpc-addr"] {
+		cfg.GRPCAddr = *grpcAddr
+	}
+	if set["socket"] {
+		cfg.Socket = *socket
+	}
+	if cfg.DB == "" || set["db"] {
+		cfg.DB = *dbPath
+	}
+
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelDebug,
 	}))
-	srv := eventale.NewServer("127.0.0.1:9999")
+	srv := eventale.NewServer(cfg.Addr)
 	srv.Logger = logger
+	srv.DBPath = cfg.DB
+	srv.LinkRules = cfg.LinkRules
+	for _, path := range cfg.AuthorizedKeys {
+		pub, err := loadPublicKey(path)
+		if err != nil {
+			log.Fatalf("Failed to start taled: %v", err)
+		}
+		srv.AuthorizeKey(pub)
+	}
+	for _, uid := range cfg.AuthorizedUIDs {
+		srv.AuthorizeUID(uid)
+	}
+	if len(cfg.AuthorizedUIDs) > 0 && len(cfg.AuthorizedKeys) == 0 {
+		logger.Warn("Users are authorized without keys, so only the Unix socket accepts clients")
+	}
 
+	if cfg.WSAddr != "" {
+		go func() {
+			if err := srv.ListenAndServeWebSocket(cfg.WSAddr); err != nil && !errors.Is(err, eventale.ErrServerClosed) {
+				log.Fatalf("Failed to serve WebSocket: %v", err)
+			}
+		}()
+	}
+	if cfg.HTTPAddr != "" {
+		go func() {
+			if err := srv.ListenAndServeGateway(cfg.HTTPAddr); err != nil && !errors.Is(err, eventale.ErrServerClosed) {
+				log.Fatalf("Failed to serve HTTP gateway: %v", err)
+			}
+		}()
+	}
+	if cfg.GRPCAddr != "" {
+		go func() {
+			if err := srv.ListenAndServeGRPC(cfg.GRPCAddr); err != nil && !errors.Is(err, eventale.ErrServerClosed) {
+				log.Fatalf("Failed to serve gRPC: %v", err)
+			}
+		}()
+	}
+	if cfg.Socket != "" {
+		go func() {
+			if err := srv.ListenAndServeUnix(cfg.Socket); err != nil && !errors.Is(err, eventale.ErrServerClosed) {
+				log.Fatalf("Failed to serve Unix socket: %v", err)
+			}
+		}()
+	}
 	if err := srv.ListenAndServe(); err != nil {
 		log.Fatalf("Failed to start taled: %v", err)
 	}
@@ -0,0 +1,64 @@
+package eventale
+
+import (
+	eventalepb "github.com/nohns/eventale/gen/v1"
+	"github.com/nohns/eventale/internal/frame"
+)
+
+// Compression is an algorithm frame payloads are compressed with. It is
+// negotiated in the handshake, and only used when both client and server
+// support it.
+type Compression int
+
+const (
+	NoCompression Compression = iota
+	Gzip
+	Zstd
+	Snappy
+)
+
+func (c Compression) String() string {
+	return frame.Compression(c).String()
+}
+
+// WithCompression makes the client advertise algorithms to the server, in
+// order of preference. Once the server picks one, payloads of frames sent by
+// both peers are compressed with it, unless they are smaller than the
+// compression threshold.
+func WithCompression(algorithms ...Compression) dialOpt {
+	return dialOptFunc(func(opts *dialOpts) {
+		opts.compressions = algorithms
+	})
+}
+
+// WithCompressionThreshold sets the size in bytes below which payloads sent
+// by the client are not compressed. Defaults to 512 bytes.
+func WithCompressionThreshold(n int) dialOpt {
+	return dialOptFunc(func(opts *dialOpts) {
+		opts.compressionThreshold = n
+	})
+}
+
+// negotiateCompression picks the first of the algorithms advertised by a
+// client which the server supports.
+func (s *Server) negotiateCompression(advertised []eventalepb.WireCompression) frame.Compression {
+	if s.DisableCompression {
+		return frame.CompressionNone
+	}
+	for _, c := range advertised {
+		switch c {
+		case eventalepb.WireCompression_WIRE_COMPRESSION_GZIP,
+			eventalepb.WireCompression_WIRE_COMPRESSION_ZSTD,
+			eventalepb.WireCompression_WIRE_COMPRESSION_SNAPPY:
+			return frame.Compression(c)
+		}
+	}
+	return frame.CompressionNone
+}
+
+func (s *Server) compressionThreshold() int {
+	if s.CompressionThreshold > 0 {
+		return s.CompressionThreshold
+	}
+	return frame.DefaultCompressionThreshold
+}
@@ -0,0 +1,85 @@
+package eventale_test
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nohns/eventale"
+)
+
+// countingListener counts the bytes read from, and the writes and bytes
+// written to, accepted connections.
+type countingListener struct {
+	net.Listener
+	read    atomic.Int64
+	writes  atomic.Int64
+	written atomic.Int64
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &countingConn{Conn: conn, read: &l.read, writes: &l.writes, written: &l.written}, nil
+}
+
+type countingConn struct {
+	net.Conn
+	read    *atomic.Int64
+	writes  *atomic.Int64
+	written *atomic.Int64
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.read.Add(int64(n))
+	return n, err
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	c.writes.Add(1)
+	n, err := c.Conn.Write(b)
+	c.written.Add(int64(n))
+	return n, err
+}
+
+func TestCompression(t *testing.T) {
+	ctx := context.Background()
+	data := []byte(`[` + strings.Repeat(`{"sku":"abc","qty":1},`, 5000) + `{}]`)
+
+	for _, c := range []eventale.Compression{eventale.NoCompression, eventale.Gzip, eventale.Zstd, eventale.Snappy} {
+		t.Run(c.String(), func(t *testing.T) {
+			lnr, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("listen: %v", err)
+			}
+			counting := &countingListener{Listener: lnr}
+			srv := eventale.NewServer(lnr.Addr().String())
+			srv.Logger = discardLogger
+			go srv.Serve(counting)
+			t.Cleanup(func() { srv.Close() })
+
+			client, err := eventale.Dial(lnr.Addr().String(), eventale.WithLogger(discardLogger), eventale.WithCompression(c))
+			if err != nil {
+				t.Fatalf("dial: %v", err)
+			}
+			defer client.Close()
+
+			if _, err := client.Append(ctx, "order-1", eventale.NoStream, eventale.EventData{Type: "ItemsAdded", Data: data}); err != nil {
+				t.Fatalf("append: %v", err)
+			}
+			events, err := client.ReadStream(ctx, "order-1")
+			if err != nil || len(events) != 1 || string(events[0].Data) != string(data) {
+				t.Fatalf("expected event read back, got %d events (err %v)", len(events), err)
+			}
+			read := counting.read.Load()
+			if compressed := read < int64(len(data)); compressed != (c != eventale.NoCompression) {
+				t.Fatalf("server read %d bytes for %d bytes of data", read, len(data))
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package eventale
+
+import (
+	"context"
+
+	eventalepb "github.com/nohns/eventale/gen/v1"
+	"github.com/nohns/eventale/internal/frame"
+)
+
+// StreamDeletedEventType is the type of the notification received by
+// subscribers of a stream, and of all streams, when the stream is deleted.
+// The Value of the notification is a *StreamDeleted. Notifications are not
+// persisted, and have no position.
+const StreamDeletedEventType = "$streamDeleted"
+
+// StreamDeleted describes a deleted stream.
+type StreamDeleted struct {
+	Stream string
+	// Version the stream was deleted at.
+	Version uint64
+	// Hard is set when the stream was deleted permanently.
+	Hard bool
+}
+
+// DeleteStream deletes stream, failing with a *WrongExpectedVersionError
+// unless it is at expectedVersion.
+//
+// A soft delete hides the events of the stream, but the stream can be
+// appended to again, continuing from the version it was deleted at. A hard
+// delete leaves a tombstone, so later appends and reads fail with
+// ErrStreamDeleted. In both cases the events are purged from storage by the
+// server's scavenger, and snapshots of the stream are removed right away.
+func (c *Client) DeleteStream(ctx context.Context, stream string, expectedVersion ExpectedVersion, hard bool) error {
+	var res eventalepb.WireStreamDeleted
+	req := &eventalepb.WireDeleteStreamRequest{Stream: stream, ExpectedVersion: int64(expectedVersion), Hard: hard}
+	return c.call(ctx, frame.FrameKindDeleteStream, req, frame.FrameKindStreamDeleted, &res)
+}
@@ -0,0 +1,56 @@
+package eventale_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nohns/eventale"
+)
+
+func TestDeleteStream(t *testing.T) {
+	ctx := context.Background()
+	_, addr := startServer(t)
+	c := orderClient(t, addr)
+
+	c.Append(ctx, "order-1", 0, &orderPlaced{OrderID: "1"})
+	c.Append(ctx, "order-2", 0, &orderPlaced{OrderID: "2"})
+	sub, err := c.Subscribe(ctx, "order-1")
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	if err := c.DeleteStream(ctx, "order-1", 0, false); !errors.Is(err, eventale.ErrWrongExpectedVersion) {
+		t.Fatalf("expected ErrWrongExpectedVersion, got %v", err)
+	}
+	if err := c.DeleteStream(ctx, "order-1", 1, false); err != nil {
+		t.Fatalf("soft delete: %v", err)
+	}
+	rctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	ev, err := sub.Recv(rctx)
+	if err != nil {
+		t.Fatalf("recv: %v", err)
+	}
+	if del, ok := ev.Value.(*eventale.StreamDeleted); ev.Type != eventale.StreamDeletedEventType || !ok || del.Version != 1 || del.Hard {
+		t.Fatalf("expected soft delete notification, got %+v", ev)
+	}
+	if events, err := c.ReadStream(ctx, "order-1"); err != nil || len(events) != 0 {
+		t.Fatalf("expected no events, got %+v (err %v)", events, err)
+	}
+	if res, err := c.Append(ctx, "order-1", 1, &orderPlaced{OrderID: "1"}); err != nil || res.Version != 2 {
+		t.Fatalf("expected recreated stream at version 2, got %+v (err %v)", res, err)
+	}
+
+	if err := c.DeleteStream(ctx, "order-2", eventale.AnyVersion, true); err != nil {
+		t.Fatalf("hard delete: %v", err)
+	}
+	if _, err := c.Append(ctx, "order-2", eventale.AnyVersion, &orderPlaced{OrderID: "2"}); !errors.Is(err, eventale.ErrStreamDeleted) {
+		t.Fatalf("expected ErrStreamDeleted on append, got %v", err)
+	}
+	if _, err := c.ReadStream(ctx, "order-2"); !errors.Is(err, eventale.ErrStreamDeleted) {
+		t.Fatalf("expected ErrStreamDeleted on read, got %v", err)
+	}
+}
@@ -1 +1,127 @@
 package eventale
+
+import (
+	"time"
+
+	eventalepb "github.com/nohns/eventale/gen/v1"
+	"github.com/nohns/eventale/internal/uuid"
+)
+
+// AllStreams is the name used to subscribe to events of all streams.
+const AllStreams = "$all"
+
+// ExpectedVersion is the version a stream must be at for a write to succeed.
+// It is either one of the sentinels below, or an exact version given by
+// Exact. Untyped integer constants are exact versions as well.
+type ExpectedVersion int64
+
+const (
+	// NoStream requires the stream to not exist, e.g. when creating a new
+	// aggregate.
+	NoStream ExpectedVersion = 0
+	// AnyVersion writes to a stream regardless of its current version,
+	// including when it does not exist.
+	AnyVersion ExpectedVersion = -1
+	// StreamExists requires the stream to exist, at any version.
+	StreamExists ExpectedVersion = -2
+)
+
+// Exact returns the expected version requiring a stream to be at exactly
+// version. Exact(0) is NoStream.
+func Exact(version uint64) ExpectedVersion {
+	return ExpectedVersion(version)
+}
+
+// EventData is a raw event to be appended to a stream. Values of registered
+// Go types can be appended directly instead, see EventRegistry.
+type EventData struct {
+	// ID optionally identifies the event, and is generated when appending
+	// if empty. See WithEventID.
+	ID          string
+	Type        string
+	ContentType string
+	Data        []byte
+	// Subject identifies the data subject, such as a customer, whose
+	// personal data the event holds. See Client.ForgetSubject.
+	Subject  string
+	Metadata EventMetadata
+}
+
+// Event is an event read from a stream.
+type Event struct {
+	Stream string
+	// Version of the stream this event resulted in, starting at 1.
+	Version uint64
+	// Position of the event in the log of all streams, starting at 1.
+	Position    uint64
+	Type        string
+	ContentType string
+	// Data is the raw encoded payload of the event.
+	Data []byte
+	// Value is the decoded payload, or nil when the event type is not
+	// registered in the client's EventRegistry.
+	Value any
+	// Link is set when the event was read through a link in a derived
+	// stream, such as "$ce-order". All other fields describe the linked
+	// event in its original stream.
+	Link *Link
+	// Subject is the data subject of the event, if any.
+	Subject string
+	// ID is the UUID the event was appended with. It is empty for events
+	// appended without an ID, before IDs were introduced.
+	ID string
+	// Shredded is set when the subject of the event was forgotten. Data and
+	// Value are nil for shredded events.
+	Shredded bool
+	Metadata EventMetadata
+	// Committed is the time the server committed the event. Commit times
+	// increase with positions.
+	Committed time.Time
+}
+
+// Link identifies a link to an event in a derived stream.
+type Link struct {
+	Stream string
+	// Version of the link in the derived stream.
+	Version uint64
+}
+
+// seq returns the version of ev in the stream it was read from.
+func (ev *Event) seq() uint64 {
+	if ev.Link != nil {
+		return ev.Link.Version
+	}
+	return ev.Version
+}
+
+// AppendResult describes the outcome of a successful append.
+type AppendResult struct {
+	// Version of the stream after the append.
+	Version uint64
+	// Position of the last appended event.
+	Position uint64
+}
+
+func eventFromWire(pb *eventalepb.WireEvent) *Event {
+	ev := &Event{
+		Stream:      pb.Stream,
+		Version:     pb.Version,
+		Position:    pb.Position,
+		Type:        pb.Type,
+		ContentType: pb.ContentType,
+		Data:        pb.Data,
+		Subject:     pb.Subject,
+		Shredded:    pb.Shredded,
+		Metadata:    metadataFromWire(pb.Metadata),
+	}
+	if id, err := uuid.FromBytes(pb.Id); err == nil {
+		ev.ID = id.String()
+	}
+	if pb.Link != nil {
+		ev.Link = &Link{Stream: pb.Link.Stream, Version: pb.Link.Version}
+	}
+	if pb.Committed != 0 {
+		ev.Committed = time.Unix(0, pb.Committed)
+	}
+	return ev
+}
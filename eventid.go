@@ -0,0 +1,45 @@
+package eventale
+
+import (
+	"fmt"
+
+	"github.com/nohns/eventale/internal/uuid"
+)
+
+// NewEventID generates a random UUID to identify an event with.
+func NewEventID() (string, error) {
+	id, err := uuid.Gen()
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+// WithEventID wraps an event value, given to Client.Append, to be appended
+// with id, which must be a UUID. Retrying an append with the same IDs does not
+// append the events again.
+func WithEventID(id string, v any) any {
+	return identifiedEvent{id: id, event: v}
+}
+
+type identifiedEvent struct {
+	id    string
+	event any
+}
+
+// eventID returns the binary form of the UUID id, generating a random one if
+// id is empty.
+func eventID(id string) ([]byte, error) {
+	if id == "" {
+		gen, err := uuid.Gen()
+		if err != nil {
+			return nil, fmt.Errorf("generate event id: %v", err)
+		}
+		return gen.Bytes(), nil
+	}
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("event id %q: %w", id, ErrBadRequest)
+	}
+	return parsed.Bytes(), nil
+}
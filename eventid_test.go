@@ -0,0 +1,138 @@
+package eventale_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nohns/eventale"
+)
+
+// lossyProxy forwards connections to addr, discarding everything sent back by
+// the server while drop is set.
+type lossyProxy struct {
+	addr string
+	drop atomic.Bool
+}
+
+func startLossyProxy(t *testing.T, addr string) (*lossyProxy, string) {
+	t.Helper()
+	lnr, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { lnr.Close() })
+	p := &lossyProxy{addr: addr}
+	go func() {
+		for {
+			conn, err := lnr.Accept()
+			if err != nil {
+				return
+			}
+			upstream, err := net.Dial("tcp", p.addr)
+			if err != nil {
+				conn.Close()
+				continue
+			}
+			t.Cleanup(func() { conn.Close(); upstream.Close() })
+			go io.Copy(upstream, conn)
+			go func() {
+				buf := make([]byte, 4096)
+				for {
+					n, err := upstream.Read(buf)
+					if err != nil {
+						conn.Close()
+						return
+					}
+					if p.drop.Load() {
+						continue
+					}
+					if _, err := conn.Write(buf[:n]); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return p, lnr.Addr().String()
+}
+
+func startServerAt(t *testing.T, dbPath string) (*eventale.Server, string) {
+	t.Helper()
+	lnr, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := eventale.NewServer(lnr.Addr().String())
+	srv.Logger = discardLogger
+	srv.DBPath = dbPath
+	go srv.Serve(lnr)
+	t.Cleanup(func() { srv.Close() })
+	return srv, lnr.Addr().String()
+}
+
+func TestAppendRetryAfterLostResponse(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "taled.db")
+	srv, addr := startServerAt(t, dbPath)
+	proxy, proxyAddr := startLossyProxy(t, addr)
+
+	id, err := eventale.NewEventID()
+	if err != nil {
+		t.Fatalf("new event id: %v", err)
+	}
+	ev := eventale.WithEventID(id, &orderPlaced{OrderID: "1"})
+
+	lossy := orderClient(t, proxyAddr)
+	proxy.drop.Store(true)
+	actx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	if _, err := lossy.Append(actx, "order-1", 0, ev); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected lost response, got %v", err)
+	}
+
+	// The retry returns the result of the append whose response was lost
+	c := orderClient(t, addr)
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		events, err := c.ReadStream(ctx, "order-1")
+		if err != nil {
+			t.Fatalf("read stream: %v", err)
+		}
+		if len(events) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for lost append to be committed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	res, err := c.Append(ctx, "order-1", 0, ev)
+	if err != nil {
+		t.Fatalf("retry append: %v", err)
+	}
+	if res.Version != 1 || res.Position != 1 {
+		t.Fatalf("expected original result, got %+v", res)
+	}
+
+	// Also after restarting the server
+	srv.Close()
+	c.Close()
+	_, addr = startServerAt(t, dbPath)
+	c = orderClient(t, addr)
+	if res, err := c.Append(ctx, "order-1", 0, ev); err != nil || res.Version != 1 {
+		t.Fatalf("expected original result after restart, got %+v (err %v)", res, err)
+	}
+	events, err := c.ReadStream(ctx, "order-1")
+	if err != nil || len(events) != 1 || events[0].ID != id {
+		t.Fatalf("expected a single event with id %s, got %+v (err %v)", id, events, err)
+	}
+
+	if _, err := c.Append(ctx, "order-2", 0, ev); !errors.Is(err, eventale.ErrBadRequest) {
+		t.Fatalf("expected ErrBadRequest appending id to other stream, got %v", err)
+	}
+}
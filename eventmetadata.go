@@ -0,0 +1,108 @@
+package eventale
+
+import (
+	"context"
+	"maps"
+
+	eventalepb "github.com/nohns/eventale/gen/v1"
+)
+
+// EventMetadata describes the context an event was appended in. It is stored
+// alongside the event, and returned when reading it.
+type EventMetadata struct {
+	// CorrelationID identifies the chain of events the event is part of,
+	// such as all events of a saga.
+	CorrelationID string
+	// CausationID is the ID of the event that caused the event.
+	CausationID string
+	// Principal identifies who appended the event. The server sets it to the
+	// principal the client authenticated as, and leaves it empty for
+	// anonymous clients, ignoring the principal given.
+	Principal string
+	// Headers holds arbitrary application defined values.
+	Headers map[string]string
+}
+
+// merge returns md with the empty fields filled in from defaults. Headers of
+// md take precedence over those of defaults.
+func (md EventMetadata) merge(defaults EventMetadata) EventMetadata {
+	if md.CorrelationID == "" {
+		md.CorrelationID = defaults.CorrelationID
+	}
+	if md.CausationID == "" {
+		md.CausationID = defaults.CausationID
+	}
+	if md.Principal == "" {
+		md.Principal = defaults.Principal
+	}
+	if len(defaults.Headers) > 0 {
+		headers := maps.Clone(defaults.Headers)
+		maps.Copy(headers, md.Headers)
+		md.Headers = headers
+	}
+	return md
+}
+
+func (md EventMetadata) toWire() *eventalepb.WireEventMetadata {
+	if md.CorrelationID == "" && md.CausationID == "" && md.Principal == "" && len(md.Headers) == 0 {
+		return nil
+	}
+	return &eventalepb.WireEventMetadata{
+		CorrelationId: md.CorrelationID,
+		CausationId:   md.CausationID,
+		Principal:     md.Principal,
+		Headers:       md.Headers,
+	}
+}
+
+func metadataFromWire(pb *eventalepb.WireEventMetadata) EventMetadata {
+	return EventMetadata{
+		CorrelationID: pb.GetCorrelationId(),
+		CausationID:   pb.GetCausationId(),
+		Principal:     pb.GetPrincipal(),
+		Headers:       pb.GetHeaders(),
+	}
+}
+
+type metadataKey struct{}
+
+// ContextWithMetadata returns a copy of ctx carrying md. Events appended with
+// the returned context get the fields of md they do not set themselves, and
+// the headers of md in addition to their own.
+func ContextWithMetadata(ctx context.Context, md EventMetadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, md)
+}
+
+// MetadataFromContext returns the metadata carried by ctx, if any.
+func MetadataFromContext(ctx context.Context) EventMetadata {
+	md, _ := ctx.Value(metadataKey{}).(EventMetadata)
+	return md
+}
+
+// CausedBy returns a copy of ctx for appending events in response to ev.
+// Events appended with the returned context are caused by ev, and share its
+// correlation ID, or use the ID of ev as correlation ID if it has none.
+// Principal and headers carried by ctx are kept.
+//
+// ProjectionRunner passes such a context to event handlers, so correlation is
+// propagated without further work.
+func CausedBy(ctx context.Context, ev *Event) context.Context {
+	md := MetadataFromContext(ctx)
+	md.CausationID = ev.ID
+	md.CorrelationID = ev.Metadata.CorrelationID
+	if md.CorrelationID == "" {
+		md.CorrelationID = ev.ID
+	}
+	return ContextWithMetadata(ctx, md)
+}
+
+// WithMetadata wraps an event value, given to Client.Append, to be appended
+// with md.
+func WithMetadata(md EventMetadata, v any) any {
+	return annotatedEvent{metadata: md, event: v}
+}
+
+type annotatedEvent struct {
+	metadata EventMetadata
+	event    any
+}
@@ -0,0 +1,114 @@
+package eventale_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nohns/eventale"
+)
+
+func TestEventMetadata(t *testing.T) {
+	_, addr := startServer(t)
+	c := orderClient(t, addr)
+	ctx := eventale.ContextWithMetadata(context.Background(), eventale.EventMetadata{
+		CorrelationID: "checkout-1",
+		Principal:     "alice",
+		Headers:       map[string]string{"tenant": "a", "source": "web"},
+	})
+
+	_, err := c.Append(ctx, "order-1", eventale.NoStream,
+		eventale.WithMetadata(eventale.EventMetadata{Headers: map[string]string{"source": "api"}}, &orderPlaced{OrderID: "1"}),
+		eventale.EventData{Type: "OrderNoted", Metadata: eventale.EventMetadata{CorrelationID: "other"}},
+	)
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	events, err := c.ReadStream(context.Background(), "order-1")
+	if err != nil || len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d (err %v)", len(events), err)
+	}
+	md := events[0].Metadata
+	if md.CorrelationID != "checkout-1" || md.Headers["tenant"] != "a" || md.Headers["source"] != "api" {
+		t.Fatalf("unexpected metadata %+v", md)
+	}
+	// Anonymous clients can not claim a principal
+	if md.Principal != "" {
+		t.Fatalf("expected no principal, got %q", md.Principal)
+	}
+	// Metadata of the event takes precedence over the context
+	if md := events[1].Metadata; md.CorrelationID != "other" || md.Headers["source"] != "web" {
+		t.Fatalf("unexpected metadata %+v", md)
+	}
+}
+
+func TestProjectionPropagatesCausation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, addr := startServer(t)
+	c := orderClient(t, addr)
+
+	proj := eventale.NewProjection("fulfilment").
+		Handle("OrderPlaced", func(ctx context.Context, ev *eventale.Event) error {
+			_, err := c.Append(ctx, "shipment-"+ev.Value.(*orderPlaced).OrderID, eventale.NoStream, &itemAdded{SKU: "box"})
+			return err
+		})
+	go eventale.NewProjectionRunner(c, proj, eventale.FileCheckpointStore{Dir: t.TempDir()}).Run(ctx)
+
+	if _, err := c.Append(ctx, "order-1", eventale.NoStream, &orderPlaced{OrderID: "1"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	sub, err := c.Subscribe(ctx, "shipment-1", eventale.After(0))
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	defer sub.Close()
+	shipped, err := sub.Recv(ctx)
+	if err != nil {
+		t.Fatalf("recv: %v", err)
+	}
+	placed, err := c.ReadStream(ctx, "order-1")
+	if err != nil {
+		t.Fatalf("read stream: %v", err)
+	}
+	// The first event of a chain correlates the rest by its ID
+	id := placed[0].ID
+	if md := shipped.Metadata; md.CausationID != id || md.CorrelationID != id {
+		t.Fatalf("expected event caused by and correlated with %s, got %+v", id, md)
+	}
+
+	next := eventale.CausedBy(ctx, shipped)
+	if md := eventale.MetadataFromContext(next); md.CausationID != shipped.ID || md.CorrelationID != id {
+		t.Fatalf("expected correlation kept along the chain, got %+v", md)
+	}
+}
+
+func TestEventMetadataPrincipal(t *testing.T) {
+	srv, addr := startServer(t)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	srv.AuthorizeKey(&key.PublicKey)
+	c, err := eventale.Dial(addr, eventale.WithLogger(discardLogger), eventale.WithKey(key))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer c.Close()
+
+	// Authenticated clients can not claim to be someone else
+	ctx := eventale.ContextWithMetadata(context.Background(), eventale.EventMetadata{Principal: "admin"})
+	if _, err := c.Append(ctx, "order-1", eventale.NoStream, eventale.EventData{Type: "A"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	events, err := c.ReadStream(ctx, "order-1")
+	if err != nil || len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d (err %v)", len(events), err)
+	}
+	if p := events[0].Metadata.Principal; !strings.HasPrefix(p, "key:") {
+		t.Fatalf("expected key principal, got %q", p)
+	}
+}
@@ -0,0 +1,83 @@
+package eventale
+
+import (
+	"context"
+	"sync"
+)
+
+// SlowSubscriptionPolicy decides what happens to a subscription falling
+// more than Server.SubscriptionBuffer events behind the live events.
+type SlowSubscriptionPolicy int
+
+const (
+	// CatchUpSlowSubscriptions drops the buffered live events, and switches
+	// the subscription to read events from storage until it has caught up.
+	// Subscriptions which had stream deletions dropped are ended with
+	// ErrSubscriptionTooSlow, as deletions can not be read from storage.
+	CatchUpSlowSubscriptions SlowSubscriptionPolicy = iota
+	// DropSlowSubscriptions ends the subscription with
+	// ErrSubscriptionTooSlow.
+	DropSlowSubscriptions
+)
+
+// _defaultSubscriptionBuffer is the number of live events buffered for a
+// subscription, unless configured otherwise.
+const _defaultSubscriptionBuffer = 10_000
+
+func (s *Server) subscriptionBuffer() int {
+	if s.SubscriptionBuffer > 0 {
+		return s.SubscriptionBuffer
+	}
+	return _defaultSubscriptionBuffer
+}
+
+// credit counts the events a subscriber is ready to receive. It is granted by
+// the client, and taken by the server before pushing events. A nil *credit is
+// unlimited.
+type credit struct {
+	mu     sync.Mutex
+	n      int
+	notify chan struct{}
+}
+
+func newCredit(n uint32) *credit {
+	if n == 0 {
+		return nil
+	}
+	return &credit{n: int(n), notify: make(chan struct{}, 1)}
+}
+
+func (c *credit) grant(n int) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.n += n
+	c.mu.Unlock()
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+// take takes up to max credit, blocking until some is granted or ctx is done.
+func (c *credit) take(ctx context.Context, max int) (int, error) {
+	if c == nil {
+		return max, nil
+	}
+	for {
+		c.mu.Lock()
+		if n := min(c.n, max); n > 0 {
+			c.n -= n
+			c.mu.Unlock()
+			return n, nil
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-c.notify:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
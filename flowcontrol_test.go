@@ -0,0 +1,106 @@
+package eventale_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/nohns/eventale"
+)
+
+func TestSubscriptionCredit(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	counting, addr := startCountingServer(t)
+	c := dial(t, addr)
+
+	const n = 200
+	data := bytes.Repeat([]byte("x"), 1<<10)
+	events := make([]any, n)
+	for i := range events {
+		events[i] = eventale.EventData{Type: "Uploaded", Data: data}
+	}
+	if _, err := c.Append(ctx, "blob-1", eventale.NoStream, events...); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	// Without receiving, the server pushes no more than the credit
+	written := counting.written.Load()
+	sub, err := c.Subscribe(ctx, "blob-1", eventale.After(0), eventale.Credit(10))
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if w := counting.written.Load() - written; w > 20*int64(len(data)) {
+		t.Fatalf("expected at most 10 events pushed, got %d bytes", w)
+	}
+
+	// Receiving grants more credit
+	for i := 0; i < n; i++ {
+		ev, err := sub.Recv(ctx)
+		if err != nil {
+			t.Fatalf("recv: %v", err)
+		}
+		if ev.Version != uint64(i+1) {
+			t.Fatalf("expected version %d, got %d", i+1, ev.Version)
+		}
+	}
+}
+
+func TestSlowSubscriptions(t *testing.T) {
+	for _, policy := range []eventale.SlowSubscriptionPolicy{eventale.CatchUpSlowSubscriptions, eventale.DropSlowSubscriptions} {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		lnr, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listen: %v", err)
+		}
+		srv := eventale.NewServer(lnr.Addr().String())
+		srv.Logger = discardLogger
+		srv.SubscriptionBuffer = 10
+		srv.SlowSubscriptions = policy
+		go srv.Serve(lnr)
+		t.Cleanup(func() { srv.Close() })
+		c := dial(t, lnr.Addr().String())
+
+		// More live events are published at once than are buffered
+		sub, err := c.Subscribe(ctx, "clock-1", eventale.Credit(5))
+		if err != nil {
+			t.Fatalf("subscribe: %v", err)
+		}
+		events := make([]any, 100)
+		for i := range events {
+			events[i] = eventale.EventData{Type: "Ticked"}
+		}
+		if _, err := c.Append(ctx, "clock-1", eventale.NoStream, events...); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+
+		if policy == eventale.DropSlowSubscriptions {
+			for err == nil {
+				_, err = sub.Recv(ctx)
+			}
+			if !errors.Is(err, eventale.ErrSubscriptionTooSlow) {
+				t.Fatalf("expected ErrSubscriptionTooSlow, got %v", err)
+			}
+			continue
+		}
+
+		// The subscription catches up from storage, and continues live
+		if _, err := c.Append(ctx, "clock-1", eventale.Exact(100), eventale.EventData{Type: "Ticked"}); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+		for i := 0; i < 101; i++ {
+			ev, err := sub.Recv(ctx)
+			if err != nil {
+				t.Fatalf("recv: %v", err)
+			}
+			if ev.Version != uint64(i+1) {
+				t.Fatalf("expected version %d, got %d", i+1, ev.Version)
+			}
+		}
+	}
+}
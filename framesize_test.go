@@ -0,0 +1,81 @@
+package eventale_test
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/nohns/eventale"
+	eventalepb "github.com/nohns/eventale/gen/v1"
+	"github.com/nohns/eventale/internal/frame"
+	"github.com/nohns/eventale/internal/uuid"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestChunkedFrames(t *testing.T) {
+	ctx := context.Background()
+	lnr, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := eventale.NewServer(lnr.Addr().String())
+	srv.Logger = discardLogger
+	srv.MaxFrameSize = 64 << 10
+	go srv.Serve(lnr)
+	t.Cleanup(func() { srv.Close() })
+
+	c, err := eventale.Dial(lnr.Addr().String(), eventale.WithLogger(discardLogger), eventale.WithMaxFrameSize(32<<10))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer c.Close()
+
+	// Payloads larger than the maximum frame size of either side are split
+	// into chunks
+	data := bytes.Repeat([]byte("0123456789"), 100<<10)
+	if _, err := c.Append(ctx, "blob-1", eventale.NoStream, eventale.EventData{Type: "Uploaded", Data: data}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	events, err := c.ReadStream(ctx, "blob-1")
+	if err != nil || len(events) != 1 || !bytes.Equal(events[0].Data, data) {
+		t.Fatalf("expected event read back, got %d events (err %v)", len(events), err)
+	}
+}
+
+func TestFrameTooLarge(t *testing.T) {
+	_, addr := startServer(t)
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// A peer ignoring the maximum frame size gets an error, and is cut off
+	enc := frame.NewEncoder(conn, nil)
+	enc.SetMaxFrameSize(4 * frame.DefaultMaxFrameSize)
+	frm, err := frame.Make(frame.FrameKindClientHello, frame.WithID(uuid.IDer))
+	if err != nil {
+		t.Fatalf("make: %v", err)
+	}
+	frm.Payload = make([]byte, 2*frame.DefaultMaxFrameSize)
+	go enc.Encode(frm)
+
+	dec := frame.NewDecoder(conn, nil)
+	res, err := dec.Decode()
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	var pb eventalepb.WireError
+	if err := proto.Unmarshal(res.Payload, &pb); err != nil || res.Kind != frame.FrameKindError || res.RespondsTo.String() != frm.ID.String() {
+		t.Fatalf("expected error response, got frame %+v (err %v)", res, err)
+	}
+	if pb.Code != eventalepb.WireErrorCode_WIRE_ERROR_CODE_FRAME_TOO_LARGE {
+		t.Fatalf("expected frame too large, got %v", pb.Code)
+	}
+	if _, err := dec.Decode(); err == nil {
+		t.Fatalf("expected connection closed")
+	}
+}
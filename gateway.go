@@ -0,0 +1,637 @@
+package eventale
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	eventalepb "github.com/nohns/eventale/gen/v1"
+	"github.com/nohns/eventale/internal/auth"
+	"github.com/nohns/eventale/internal/store"
+	"github.com/nohns/eventale/internal/uuid"
+)
+
+const (
+	// _gatewayMaxBody is the largest request body accepted by the HTTP
+	// gateway, matching the largest payload a TCP client can send in chunks.
+	_gatewayMaxBody = 64 << 20
+	// _tokenMaxAge is how long before or after signing a bearer token is
+	// accepted, allowing for clock skew between client and server. Tokens
+	// are remembered for as long, to reject them when replayed.
+	_tokenMaxAge = 5 * time.Minute
+	// _gatewayReadHeaderTimeout is how long the gateway waits for the headers
+	// of a request, so idle connections of slow clients are closed.
+	_gatewayReadHeaderTimeout = 10 * time.Second
+	// _sseKeepAlive is the interval of comments sent on idle server-sent
+	// event streams, so proxies do not time them out.
+	_sseKeepAlive = 15 * time.Second
+)
+
+//go:embed openapi.yaml
+var openAPI []byte
+
+// Gateway returns the handler of the HTTP/JSON API, for services without a
+// client for the frame protocol. It appends and reads events of the same
+// store, authenticating requests with bearer tokens signed by the keys
+// authorized with AuthorizeKey. The API is described by the OpenAPI document
+// served at /openapi.yaml.
+func (s *Server) Gateway() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Write(openAPI)
+	})
+	mux.Handle("POST /streams/{stream}", s.gatewayHandler(s.gatewayAppend))
+	mux.Handle("GET /streams/{stream}", s.gatewayHandler(s.gatewayReadStream))
+	mux.Handle("GET /streams/$all", s.gatewayHandler(s.gatewayReadAll))
+	mux.Handle("GET /streams/{stream}/metadata", s.gatewayHandler(s.gatewayReadStreamMetadata))
+	mux.Handle("PUT /streams/{stream}/metadata", s.gatewayHandler(s.gatewayWriteStreamMetadata))
+	mux.Handle("GET /streams/{stream}/subscribe", s.gatewaySubscriptionHandler(s.gatewaySubscribe))
+	return mux
+}
+
+// ListenAndServeGateway listens on the TCP address addr, and serves the HTTP
+// gateway on it, see ServeGateway.
+func (s *Server) ListenAndServeGateway(addr string) error {
+	s.Logger.Info("Listening for HTTP traffic", slog.String("addr", addr))
+	lnr, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.ServeGateway(lnr)
+}
+
+// ServeGateway accepts HTTP connections on lnr, and serves the API of Gateway
+// on them alongside Serve. ServeGateway always returns a non-nil error and
+// closes lnr.
+func (s *Server) ServeGateway(lnr net.Listener) error {
+	return s.serveHTTP(lnr, s.Gateway())
+}
+
+// serveHTTP serves h on lnr until the server is closed.
+func (s *Server) serveHTTP(lnr net.Listener, h http.Handler) error {
+	if err := s.listen(lnr); err != nil {
+		return err
+	}
+	defer s.Close()
+
+	hs := &http.Server{
+		Handler:           h,
+		ReadHeaderTimeout: _gatewayReadHeaderTimeout,
+		ErrorLog:          slog.NewLogLogger(s.Logger.Handler(), slog.LevelWarn),
+	}
+	// Requests still being served, such as server-sent event streams, are
+	// ended with the server
+	go func() {
+		<-s.done
+		hs.Close()
+	}()
+	err := hs.Serve(lnr)
+	if s.readState() == serverStatusClosed {
+		return ErrServerClosed
+	}
+	return err
+}
+
+// gatewayHandler authenticates requests and responds with the error of fn,
+// if any. fn is given the principal of the request.
+func (s *Server) gatewayHandler(fn func(w http.ResponseWriter, r *http.Request, principal string) error) http.Handler {
+	return s.gatewayRoute(fn, false)
+}
+
+// gatewaySubscriptionHandler is gatewayHandler for server-sent event streams.
+// Their tokens are accepted again until they expire, as browsers reconnect
+// with the URL and token the stream was opened with.
+func (s *Server) gatewaySubscriptionHandler(fn func(w http.ResponseWriter, r *http.Request, principal string) error) http.Handler {
+	return s.gatewayRoute(fn, true)
+}
+
+func (s *Server) gatewayRoute(fn func(w http.ResponseWriter, r *http.Request, principal string) error, reuse bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := s.open(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, _gatewayMaxBody)
+		principal, err := s.authenticate(r, reuse)
+		if err == nil {
+			err = fn(w, r, principal)
+		}
+		if err != nil {
+			writeGatewayError(w, err)
+		}
+	})
+}
+
+// authenticate returns the principal of the key the bearer token of r is
+// signed with, given in the Authorization header or, for browsers unable to
+// set it on server-sent event streams, the token query parameter. The key
+// and age of the token are checked before the body of r is read, to verify
+// the token is signed for it. The body is replaced with a reader of the same
+// bytes.
+func (s *Server) authenticate(r *http.Request, reuse bool) (string, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		token = r.URL.Query().Get("token")
+	}
+	tok, err := s.parseToken(token)
+	if err != nil {
+		return "", err
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return "", err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return s.verifyToken(tok, auth.TokenRequest{Method: r.Method, Path: r.URL.EscapedPath(), Body: body}, reuse)
+}
+
+// parseToken decodes token and checks its key and age. An empty token is
+// only allowed when no keys or users have been authorized, and returned as
+// nil.
+func (s *Server) parseToken(token string) (*auth.Token, error) {
+	s.mu.RLock()
+	anonymous := len(s.authedkeys) == 0 && len(s.authedUIDs) == 0
+	s.mu.RUnlock()
+	if token == "" {
+		if anonymous {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("missing bearer token: %w", ErrUnauthorized)
+	}
+
+	tok, err := s.tokens.Parse(token, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrUnauthorized)
+	}
+	return tok, nil
+}
+
+// verifyToken returns the principal of the key tok is signed with, if it is
+// signed for req, or no principal for a nil tok. See auth.TokenVerifier.Verify
+// for reuse.
+func (s *Server) verifyToken(tok *auth.Token, req auth.TokenRequest, reuse bool) (string, error) {
+	if tok == nil {
+		return "", nil
+	}
+	principal, err := s.tokens.Verify(tok, req, reuse, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("%v: %w", err, ErrUnauthorized)
+	}
+	return principal, nil
+}
+
+// gatewayError is the body of error responses.
+type gatewayError struct {
+	// Code is the name of the WireErrorCode of the error, without prefix.
+	Code          string `json:"code"`
+	Message       string `json:"message"`
+	ActualVersion uint64 `json:"actualVersion,omitempty"`
+}
+
+func writeGatewayError(w http.ResponseWriter, err error) {
+	pb := errorToWire(err)
+	status := http.StatusInternalServerError
+	switch pb.Code {
+	case eventalepb.WireErrorCode_WIRE_ERROR_CODE_BAD_REQUEST:
+		status = http.StatusBadRequest
+	case eventalepb.WireErrorCode_WIRE_ERROR_CODE_UNAUTHORIZED:
+		status = http.StatusUnauthorized
+	case eventalepb.WireErrorCode_WIRE_ERROR_CODE_WRONG_EXPECTED_VERSION:
+		status = http.StatusConflict
+	case eventalepb.WireErrorCode_WIRE_ERROR_CODE_STREAM_DELETED:
+		status = http.StatusGone
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		status = http.StatusRequestEntityTooLarge
+	}
+	writeJSON(w, status, gatewayError{
+		Code:          strings.TrimPrefix(pb.Code.String(), "WIRE_ERROR_CODE_"),
+		Message:       pb.Message,
+		ActualVersion: pb.ActualVersion,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// gatewayEvent is the JSON representation of events. Data is embedded as is
+// when it is valid JSON, and encoded in DataBase64 otherwise.
+type gatewayEvent struct {
+	Stream      string           `json:"stream,omitempty"`
+	Version     uint64           `json:"version,omitempty"`
+	Position    uint64           `json:"position,omitempty"`
+	ID          string           `json:"id,omitempty"`
+	Type        string           `json:"type"`
+	ContentType string           `json:"contentType,omitempty"`
+	Data        json.RawMessage  `json:"data,omitempty"`
+	DataBase64  []byte           `json:"dataBase64,omitempty"`
+	Subject     string           `json:"subject,omitempty"`
+	Shredded    bool             `json:"shredded,omitempty"`
+	Metadata    *gatewayMetadata `json:"metadata,omitempty"`
+	Link        *gatewayLink     `json:"link,omitempty"`
+	Committed   *time.Time       `json:"committed,omitempty"`
+}
+
+type gatewayMetadata struct {
+	CorrelationID string            `json:"correlationId,omitempty"`
+	CausationID   string            `json:"causationId,omitempty"`
+	Principal     string            `json:"principal,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+}
+
+type gatewayLink struct {
+	Stream  string `json:"stream"`
+	Version uint64 `json:"version"`
+}
+
+func gatewayEventFromWire(pb *eventalepb.WireEvent) gatewayEvent {
+	committed := time.Unix(0, pb.Committed).UTC()
+	ev := gatewayEvent{
+		Stream:      pb.Stream,
+		Version:     pb.Version,
+		Position:    pb.Position,
+		Type:        pb.Type,
+		ContentType: pb.ContentType,
+		Subject:     pb.Subject,
+		Shredded:    pb.Shredded,
+		Committed:   &committed,
+	}
+	if json.Valid(pb.Data) {
+		ev.Data = pb.Data
+	} else {
+		ev.DataBase64 = pb.Data
+	}
+	if id, err := uuid.FromBytes(pb.Id); err == nil {
+		ev.ID = id.String()
+	}
+	if md := pb.Metadata; md != nil {
+		ev.Metadata = &gatewayMetadata{
+			CorrelationID: md.CorrelationId,
+			CausationID:   md.CausationId,
+			Principal:     md.Principal,
+			Headers:       md.Headers,
+		}
+	}
+	if pb.Link != nil {
+		ev.Link = &gatewayLink{Stream: pb.Link.Stream, Version: pb.Link.Version}
+	}
+	return ev
+}
+
+func gatewayEventsFromWire(pbs []*eventalepb.WireEvent) []gatewayEvent {
+	events := make([]gatewayEvent, len(pbs))
+	for i, pb := range pbs {
+		events[i] = gatewayEventFromWire(pb)
+	}
+	return events
+}
+
+// gatewayAppendRequest is the body of append requests. ExpectedVersion is
+// either an exact version or one of the WireExpectedVersion values, and
+// defaults to any version.
+type gatewayAppendRequest struct {
+	ExpectedVersion *int64         `json:"expectedVersion"`
+	Events          []gatewayEvent `json:"events"`
+}
+
+func (s *Server) gatewayAppend(w http.ResponseWriter, r *http.Request, principal string) error {
+	var body gatewayAppendRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return fmt.Errorf("decode append: %w: %w", err, errBadRequest)
+	}
+	req := &eventalepb.WireAppendRequest{
+		Stream:          r.PathValue("stream"),
+		ExpectedVersion: int64(eventalepb.WireExpectedVersion_WIRE_EXPECTED_VERSION_ANY),
+		Events:          make([]*eventalepb.WireEventData, len(body.Events)),
+	}
+	if body.ExpectedVersion != nil {
+		req.ExpectedVersion = *body.ExpectedVersion
+	}
+	for i, ev := range body.Events {
+		pb := &eventalepb.WireEventData{
+			Type:        ev.Type,
+			ContentType: ev.ContentType,
+			Data:        ev.DataBase64,
+			Subject:     ev.Subject,
+		}
+		if len(ev.Data) > 0 {
+			if len(ev.DataBase64) > 0 {
+				return fmt.Errorf("event %d has both data and dataBase64: %w", i, errBadRequest)
+			}
+			pb.Data = ev.Data
+			if pb.ContentType == "" {
+				pb.ContentType = "application/json"
+			}
+		}
+		if ev.ID != "" {
+			id, err := uuid.Parse(ev.ID)
+			if err != nil {
+				return fmt.Errorf("event %d id %q: %w", i, ev.ID, errBadRequest)
+			}
+			pb.Id = id.Bytes()
+		}
+		if md := ev.Metadata; md != nil {
+			pb.Metadata = &eventalepb.WireEventMetadata{
+				CorrelationId: md.CorrelationID,
+				CausationId:   md.CausationID,
+				Headers:       md.Headers,
+			}
+		}
+		req.Events[i] = pb
+	}
+
+	res, err := s.append(r.Context(), principal, req)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusCreated, map[string]uint64{"version": res.Version, "position": res.Position})
+	return nil
+}
+
+func (s *Server) gatewayReadStream(w http.ResponseWriter, r *http.Request, _ string) error {
+	q := queryParser{values: r.URL.Query()}
+	req := &eventalepb.WireReadStreamRequest{
+		Stream:      r.PathValue("stream"),
+		FromVersion: q.uint("from"),
+		MaxCount:    uint32(q.uint("max")),
+		AsOf:        q.time("asOf"),
+	}
+	if q.err != nil {
+		return q.err
+	}
+	res, err := s.readStream(r.Context(), req)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"events": gatewayEventsFromWire(res.Events)})
+	return nil
+}
+
+func (s *Server) gatewayReadAll(w http.ResponseWriter, r *http.Request, _ string) error {
+	q := queryParser{values: r.URL.Query()}
+	req := &eventalepb.WireReadAllRequest{
+		FromPosition: q.uint("from"),
+		Backwards:    q.bool("backwards"),
+		MaxCount:     uint32(q.uint("max")),
+		AsOf:         q.time("asOf"),
+		Filter: &eventalepb.WireFilter{
+			StreamPrefixes:    q.values["streamPrefix"],
+			EventTypePrefixes: q.values["typePrefix"],
+			StreamRegex:       q.values.Get("streamRegex"),
+			EventTypeRegex:    q.values.Get("typeRegex"),
+		},
+	}
+	if q.err != nil {
+		return q.err
+	}
+	res, err := s.readAll(r.Context(), req)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"events":     gatewayEventsFromWire(res.Events),
+		"checkpoint": res.Checkpoint,
+		"end":        res.End,
+	})
+	return nil
+}
+
+// gatewayStreamMetadata is the JSON representation of stream metadata, with
+// MaxAge in milliseconds.
+type gatewayStreamMetadata struct {
+	MaxAge         uint64            `json:"maxAge,omitempty"`
+	MaxCount       uint64            `json:"maxCount,omitempty"`
+	TruncateBefore uint64            `json:"truncateBefore,omitempty"`
+	Custom         map[string]string `json:"custom,omitempty"`
+}
+
+func (s *Server) gatewayReadStreamMetadata(w http.ResponseWriter, r *http.Request, _ string) error {
+	meta, err := s.readStreamMetadata(r.Context(), r.PathValue("stream"))
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, gatewayStreamMetadata{
+		MaxAge:         meta.MaxAge,
+		MaxCount:       meta.MaxCount,
+		TruncateBefore: meta.TruncateBefore,
+		Custom:         meta.Custom,
+	})
+	return nil
+}
+
+func (s *Server) gatewayWriteStreamMetadata(w http.ResponseWriter, r *http.Request, _ string) error {
+	var body gatewayStreamMetadata
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return fmt.Errorf("decode stream metadata: %w: %w", err, errBadRequest)
+	}
+	if err := s.writeStreamMetadata(r.Context(), &eventalepb.WireStreamMetadata{
+		Stream:         r.PathValue("stream"),
+		MaxAge:         body.MaxAge,
+		MaxCount:       body.MaxCount,
+		TruncateBefore: body.TruncateBefore,
+		Custom:         body.Custom,
+	}); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// gatewaySubscribe streams the events of a stream as server-sent events,
+// starting with the events after the from query parameter when given. The
+// ID of each event is its version, or its position when subscribing to
+// $all, so clients reconnecting with Last-Event-ID resume where they left.
+func (s *Server) gatewaySubscribe(w http.ResponseWriter, r *http.Request, _ string) error {
+	stream := r.PathValue("stream")
+	if stream == AllStreams {
+		stream = ""
+	}
+	q := queryParser{values: r.URL.Query()}
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		q.values.Set("from", id)
+	}
+	var from *uint64
+	if q.values.Has("from") {
+		v := q.uint("from")
+		from = &v
+	}
+	if q.err != nil {
+		return q.err
+	}
+
+	// Register with the broker before catching up, so no events appended in
+	// between are missed
+	sub := s.broker.Subscribe(stream, s.subscriptionBuffer())
+	defer s.broker.Unsubscribe(sub)
+	sink := &sseSink{w: w, rc: http.NewResponseController(w), all: stream == ""}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if err := sink.flush(); err != nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go sink.keepAlive(ctx, cancel)
+	err := s.runSubscription(ctx, &serverSubscription{
+		stream: stream,
+		from:   from,
+		batch:  _maxSubscriptionBatch,
+		cancel: cancel,
+		sink:   sink,
+	}, sub)
+	if err != nil && ctx.Err() == nil {
+		// The response has started, so the error is sent as an event
+		// before closing the stream
+		s.Logger.Error("Subscription failed", slog.String("error", err.Error()))
+		pb := errorToWire(err)
+		sink.event("error", "", gatewayError{Code: strings.TrimPrefix(pb.Code.String(), "WIRE_ERROR_CODE_"), Message: pb.Message})
+	}
+	return nil
+}
+
+// sseSink writes the events of a subscription as server-sent events. Stream
+// deletions are sent as deleted events, and keep-alive comments are written
+// concurrently with events.
+type sseSink struct {
+	mu  sync.Mutex
+	w   http.ResponseWriter
+	rc  *http.ResponseController
+	all bool
+}
+
+func (ss *sseSink) push(ctx context.Context, events []store.Event) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	for _, ev := range events {
+		id := ev.Seq()
+		if ss.all {
+			id = ev.Position
+		}
+		if err := ss.write("", strconv.FormatUint(id, 10), gatewayEventFromWire(eventToWire(ev))); err != nil {
+			return err
+		}
+	}
+	return ss.flush()
+}
+
+func (ss *sseSink) deleted(ctx context.Context, del *eventalepb.WireStreamDeleted) error {
+	return ss.event("deleted", "", map[string]any{"stream": del.Stream, "version": del.Version, "hard": del.Hard})
+}
+
+// event writes and flushes a single event of type typ.
+func (ss *sseSink) event(typ, id string, v any) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if err := ss.write(typ, id, v); err != nil {
+		return err
+	}
+	return ss.flush()
+}
+
+// write writes an event with v as JSON data. ss.mu must be held.
+func (ss *sseSink) write(typ, id string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var buf []byte
+	if typ != "" {
+		buf = append(append(append(buf, "event: "...), typ...), '\n')
+	}
+	if id != "" {
+		buf = append(append(append(buf, "id: "...), id...), '\n')
+	}
+	buf = append(append(append(buf, "data: "...), b...), "\n\n"...)
+	_, err = ss.w.Write(buf)
+	return err
+}
+
+func (ss *sseSink) flush() error {
+	return ss.rc.Flush()
+}
+
+// keepAlive writes a comment whenever the stream has been idle for a while,
+// until ctx is done. cancel is called when the client is gone.
+func (ss *sseSink) keepAlive(ctx context.Context, cancel context.CancelFunc) {
+	ticker := time.NewTicker(_sseKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		ss.mu.Lock()
+		_, err := ss.w.Write([]byte(": keep-alive\n\n"))
+		if err == nil {
+			err = ss.flush()
+		}
+		ss.mu.Unlock()
+		if err != nil {
+			cancel()
+			return
+		}
+	}
+}
+
+// queryParser parses query parameters, keeping the first error.
+type queryParser struct {
+	values url.Values
+	err    error
+}
+
+func (q *queryParser) uint(key string) uint64 {
+	v := q.values.Get(key)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil && q.err == nil {
+		q.err = fmt.Errorf("query parameter %s: %w", key, errBadRequest)
+	}
+	return n
+}
+
+func (q *queryParser) bool(key string) bool {
+	v := q.values.Get(key)
+	if v == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil && q.err == nil {
+		q.err = fmt.Errorf("query parameter %s: %w", key, errBadRequest)
+	}
+	return b
+}
+
+// time parses an RFC 3339 time into Unix nanoseconds.
+func (q *queryParser) time(key string) int64 {
+	v := q.values.Get(key)
+	if v == "" {
+		return 0
+	}
+	t, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil && q.err == nil {
+		q.err = fmt.Errorf("query parameter %s: %w", key, errBadRequest)
+	}
+	return t.UnixNano()
+}
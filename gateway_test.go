@@ -0,0 +1,360 @@
+package eventale_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nohns/eventale"
+	"github.com/nohns/eventale/internal/auth"
+)
+
+// gatewayToken returns the bearer token of a request, or "" for none.
+type gatewayToken func(t *testing.T, req auth.TokenRequest) string
+
+// signedBy signs requests with key, as if at now plus skew.
+func signedBy(key *rsa.PrivateKey, skew time.Duration) gatewayToken {
+	return func(t *testing.T, req auth.TokenRequest) string {
+		t.Helper()
+		tok, err := auth.SignToken(key, time.Now().Add(skew), req)
+		if err != nil {
+			t.Fatalf("sign token: %v", err)
+		}
+		return tok
+	}
+}
+
+// gatewayDo sends a request with a JSON body to the gateway, decoding the
+// JSON response into res, and returns the status code.
+func gatewayDo(t *testing.T, method, url string, token gatewayToken, body any, res any) int {
+	t.Helper()
+	var b []byte
+	if body != nil {
+		var err error
+		if b, err = json.Marshal(body); err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+	}
+	req, err := http.NewRequest(method, url, bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	if token != nil {
+		tok := token(t, auth.TokenRequest{Method: method, Path: req.URL.EscapedPath(), Body: b})
+		req.Header.Set("Authorization", "Bearer "+tok)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	if res != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(res); err != nil {
+			t.Fatalf("decode %s %s response: %v", method, url, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+type gatewayEvents struct {
+	Events []struct {
+		Stream     string          `json:"stream"`
+		Version    uint64          `json:"version"`
+		Position   uint64          `json:"position"`
+		Type       string          `json:"type"`
+		Data       json.RawMessage `json:"data"`
+		DataBase64 []byte          `json:"dataBase64"`
+		Metadata   struct {
+			Principal     string `json:"principal"`
+			CorrelationID string `json:"correlationId"`
+		} `json:"metadata"`
+	} `json:"events"`
+	End bool `json:"end"`
+}
+
+func TestGateway(t *testing.T) {
+	ctx := context.Background()
+	srv, addr := startServer(t)
+	hs := httptest.NewServer(srv.Gateway())
+	defer hs.Close()
+
+	var appended struct{ Version, Position uint64 }
+	status := gatewayDo(t, "POST", hs.URL+"/streams/order-1", nil, map[string]any{
+		"expectedVersion": 0,
+		"events": []map[string]any{
+			{"type": "OrderPlaced", "data": map[string]string{"orderId": "1"}, "metadata": map[string]string{"correlationId": "c-1"}},
+			{"type": "Attachment", "dataBase64": []byte{0xff, 0x00}},
+		},
+	}, &appended)
+	if status != http.StatusCreated || appended.Version != 2 {
+		t.Fatalf("expected version 2 created, got %d %+v", status, appended)
+	}
+	var conflict struct {
+		Code          string `json:"code"`
+		ActualVersion uint64 `json:"actualVersion"`
+	}
+	status = gatewayDo(t, "POST", hs.URL+"/streams/order-1", nil, map[string]any{
+		"expectedVersion": 0,
+		"events":          []map[string]any{{"type": "OrderPlaced"}},
+	}, &conflict)
+	if status != http.StatusConflict || conflict.Code != "WRONG_EXPECTED_VERSION" || conflict.ActualVersion != 2 {
+		t.Fatalf("expected conflict at version 2, got %d %+v", status, conflict)
+	}
+
+	// Events appended over HTTP are the same as over TCP
+	c := dial(t, addr)
+	events, err := c.ReadStream(ctx, "order-1")
+	if err != nil || len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d (err %v)", len(events), err)
+	}
+	if string(events[0].Data) != `{"orderId":"1"}` || events[0].ContentType != "application/json" || events[0].Metadata.CorrelationID != "c-1" {
+		t.Fatalf("unexpected event %+v", events[0])
+	}
+	if _, err := c.Append(ctx, "order-2", eventale.NoStream, eventale.EventData{Type: "OrderPlaced", Data: []byte(`{"orderId":"2"}`)}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	var read gatewayEvents
+	if status := gatewayDo(t, "GET", hs.URL+"/streams/order-1?from=1", nil, nil, &read); status != http.StatusOK {
+		t.Fatalf("read stream: status %d", status)
+	}
+	if len(read.Events) != 1 || read.Events[0].Version != 2 || string(read.Events[0].DataBase64) != "\xff\x00" {
+		t.Fatalf("expected the attachment, got %+v", read.Events)
+	}
+	read = gatewayEvents{}
+	if status := gatewayDo(t, "GET", hs.URL+"/streams/$all?typePrefix=Order", nil, nil, &read); status != http.StatusOK {
+		t.Fatalf("read all: status %d", status)
+	}
+	if len(read.Events) != 2 || read.Events[1].Stream != "order-2" || string(read.Events[1].Data) != `{"orderId":"2"}` || !read.End {
+		t.Fatalf("expected both orders placed, got %+v", read)
+	}
+	if status := gatewayDo(t, "GET", hs.URL+"/streams/order-1?max=x", nil, nil, &conflict); status != http.StatusBadRequest {
+		t.Fatalf("expected bad request, got %d", status)
+	}
+
+	meta := map[string]any{"maxCount": 10, "custom": map[string]string{"owner": "sales"}}
+	if status := gatewayDo(t, "PUT", hs.URL+"/streams/order-1/metadata", nil, meta, nil); status != http.StatusNoContent {
+		t.Fatalf("write metadata: status %d", status)
+	}
+	md, err := c.ReadStreamMetadata(ctx, "order-1")
+	if err != nil || md.MaxCount != 10 || md.Custom["owner"] != "sales" {
+		t.Fatalf("unexpected metadata %+v (err %v)", md, err)
+	}
+	var readMeta struct {
+		MaxCount uint64            `json:"maxCount"`
+		Custom   map[string]string `json:"custom"`
+	}
+	if status := gatewayDo(t, "GET", hs.URL+"/streams/order-1/metadata", nil, nil, &readMeta); status != http.StatusOK || readMeta.MaxCount != 10 {
+		t.Fatalf("read metadata: status %d %+v", status, readMeta)
+	}
+}
+
+func TestGatewayAuth(t *testing.T) {
+	srv, _ := startServer(t)
+	hs := httptest.NewServer(srv.Gateway())
+	defer hs.Close()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	srv.AuthorizeKey(&key.PublicKey)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	body := map[string]any{"events": []map[string]any{{"type": "A"}}}
+	if status := gatewayDo(t, "POST", hs.URL+"/streams/a-1", nil, body, nil); status != http.StatusUnauthorized {
+		t.Fatalf("expected unauthorized without token, got %d", status)
+	}
+	for name, token := range map[string]gatewayToken{
+		"unauthorized key": signedBy(other, 0),
+		"expired":          signedBy(key, -time.Hour),
+		"other request": func(t *testing.T, req auth.TokenRequest) string {
+			req.Path = "/streams/a-2"
+			return signedBy(key, 0)(t, req)
+		},
+	} {
+		if status := gatewayDo(t, "POST", hs.URL+"/streams/a-1", token, body, nil); status != http.StatusUnauthorized {
+			t.Fatalf("%s: expected unauthorized, got %d", name, status)
+		}
+	}
+
+	var tok string
+	once := func(t *testing.T, req auth.TokenRequest) string {
+		if tok == "" {
+			tok = signedBy(key, 0)(t, req)
+		}
+		return tok
+	}
+	if status := gatewayDo(t, "POST", hs.URL+"/streams/a-1", once, body, nil); status != http.StatusCreated {
+		t.Fatalf("expected created, got %d", status)
+	}
+	// Tokens are only accepted once
+	if status := gatewayDo(t, "POST", hs.URL+"/streams/a-1", once, body, nil); status != http.StatusUnauthorized {
+		t.Fatalf("expected unauthorized replay, got %d", status)
+	}
+	var read gatewayEvents
+	if status := gatewayDo(t, "GET", hs.URL+"/streams/a-1", signedBy(key, 0), nil, &read); status != http.StatusOK {
+		t.Fatalf("read stream: status %d", status)
+	}
+	if len(read.Events) != 1 || read.Events[0].Metadata.Principal != auth.KeyPrincipal(auth.Fingerprint(&key.PublicKey)) {
+		t.Fatalf("expected event appended by the key principal, got %+v", read.Events)
+	}
+}
+
+func TestGatewaySubscribe(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	srv, addr := startServer(t)
+	hs := httptest.NewServer(srv.Gateway())
+	defer hs.Close()
+	c := dial(t, addr)
+	if _, err := c.Append(ctx, "order-1", eventale.NoStream, eventale.EventData{Type: "OrderPlaced", Data: []byte(`{}`)}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	subscribe := func(path, lastID string) (*bufio.Scanner, func()) {
+		return gatewaySubscribe(t, ctx, hs.URL+path, lastID)
+	}
+	next := func(sc *bufio.Scanner) (string, map[string]any) {
+		t.Helper()
+		return nextGatewayEvent(t, sc)
+	}
+
+	sc, stop := subscribe("/streams/order-1/subscribe?from=0", "")
+	defer stop()
+	if id, ev := next(sc); id != "1" || ev["type"] != "OrderPlaced" {
+		t.Fatalf("expected caught up event 1, got %s %v", id, ev)
+	}
+	if _, err := c.Append(ctx, "order-1", eventale.Exact(1), eventale.EventData{Type: "ItemAdded", Data: []byte(`{}`)}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if id, ev := next(sc); id != "2" || ev["type"] != "ItemAdded" {
+		t.Fatalf("expected live event 2, got %s %v", id, ev)
+	}
+
+	// Reconnecting with the last event ID resumes after it
+	sc, stop = subscribe("/streams/$all/subscribe", "1")
+	defer stop()
+	if id, ev := next(sc); id != "2" || ev["stream"] != "order-1" {
+		t.Fatalf("expected event at position 2, got %s %v", id, ev)
+	}
+}
+
+func TestGatewaySubscribeReconnect(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	srv, _ := startServer(t)
+	hs := httptest.NewServer(srv.Gateway())
+	defer hs.Close()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	srv.AuthorizeKey(&key.PublicKey)
+	appendEvent := func(eventType string) {
+		t.Helper()
+		body := map[string]any{"events": []map[string]any{{"type": eventType}}}
+		if status := gatewayDo(t, "POST", hs.URL+"/streams/order-1", signedBy(key, 0), body, nil); status != http.StatusCreated {
+			t.Fatalf("append %s: status %d", eventType, status)
+		}
+	}
+	appendEvent("OrderPlaced")
+
+	// Like EventSource, reconnect with the URL and token the stream was
+	// opened with
+	tok := signedBy(key, 0)(t, auth.TokenRequest{Method: "GET", Path: "/streams/order-1/subscribe"})
+	url := hs.URL + "/streams/order-1/subscribe?from=0&token=" + tok
+	sc, stop := gatewaySubscribe(t, ctx, url, "")
+	if id, ev := nextGatewayEvent(t, sc); id != "1" || ev["type"] != "OrderPlaced" {
+		t.Fatalf("expected event 1, got %s %v", id, ev)
+	}
+	stop()
+	appendEvent("ItemAdded")
+	sc, stop = gatewaySubscribe(t, ctx, url, "1")
+	defer stop()
+	if id, ev := nextGatewayEvent(t, sc); id != "2" || ev["type"] != "ItemAdded" {
+		t.Fatalf("expected resume at event 2, got %s %v", id, ev)
+	}
+
+	// The token still only authorizes the subscription it is signed for
+	req, err := http.NewRequestWithContext(ctx, "GET", hs.URL+"/streams/order-2/subscribe?token="+tok, nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected unauthorized subscription to another stream, got %d", resp.StatusCode)
+	}
+}
+
+// gatewaySubscribe opens the server-sent event stream at url, resuming after
+// lastID unless empty. Events are read with nextGatewayEvent.
+func gatewaySubscribe(t *testing.T, ctx context.Context, url, lastID string) (*bufio.Scanner, func()) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("expected event stream, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	return bufio.NewScanner(resp.Body), func() { resp.Body.Close() }
+}
+
+// nextGatewayEvent returns the ID and data of the next event of sc.
+func nextGatewayEvent(t *testing.T, sc *bufio.Scanner) (id string, data map[string]any) {
+	t.Helper()
+	for sc.Scan() {
+		line := sc.Text()
+		if v, ok := strings.CutPrefix(line, "id: "); ok {
+			id = v
+		}
+		if v, ok := strings.CutPrefix(line, "data: "); ok {
+			if err := json.Unmarshal([]byte(v), &data); err != nil {
+				t.Fatalf("unmarshal %s: %v", v, err)
+			}
+		}
+		if line == "" && data != nil {
+			return id, data
+		}
+	}
+	t.Fatalf("event stream ended: %v", sc.Err())
+	return "", nil
+}
+
+func TestGatewayOpenAPI(t *testing.T) {
+	srv, _ := startServer(t)
+	hs := httptest.NewServer(srv.Gateway())
+	defer hs.Close()
+	resp, err := http.Get(hs.URL + "/openapi.yaml")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil || resp.StatusCode != http.StatusOK || !strings.HasPrefix(string(b), "openapi: 3") {
+		t.Fatalf("expected the OpenAPI document, got %d (err %v)", resp.StatusCode, err)
+	}
+}
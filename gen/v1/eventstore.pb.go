@@ -0,0 +1,372 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.33.0
+// 	protoc        (unknown)
+// source: v1/eventstore.proto
+
+package eventalepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// WireSubscriptionMessage is a message streamed to gRPC subscriptions.
+type WireSubscriptionMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Message:
+	//	*WireSubscriptionMessage_Events
+	//	*WireSubscriptionMessage_Deleted
+	Message isWireSubscriptionMessage_Message `protobuf_oneof:"message"`
+}
+
+func (x *WireSubscriptionMessage) Reset() {
+	*x = WireSubscriptionMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_eventstore_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireSubscriptionMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireSubscriptionMessage) ProtoMessage() {}
+
+func (x *WireSubscriptionMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_eventstore_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireSubscriptionMessage.ProtoReflect.Descriptor instead.
+func (*WireSubscriptionMessage) Descriptor() ([]byte, []int) {
+	return file_v1_eventstore_proto_rawDescGZIP(), []int{0}
+}
+
+func (m *WireSubscriptionMessage) GetMessage() isWireSubscriptionMessage_Message {
+	if m != nil {
+		return m.Message
+	}
+	return nil
+}
+
+func (x *WireSubscriptionMessage) GetEvents() *WireSubscriptionEvents {
+	if x, ok := x.GetMessage().(*WireSubscriptionMessage_Events); ok {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *WireSubscriptionMessage) GetDeleted() *WireStreamDeleted {
+	if x, ok := x.GetMessage().(*WireSubscriptionMessage_Deleted); ok {
+		return x.Deleted
+	}
+	return nil
+}
+
+type isWireSubscriptionMessage_Message interface {
+	isWireSubscriptionMessage_Message()
+}
+
+type WireSubscriptionMessage_Events struct {
+	Events *WireSubscriptionEvents `protobuf:"bytes,1,opt,name=events,proto3,oneof"`
+}
+
+type WireSubscriptionMessage_Deleted struct {
+	Deleted *WireStreamDeleted `protobuf:"bytes,2,opt,name=deleted,proto3,oneof"`
+}
+
+func (*WireSubscriptionMessage_Events) isWireSubscriptionMessage_Message() {}
+
+func (*WireSubscriptionMessage_Deleted) isWireSubscriptionMessage_Message() {}
+
+type WireGrantCreditRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// ID of the subscription, from the subscription-id response header.
+	SubscriptionId string `protobuf:"bytes,1,opt,name=subscriptionId,proto3" json:"subscriptionId,omitempty"`
+	Credit         uint32 `protobuf:"varint,2,opt,name=credit,proto3" json:"credit,omitempty"`
+}
+
+func (x *WireGrantCreditRequest) Reset() {
+	*x = WireGrantCreditRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_eventstore_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireGrantCreditRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireGrantCreditRequest) ProtoMessage() {}
+
+func (x *WireGrantCreditRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_eventstore_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireGrantCreditRequest.ProtoReflect.Descriptor instead.
+func (*WireGrantCreditRequest) Descriptor() ([]byte, []int) {
+	return file_v1_eventstore_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *WireGrantCreditRequest) GetSubscriptionId() string {
+	if x != nil {
+		return x.SubscriptionId
+	}
+	return ""
+}
+
+func (x *WireGrantCreditRequest) GetCredit() uint32 {
+	if x != nil {
+		return x.Credit
+	}
+	return 0
+}
+
+type WireCreditGranted struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *WireCreditGranted) Reset() {
+	*x = WireCreditGranted{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_eventstore_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireCreditGranted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireCreditGranted) ProtoMessage() {}
+
+func (x *WireCreditGranted) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_eventstore_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireCreditGranted.ProtoReflect.Descriptor instead.
+func (*WireCreditGranted) Descriptor() ([]byte, []int) {
+	return file_v1_eventstore_proto_rawDescGZIP(), []int{2}
+}
+
+var File_v1_eventstore_proto protoreflect.FileDescriptor
+
+var file_v1_eventstore_proto_rawDesc = []byte{
+	0x0a, 0x13, 0x76, 0x31, 0x2f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x08, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x1a,
+	0x0c, 0x76, 0x31, 0x2f, 0x74, 0x63, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x99, 0x01,
+	0x0a, 0x17, 0x57, 0x69, 0x72, 0x65, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69,
+	0x6f, 0x6e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x3a, 0x0a, 0x06, 0x65, 0x76, 0x65,
+	0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x65, 0x76, 0x65, 0x6e,
+	0x74, 0x61, 0x6c, 0x65, 0x2e, 0x57, 0x69, 0x72, 0x65, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x48, 0x00, 0x52, 0x06, 0x65,
+	0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x37, 0x0a, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c,
+	0x65, 0x2e, 0x57, 0x69, 0x72, 0x65, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x44, 0x65, 0x6c, 0x65,
+	0x74, 0x65, 0x64, 0x48, 0x00, 0x52, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x42, 0x09,
+	0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x58, 0x0a, 0x16, 0x57, 0x69, 0x72,
+	0x65, 0x47, 0x72, 0x61, 0x6e, 0x74, 0x43, 0x72, 0x65, 0x64, 0x69, 0x74, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x26, 0x0a, 0x0e, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x73, 0x75, 0x62,
+	0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x63,
+	0x72, 0x65, 0x64, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x63, 0x72, 0x65,
+	0x64, 0x69, 0x74, 0x22, 0x13, 0x0a, 0x11, 0x57, 0x69, 0x72, 0x65, 0x43, 0x72, 0x65, 0x64, 0x69,
+	0x74, 0x47, 0x72, 0x61, 0x6e, 0x74, 0x65, 0x64, 0x32, 0xd4, 0x03, 0x0a, 0x0a, 0x45, 0x76, 0x65,
+	0x6e, 0x74, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x12, 0x41, 0x0a, 0x06, 0x41, 0x70, 0x70, 0x65, 0x6e,
+	0x64, 0x12, 0x1b, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2e, 0x57, 0x69, 0x72,
+	0x65, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a,
+	0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2e, 0x57, 0x69, 0x72, 0x65, 0x41, 0x70,
+	0x70, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x4d, 0x0a, 0x0a, 0x52, 0x65,
+	0x61, 0x64, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x1f, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74,
+	0x61, 0x6c, 0x65, 0x2e, 0x57, 0x69, 0x72, 0x65, 0x52, 0x65, 0x61, 0x64, 0x53, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x65, 0x76, 0x65, 0x6e,
+	0x74, 0x61, 0x6c, 0x65, 0x2e, 0x57, 0x69, 0x72, 0x65, 0x52, 0x65, 0x61, 0x64, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x44, 0x0a, 0x07, 0x52, 0x65, 0x61,
+	0x64, 0x41, 0x6c, 0x6c, 0x12, 0x1c, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2e,
+	0x57, 0x69, 0x72, 0x65, 0x52, 0x65, 0x61, 0x64, 0x41, 0x6c, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2e, 0x57, 0x69,
+	0x72, 0x65, 0x52, 0x65, 0x61, 0x64, 0x41, 0x6c, 0x6c, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12,
+	0x50, 0x0a, 0x09, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x12, 0x1e, 0x2e, 0x65,
+	0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2e, 0x57, 0x69, 0x72, 0x65, 0x53, 0x75, 0x62, 0x73,
+	0x63, 0x72, 0x69, 0x62, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x65,
+	0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2e, 0x57, 0x69, 0x72, 0x65, 0x53, 0x75, 0x62, 0x73,
+	0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x30,
+	0x01, 0x12, 0x4c, 0x0a, 0x0b, 0x47, 0x72, 0x61, 0x6e, 0x74, 0x43, 0x72, 0x65, 0x64, 0x69, 0x74,
+	0x12, 0x20, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2e, 0x57, 0x69, 0x72, 0x65,
+	0x47, 0x72, 0x61, 0x6e, 0x74, 0x43, 0x72, 0x65, 0x64, 0x69, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2e, 0x57, 0x69,
+	0x72, 0x65, 0x43, 0x72, 0x65, 0x64, 0x69, 0x74, 0x47, 0x72, 0x61, 0x6e, 0x74, 0x65, 0x64, 0x12,
+	0x4e, 0x0a, 0x0c, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12,
+	0x21, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2e, 0x57, 0x69, 0x72, 0x65, 0x44,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2e, 0x57, 0x69,
+	0x72, 0x65, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x42,
+	0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6e, 0x6f,
+	0x68, 0x6e, 0x73, 0x2f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2f, 0x67, 0x65, 0x6e,
+	0x2f, 0x76, 0x31, 0x2f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x70, 0x62, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_v1_eventstore_proto_rawDescOnce sync.Once
+	file_v1_eventstore_proto_rawDescData = file_v1_eventstore_proto_rawDesc
+)
+
+func file_v1_eventstore_proto_rawDescGZIP() []byte {
+	file_v1_eventstore_proto_rawDescOnce.Do(func() {
+		file_v1_eventstore_proto_rawDescData = protoimpl.X.CompressGZIP(file_v1_eventstore_proto_rawDescData)
+	})
+	return file_v1_eventstore_proto_rawDescData
+}
+
+var file_v1_eventstore_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_v1_eventstore_proto_goTypes = []interface{}{
+	(*WireSubscriptionMessage)(nil), // 0: eventale.WireSubscriptionMessage
+	(*WireGrantCreditRequest)(nil),  // 1: eventale.WireGrantCreditRequest
+	(*WireCreditGranted)(nil),       // 2: eventale.WireCreditGranted
+	(*WireSubscriptionEvents)(nil),  // 3: eventale.WireSubscriptionEvents
+	(*WireStreamDeleted)(nil),       // 4: eventale.WireStreamDeleted
+	(*WireAppendRequest)(nil),       // 5: eventale.WireAppendRequest
+	(*WireReadStreamRequest)(nil),   // 6: eventale.WireReadStreamRequest
+	(*WireReadAllRequest)(nil),      // 7: eventale.WireReadAllRequest
+	(*WireSubscribeRequest)(nil),    // 8: eventale.WireSubscribeRequest
+	(*WireDeleteStreamRequest)(nil), // 9: eventale.WireDeleteStreamRequest
+	(*WireAppendResult)(nil),        // 10: eventale.WireAppendResult
+	(*WireReadStreamResult)(nil),    // 11: eventale.WireReadStreamResult
+	(*WireReadAllResult)(nil),       // 12: eventale.WireReadAllResult
+}
+var file_v1_eventstore_proto_depIdxs = []int32{
+	3,  // 0: eventale.WireSubscriptionMessage.events:type_name -> eventale.WireSubscriptionEvents
+	4,  // 1: eventale.WireSubscriptionMessage.deleted:type_name -> eventale.WireStreamDeleted
+	5,  // 2: eventale.EventStore.Append:input_type -> eventale.WireAppendRequest
+	6,  // 3: eventale.EventStore.ReadStream:input_type -> eventale.WireReadStreamRequest
+	7,  // 4: eventale.EventStore.ReadAll:input_type -> eventale.WireReadAllRequest
+	8,  // 5: eventale.EventStore.Subscribe:input_type -> eventale.WireSubscribeRequest
+	1,  // 6: eventale.EventStore.GrantCredit:input_type -> eventale.WireGrantCreditRequest
+	9,  // 7: eventale.EventStore.DeleteStream:input_type -> eventale.WireDeleteStreamRequest
+	10, // 8: eventale.EventStore.Append:output_type -> eventale.WireAppendResult
+	11, // 9: eventale.EventStore.ReadStream:output_type -> eventale.WireReadStreamResult
+	12, // 10: eventale.EventStore.ReadAll:output_type -> eventale.WireReadAllResult
+	0,  // 11: eventale.EventStore.Subscribe:output_type -> eventale.WireSubscriptionMessage
+	2,  // 12: eventale.EventStore.GrantCredit:output_type -> eventale.WireCreditGranted
+	4,  // 13: eventale.EventStore.DeleteStream:output_type -> eventale.WireStreamDeleted
+	8,  // [8:14] is the sub-list for method output_type
+	2,  // [2:8] is the sub-list for method input_type
+	2,  // [2:2] is the sub-list for extension type_name
+	2,  // [2:2] is the sub-list for extension extendee
+	0,  // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_v1_eventstore_proto_init() }
+func file_v1_eventstore_proto_init() {
+	if File_v1_eventstore_proto != nil {
+		return
+	}
+	file_v1_tcp_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_v1_eventstore_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireSubscriptionMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_eventstore_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireGrantCreditRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_eventstore_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireCreditGranted); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_v1_eventstore_proto_msgTypes[0].OneofWrappers = []interface{}{
+		(*WireSubscriptionMessage_Events)(nil),
+		(*WireSubscriptionMessage_Deleted)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_v1_eventstore_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_v1_eventstore_proto_goTypes,
+		DependencyIndexes: file_v1_eventstore_proto_depIdxs,
+		MessageInfos:      file_v1_eventstore_proto_msgTypes,
+	}.Build()
+	File_v1_eventstore_proto = out.File
+	file_v1_eventstore_proto_rawDesc = nil
+	file_v1_eventstore_proto_goTypes = nil
+	file_v1_eventstore_proto_depIdxs = nil
+}
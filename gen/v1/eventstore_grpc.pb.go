@@ -0,0 +1,338 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: v1/eventstore.proto
+
+package eventalepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	EventStore_Append_FullMethodName       = "/eventale.EventStore/Append"
+	EventStore_ReadStream_FullMethodName   = "/eventale.EventStore/ReadStream"
+	EventStore_ReadAll_FullMethodName      = "/eventale.EventStore/ReadAll"
+	EventStore_Subscribe_FullMethodName    = "/eventale.EventStore/Subscribe"
+	EventStore_GrantCredit_FullMethodName  = "/eventale.EventStore/GrantCredit"
+	EventStore_DeleteStream_FullMethodName = "/eventale.EventStore/DeleteStream"
+)
+
+// EventStoreClient is the client API for EventStore service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type EventStoreClient interface {
+	Append(ctx context.Context, in *WireAppendRequest, opts ...grpc.CallOption) (*WireAppendResult, error)
+	ReadStream(ctx context.Context, in *WireReadStreamRequest, opts ...grpc.CallOption) (*WireReadStreamResult, error)
+	ReadAll(ctx context.Context, in *WireReadAllRequest, opts ...grpc.CallOption) (*WireReadAllResult, error)
+	// Subscribe streams the events of a stream, or of all streams, until the
+	// call is canceled. The server sends the response headers once the
+	// subscription is live, so clients wait for them before relying on
+	// events appended afterwards being pushed. The headers carry the ID of
+	// the subscription in the subscription-id metadata, with which
+	// subscriptions started with credit are granted more.
+	Subscribe(ctx context.Context, in *WireSubscribeRequest, opts ...grpc.CallOption) (EventStore_SubscribeClient, error)
+	// GrantCredit grants a subscription of the same principal credit for
+	// more events.
+	GrantCredit(ctx context.Context, in *WireGrantCreditRequest, opts ...grpc.CallOption) (*WireCreditGranted, error)
+	DeleteStream(ctx context.Context, in *WireDeleteStreamRequest, opts ...grpc.CallOption) (*WireStreamDeleted, error)
+}
+
+type eventStoreClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEventStoreClient(cc grpc.ClientConnInterface) EventStoreClient {
+	return &eventStoreClient{cc}
+}
+
+func (c *eventStoreClient) Append(ctx context.Context, in *WireAppendRequest, opts ...grpc.CallOption) (*WireAppendResult, error) {
+	out := new(WireAppendResult)
+	err := c.cc.Invoke(ctx, EventStore_Append_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventStoreClient) ReadStream(ctx context.Context, in *WireReadStreamRequest, opts ...grpc.CallOption) (*WireReadStreamResult, error) {
+	out := new(WireReadStreamResult)
+	err := c.cc.Invoke(ctx, EventStore_ReadStream_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventStoreClient) ReadAll(ctx context.Context, in *WireReadAllRequest, opts ...grpc.CallOption) (*WireReadAllResult, error) {
+	out := new(WireReadAllResult)
+	err := c.cc.Invoke(ctx, EventStore_ReadAll_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventStoreClient) Subscribe(ctx context.Context, in *WireSubscribeRequest, opts ...grpc.CallOption) (EventStore_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &EventStore_ServiceDesc.Streams[0], EventStore_Subscribe_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &eventStoreSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type EventStore_SubscribeClient interface {
+	Recv() (*WireSubscriptionMessage, error)
+	grpc.ClientStream
+}
+
+type eventStoreSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *eventStoreSubscribeClient) Recv() (*WireSubscriptionMessage, error) {
+	m := new(WireSubscriptionMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *eventStoreClient) GrantCredit(ctx context.Context, in *WireGrantCreditRequest, opts ...grpc.CallOption) (*WireCreditGranted, error) {
+	out := new(WireCreditGranted)
+	err := c.cc.Invoke(ctx, EventStore_GrantCredit_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventStoreClient) DeleteStream(ctx context.Context, in *WireDeleteStreamRequest, opts ...grpc.CallOption) (*WireStreamDeleted, error) {
+	out := new(WireStreamDeleted)
+	err := c.cc.Invoke(ctx, EventStore_DeleteStream_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EventStoreServer is the server API for EventStore service.
+// All implementations must embed UnimplementedEventStoreServer
+// for forward compatibility
+type EventStoreServer interface {
+	Append(context.Context, *WireAppendRequest) (*WireAppendResult, error)
+	ReadStream(context.Context, *WireReadStreamRequest) (*WireReadStreamResult, error)
+	ReadAll(context.Context, *WireReadAllRequest) (*WireReadAllResult, error)
+	// Subscribe streams the events of a stream, or of all streams, until the
+	// call is canceled. The server sends the response headers once the
+	// subscription is live, so clients wait for them before relying on
+	// events appended afterwards being pushed. The headers carry the ID of
+	// the subscription in the subscription-id metadata, with which
+	// subscriptions started with credit are granted more.
+	Subscribe(*WireSubscribeRequest, EventStore_SubscribeServer) error
+	// GrantCredit grants a subscription of the same principal credit for
+	// more events.
+	GrantCredit(context.Context, *WireGrantCreditRequest) (*WireCreditGranted, error)
+	DeleteStream(context.Context, *WireDeleteStreamRequest) (*WireStreamDeleted, error)
+	mustEmbedUnimplementedEventStoreServer()
+}
+
+// UnimplementedEventStoreServer must be embedded to have forward compatible implementations.
+type UnimplementedEventStoreServer struct {
+}
+
+func (UnimplementedEventStoreServer) Append(context.Context, *WireAppendRequest) (*WireAppendResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Append not implemented")
+}
+func (UnimplementedEventStoreServer) ReadStream(context.Context, *WireReadStreamRequest) (*WireReadStreamResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadStream not implemented")
+}
+func (UnimplementedEventStoreServer) ReadAll(context.Context, *WireReadAllRequest) (*WireReadAllResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadAll not implemented")
+}
+func (UnimplementedEventStoreServer) Subscribe(*WireSubscribeRequest, EventStore_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedEventStoreServer) GrantCredit(context.Context, *WireGrantCreditRequest) (*WireCreditGranted, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GrantCredit not implemented")
+}
+func (UnimplementedEventStoreServer) DeleteStream(context.Context, *WireDeleteStreamRequest) (*WireStreamDeleted, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteStream not implemented")
+}
+func (UnimplementedEventStoreServer) mustEmbedUnimplementedEventStoreServer() {}
+
+// UnsafeEventStoreServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EventStoreServer will
+// result in compilation errors.
+type UnsafeEventStoreServer interface {
+	mustEmbedUnimplementedEventStoreServer()
+}
+
+func RegisterEventStoreServer(s grpc.ServiceRegistrar, srv EventStoreServer) {
+	s.RegisterService(&EventStore_ServiceDesc, srv)
+}
+
+func _EventStore_Append_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WireAppendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventStoreServer).Append(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventStore_Append_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventStoreServer).Append(ctx, req.(*WireAppendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventStore_ReadStream_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WireReadStreamRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventStoreServer).ReadStream(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventStore_ReadStream_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventStoreServer).ReadStream(ctx, req.(*WireReadStreamRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventStore_ReadAll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WireReadAllRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventStoreServer).ReadAll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventStore_ReadAll_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventStoreServer).ReadAll(ctx, req.(*WireReadAllRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventStore_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WireSubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EventStoreServer).Subscribe(m, &eventStoreSubscribeServer{stream})
+}
+
+type EventStore_SubscribeServer interface {
+	Send(*WireSubscriptionMessage) error
+	grpc.ServerStream
+}
+
+type eventStoreSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *eventStoreSubscribeServer) Send(m *WireSubscriptionMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _EventStore_GrantCredit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WireGrantCreditRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventStoreServer).GrantCredit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventStore_GrantCredit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventStoreServer).GrantCredit(ctx, req.(*WireGrantCreditRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventStore_DeleteStream_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WireDeleteStreamRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventStoreServer).DeleteStream(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventStore_DeleteStream_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventStoreServer).DeleteStream(ctx, req.(*WireDeleteStreamRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// EventStore_ServiceDesc is the grpc.ServiceDesc for EventStore service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var EventStore_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "eventale.EventStore",
+	HandlerType: (*EventStoreServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Append",
+			Handler:    _EventStore_Append_Handler,
+		},
+		{
+			MethodName: "ReadStream",
+			Handler:    _EventStore_ReadStream_Handler,
+		},
+		{
+			MethodName: "ReadAll",
+			Handler:    _EventStore_ReadAll_Handler,
+		},
+		{
+			MethodName: "GrantCredit",
+			Handler:    _EventStore_GrantCredit_Handler,
+		},
+		{
+			MethodName: "DeleteStream",
+			Handler:    _EventStore_DeleteStream_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _EventStore_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "v1/eventstore.proto",
+}
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.26.0
+// 	protoc-gen-go v1.33.0
 // 	protoc        (unknown)
 // source: v1/tcp.proto
 
@@ -20,6 +20,184 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Algorithms frame payloads can be compressed with. The values match the
+// compression flags of the frame header.
+type WireCompression int32
+
+const (
+	WireCompression_WIRE_COMPRESSION_NONE   WireCompression = 0
+	WireCompression_WIRE_COMPRESSION_GZIP   WireCompression = 1
+	WireCompression_WIRE_COMPRESSION_ZSTD   WireCompression = 2
+	WireCompression_WIRE_COMPRESSION_SNAPPY WireCompression = 3
+)
+
+// Enum value maps for WireCompression.
+var (
+	WireCompression_name = map[int32]string{
+		0: "WIRE_COMPRESSION_NONE",
+		1: "WIRE_COMPRESSION_GZIP",
+		2: "WIRE_COMPRESSION_ZSTD",
+		3: "WIRE_COMPRESSION_SNAPPY",
+	}
+	WireCompression_value = map[string]int32{
+		"WIRE_COMPRESSION_NONE":   0,
+		"WIRE_COMPRESSION_GZIP":   1,
+		"WIRE_COMPRESSION_ZSTD":   2,
+		"WIRE_COMPRESSION_SNAPPY": 3,
+	}
+)
+
+func (x WireCompression) Enum() *WireCompression {
+	p := new(WireCompression)
+	*p = x
+	return p
+}
+
+func (x WireCompression) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WireCompression) Descriptor() protoreflect.EnumDescriptor {
+	return file_v1_tcp_proto_enumTypes[0].Descriptor()
+}
+
+func (WireCompression) Type() protoreflect.EnumType {
+	return &file_v1_tcp_proto_enumTypes[0]
+}
+
+func (x WireCompression) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WireCompression.Descriptor instead.
+func (WireCompression) EnumDescriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{0}
+}
+
+type WireErrorCode int32
+
+const (
+	WireErrorCode_WIRE_ERROR_CODE_UNKNOWN                WireErrorCode = 0
+	WireErrorCode_WIRE_ERROR_CODE_BAD_REQUEST            WireErrorCode = 1
+	WireErrorCode_WIRE_ERROR_CODE_UNAUTHORIZED           WireErrorCode = 2
+	WireErrorCode_WIRE_ERROR_CODE_WRONG_EXPECTED_VERSION WireErrorCode = 3
+	// The lease of the consumer group is held by another member.
+	WireErrorCode_WIRE_ERROR_CODE_LEASE_LOST            WireErrorCode = 4
+	WireErrorCode_WIRE_ERROR_CODE_STREAM_DELETED        WireErrorCode = 5
+	WireErrorCode_WIRE_ERROR_CODE_TRANSACTION_NOT_FOUND WireErrorCode = 6
+	// The frame exceeded the maximum frame size. The connection is closed.
+	WireErrorCode_WIRE_ERROR_CODE_FRAME_TOO_LARGE WireErrorCode = 7
+	// The subscriber fell too far behind, and the subscription was dropped.
+	WireErrorCode_WIRE_ERROR_CODE_SUBSCRIPTION_TOO_SLOW WireErrorCode = 8
+)
+
+// Enum value maps for WireErrorCode.
+var (
+	WireErrorCode_name = map[int32]string{
+		0: "WIRE_ERROR_CODE_UNKNOWN",
+		1: "WIRE_ERROR_CODE_BAD_REQUEST",
+		2: "WIRE_ERROR_CODE_UNAUTHORIZED",
+		3: "WIRE_ERROR_CODE_WRONG_EXPECTED_VERSION",
+		4: "WIRE_ERROR_CODE_LEASE_LOST",
+		5: "WIRE_ERROR_CODE_STREAM_DELETED",
+		6: "WIRE_ERROR_CODE_TRANSACTION_NOT_FOUND",
+		7: "WIRE_ERROR_CODE_FRAME_TOO_LARGE",
+		8: "WIRE_ERROR_CODE_SUBSCRIPTION_TOO_SLOW",
+	}
+	WireErrorCode_value = map[string]int32{
+		"WIRE_ERROR_CODE_UNKNOWN":                0,
+		"WIRE_ERROR_CODE_BAD_REQUEST":            1,
+		"WIRE_ERROR_CODE_UNAUTHORIZED":           2,
+		"WIRE_ERROR_CODE_WRONG_EXPECTED_VERSION": 3,
+		"WIRE_ERROR_CODE_LEASE_LOST":             4,
+		"WIRE_ERROR_CODE_STREAM_DELETED":         5,
+		"WIRE_ERROR_CODE_TRANSACTION_NOT_FOUND":  6,
+		"WIRE_ERROR_CODE_FRAME_TOO_LARGE":        7,
+		"WIRE_ERROR_CODE_SUBSCRIPTION_TOO_SLOW":  8,
+	}
+)
+
+func (x WireErrorCode) Enum() *WireErrorCode {
+	p := new(WireErrorCode)
+	*p = x
+	return p
+}
+
+func (x WireErrorCode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WireErrorCode) Descriptor() protoreflect.EnumDescriptor {
+	return file_v1_tcp_proto_enumTypes[1].Descriptor()
+}
+
+func (WireErrorCode) Type() protoreflect.EnumType {
+	return &file_v1_tcp_proto_enumTypes[1]
+}
+
+func (x WireErrorCode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WireErrorCode.Descriptor instead.
+func (WireErrorCode) EnumDescriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{1}
+}
+
+// Expected versions with special meaning. Expected versions above 0 are exact
+// versions the stream must be at.
+type WireExpectedVersion int32
+
+const (
+	// The stream must not exist.
+	WireExpectedVersion_WIRE_EXPECTED_VERSION_NO_STREAM WireExpectedVersion = 0
+	// Any version, including no stream.
+	WireExpectedVersion_WIRE_EXPECTED_VERSION_ANY WireExpectedVersion = -1
+	// The stream must exist, at any version.
+	WireExpectedVersion_WIRE_EXPECTED_VERSION_STREAM_EXISTS WireExpectedVersion = -2
+)
+
+// Enum value maps for WireExpectedVersion.
+var (
+	WireExpectedVersion_name = map[int32]string{
+		0:  "WIRE_EXPECTED_VERSION_NO_STREAM",
+		-1: "WIRE_EXPECTED_VERSION_ANY",
+		-2: "WIRE_EXPECTED_VERSION_STREAM_EXISTS",
+	}
+	WireExpectedVersion_value = map[string]int32{
+		"WIRE_EXPECTED_VERSION_NO_STREAM":     0,
+		"WIRE_EXPECTED_VERSION_ANY":           -1,
+		"WIRE_EXPECTED_VERSION_STREAM_EXISTS": -2,
+	}
+)
+
+func (x WireExpectedVersion) Enum() *WireExpectedVersion {
+	p := new(WireExpectedVersion)
+	*p = x
+	return p
+}
+
+func (x WireExpectedVersion) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WireExpectedVersion) Descriptor() protoreflect.EnumDescriptor {
+	return file_v1_tcp_proto_enumTypes[2].Descriptor()
+}
+
+func (WireExpectedVersion) Type() protoreflect.EnumType {
+	return &file_v1_tcp_proto_enumTypes[2]
+}
+
+func (x WireExpectedVersion) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WireExpectedVersion.Descriptor instead.
+func (WireExpectedVersion) EnumDescriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{2}
+}
+
 type SemanticVersion struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -90,6 +268,11 @@ type WireClientHello struct {
 
 	ClientVersion *SemanticVersion `protobuf:"bytes,1,opt,name=clientVersion,proto3" json:"clientVersion,omitempty"`
 	Signature     []byte           `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+	// Compression algorithms supported by the client, in order of preference.
+	Compressions []WireCompression `protobuf:"varint,3,rep,packed,name=compressions,proto3,enum=eventale.WireCompression" json:"compressions,omitempty"`
+	// Maximum payload size of frames the client accepts. Larger payloads are
+	// split into chunks. 0 for the default of 1 MiB.
+	MaxFrameSize uint32 `protobuf:"varint,4,opt,name=maxFrameSize,proto3" json:"maxFrameSize,omitempty"`
 }
 
 func (x *WireClientHello) Reset() {
@@ -138,6 +321,20 @@ func (x *WireClientHello) GetSignature() []byte {
 	return nil
 }
 
+func (x *WireClientHello) GetCompressions() []WireCompression {
+	if x != nil {
+		return x.Compressions
+	}
+	return nil
+}
+
+func (x *WireClientHello) GetMaxFrameSize() uint32 {
+	if x != nil {
+		return x.MaxFrameSize
+	}
+	return 0
+}
+
 type WireServerHello struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -145,6 +342,12 @@ type WireServerHello struct {
 
 	ServerVersion *SemanticVersion `protobuf:"bytes,1,opt,name=serverVersion,proto3" json:"serverVersion,omitempty"`
 	EncryptionKey []byte           `protobuf:"bytes,2,opt,name=encryptionKey,proto3" json:"encryptionKey,omitempty"`
+	// Compression chosen from those advertised by the client, used by both
+	// peers for frames sent after the hello.
+	Compression WireCompression `protobuf:"varint,3,opt,name=compression,proto3,enum=eventale.WireCompression" json:"compression,omitempty"`
+	// Maximum payload size of frames the server accepts. Larger payloads are
+	// split into chunks. 0 for the default of 1 MiB.
+	MaxFrameSize uint32 `protobuf:"varint,4,opt,name=maxFrameSize,proto3" json:"maxFrameSize,omitempty"`
 }
 
 func (x *WireServerHello) Reset() {
@@ -193,96 +396,2868 @@ func (x *WireServerHello) GetEncryptionKey() []byte {
 	return nil
 }
 
-var File_v1_tcp_proto protoreflect.FileDescriptor
+func (x *WireServerHello) GetCompression() WireCompression {
+	if x != nil {
+		return x.Compression
+	}
+	return WireCompression_WIRE_COMPRESSION_NONE
+}
 
-var file_v1_tcp_proto_rawDesc = []byte{
-	0x0a, 0x0c, 0x76, 0x31, 0x2f, 0x74, 0x63, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x08,
-	0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x22, 0x53, 0x0a, 0x0f, 0x53, 0x65, 0x6d, 0x61,
-	0x6e, 0x74, 0x69, 0x63, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x6d,
-	0x61, 0x6a, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6d, 0x61, 0x6a, 0x6f,
-	0x72, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x69, 0x6e, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d,
-	0x52, 0x05, 0x6d, 0x69, 0x6e, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x61, 0x74, 0x63, 0x68,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x70, 0x61, 0x74, 0x63, 0x68, 0x22, 0x70, 0x0a,
-	0x0f, 0x57, 0x69, 0x72, 0x65, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x48, 0x65, 0x6c, 0x6c, 0x6f,
-	0x12, 0x3f, 0x0a, 0x0d, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
-	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61,
-	0x6c, 0x65, 0x2e, 0x53, 0x65, 0x6d, 0x61, 0x6e, 0x74, 0x69, 0x63, 0x56, 0x65, 0x72, 0x73, 0x69,
-	0x6f, 0x6e, 0x52, 0x0d, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
-	0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22,
-	0x78, 0x0a, 0x0f, 0x57, 0x69, 0x72, 0x65, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x48, 0x65, 0x6c,
-	0x6c, 0x6f, 0x12, 0x3f, 0x0a, 0x0d, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x56, 0x65, 0x72, 0x73,
-	0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x65, 0x76, 0x65, 0x6e,
-	0x74, 0x61, 0x6c, 0x65, 0x2e, 0x53, 0x65, 0x6d, 0x61, 0x6e, 0x74, 0x69, 0x63, 0x56, 0x65, 0x72,
-	0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0d, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x56, 0x65, 0x72, 0x73,
-	0x69, 0x6f, 0x6e, 0x12, 0x24, 0x0a, 0x0d, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f,
-	0x6e, 0x4b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0d, 0x65, 0x6e, 0x63, 0x72,
-	0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x4b, 0x65, 0x79, 0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74,
-	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6e, 0x6f, 0x68, 0x6e, 0x73, 0x2f, 0x65, 0x76,
-	0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x76, 0x31, 0x2f, 0x65, 0x76,
-	0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+func (x *WireServerHello) GetMaxFrameSize() uint32 {
+	if x != nil {
+		return x.MaxFrameSize
+	}
+	return 0
 }
 
-var (
-	file_v1_tcp_proto_rawDescOnce sync.Once
-	file_v1_tcp_proto_rawDescData = file_v1_tcp_proto_rawDesc
-)
+type WireError struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 
-func file_v1_tcp_proto_rawDescGZIP() []byte {
-	file_v1_tcp_proto_rawDescOnce.Do(func() {
-		file_v1_tcp_proto_rawDescData = protoimpl.X.CompressGZIP(file_v1_tcp_proto_rawDescData)
-	})
-	return file_v1_tcp_proto_rawDescData
+	Code    WireErrorCode `protobuf:"varint,1,opt,name=code,proto3,enum=eventale.WireErrorCode" json:"code,omitempty"`
+	Message string        `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// Current version of the stream for WIRE_ERROR_CODE_WRONG_EXPECTED_VERSION,
+	// 0 if the stream does not exist.
+	ActualVersion uint64 `protobuf:"varint,3,opt,name=actualVersion,proto3" json:"actualVersion,omitempty"`
 }
 
-var file_v1_tcp_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
-var file_v1_tcp_proto_goTypes = []interface{}{
-	(*SemanticVersion)(nil), // 0: eventale.SemanticVersion
-	(*WireClientHello)(nil), // 1: eventale.WireClientHello
-	(*WireServerHello)(nil), // 2: eventale.WireServerHello
+func (x *WireError) Reset() {
+	*x = WireError{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
 }
-var file_v1_tcp_proto_depIdxs = []int32{
-	0, // 0: eventale.WireClientHello.clientVersion:type_name -> eventale.SemanticVersion
-	0, // 1: eventale.WireServerHello.serverVersion:type_name -> eventale.SemanticVersion
-	2, // [2:2] is the sub-list for method output_type
-	2, // [2:2] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+
+func (x *WireError) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func init() { file_v1_tcp_proto_init() }
-func file_v1_tcp_proto_init() {
-	if File_v1_tcp_proto != nil {
-		return
+func (*WireError) ProtoMessage() {}
+
+func (x *WireError) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_v1_tcp_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SemanticVersion); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireError.ProtoReflect.Descriptor instead.
+func (*WireError) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *WireError) GetCode() WireErrorCode {
+	if x != nil {
+		return x.Code
+	}
+	return WireErrorCode_WIRE_ERROR_CODE_UNKNOWN
+}
+
+func (x *WireError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *WireError) GetActualVersion() uint64 {
+	if x != nil {
+		return x.ActualVersion
+	}
+	return 0
+}
+
+type WireEventData struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type        string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	ContentType string `protobuf:"bytes,2,opt,name=contentType,proto3" json:"contentType,omitempty"`
+	Data        []byte `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	// Data subject whose key the data is encrypted with at rest.
+	Subject string `protobuf:"bytes,4,opt,name=subject,proto3" json:"subject,omitempty"`
+	// Client generated 16 byte UUID, used to deduplicate retried appends.
+	Id       []byte             `protobuf:"bytes,5,opt,name=id,proto3" json:"id,omitempty"`
+	Metadata *WireEventMetadata `protobuf:"bytes,6,opt,name=metadata,proto3" json:"metadata,omitempty"`
+}
+
+func (x *WireEventData) Reset() {
+	*x = WireEventData{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireEventData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireEventData) ProtoMessage() {}
+
+func (x *WireEventData) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_v1_tcp_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*WireClientHello); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireEventData.ProtoReflect.Descriptor instead.
+func (*WireEventData) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *WireEventData) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *WireEventData) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *WireEventData) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *WireEventData) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *WireEventData) GetId() []byte {
+	if x != nil {
+		return x.Id
+	}
+	return nil
+}
+
+func (x *WireEventData) GetMetadata() *WireEventMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type WireEventMetadata struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Identifies the chain of events the event is part of.
+	CorrelationId string `protobuf:"bytes,1,opt,name=correlationId,proto3" json:"correlationId,omitempty"`
+	// ID of the event that caused the event.
+	CausationId string `protobuf:"bytes,2,opt,name=causationId,proto3" json:"causationId,omitempty"`
+	// Who appended the event. Set by the server, empty for anonymous clients.
+	Principal string            `protobuf:"bytes,3,opt,name=principal,proto3" json:"principal,omitempty"`
+	Headers   map[string]string `protobuf:"bytes,4,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *WireEventMetadata) Reset() {
+	*x = WireEventMetadata{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireEventMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireEventMetadata) ProtoMessage() {}
+
+func (x *WireEventMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_v1_tcp_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*WireServerHello); i {
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireEventMetadata.ProtoReflect.Descriptor instead.
+func (*WireEventMetadata) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *WireEventMetadata) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+func (x *WireEventMetadata) GetCausationId() string {
+	if x != nil {
+		return x.CausationId
+	}
+	return ""
+}
+
+func (x *WireEventMetadata) GetPrincipal() string {
+	if x != nil {
+		return x.Principal
+	}
+	return ""
+}
+
+func (x *WireEventMetadata) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+type WireLink struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Derived stream the event was linked to.
+	Stream string `protobuf:"bytes,1,opt,name=stream,proto3" json:"stream,omitempty"`
+	// Version of the link in the derived stream.
+	Version uint64 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+}
+
+func (x *WireLink) Reset() {
+	*x = WireLink{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireLink) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireLink) ProtoMessage() {}
+
+func (x *WireLink) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireLink.ProtoReflect.Descriptor instead.
+func (*WireLink) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *WireLink) GetStream() string {
+	if x != nil {
+		return x.Stream
+	}
+	return ""
+}
+
+func (x *WireLink) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type WireEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Stream      string `protobuf:"bytes,1,opt,name=stream,proto3" json:"stream,omitempty"`
+	Version     uint64 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	Position    uint64 `protobuf:"varint,3,opt,name=position,proto3" json:"position,omitempty"`
+	Type        string `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	ContentType string `protobuf:"bytes,5,opt,name=contentType,proto3" json:"contentType,omitempty"`
+	Data        []byte `protobuf:"bytes,6,opt,name=data,proto3" json:"data,omitempty"`
+	// Set when the event was read through a link in a derived stream.
+	Link    *WireLink `protobuf:"bytes,7,opt,name=link,proto3" json:"link,omitempty"`
+	Subject string    `protobuf:"bytes,8,opt,name=subject,proto3" json:"subject,omitempty"`
+	// Set when the subject was forgotten, leaving data empty.
+	Shredded bool               `protobuf:"varint,9,opt,name=shredded,proto3" json:"shredded,omitempty"`
+	Id       []byte             `protobuf:"bytes,10,opt,name=id,proto3" json:"id,omitempty"`
+	Metadata *WireEventMetadata `protobuf:"bytes,11,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	// Time the event was committed by the server, in Unix nanoseconds.
+	Committed int64 `protobuf:"varint,12,opt,name=committed,proto3" json:"committed,omitempty"`
+}
+
+func (x *WireEvent) Reset() {
+	*x = WireEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireEvent) ProtoMessage() {}
+
+func (x *WireEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireEvent.ProtoReflect.Descriptor instead.
+func (*WireEvent) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *WireEvent) GetStream() string {
+	if x != nil {
+		return x.Stream
+	}
+	return ""
+}
+
+func (x *WireEvent) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *WireEvent) GetPosition() uint64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *WireEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *WireEvent) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *WireEvent) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *WireEvent) GetLink() *WireLink {
+	if x != nil {
+		return x.Link
+	}
+	return nil
+}
+
+func (x *WireEvent) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *WireEvent) GetShredded() bool {
+	if x != nil {
+		return x.Shredded
+	}
+	return false
+}
+
+func (x *WireEvent) GetId() []byte {
+	if x != nil {
+		return x.Id
+	}
+	return nil
+}
+
+func (x *WireEvent) GetMetadata() *WireEventMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *WireEvent) GetCommitted() int64 {
+	if x != nil {
+		return x.Committed
+	}
+	return 0
+}
+
+type WireAppendRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Stream string `protobuf:"bytes,1,opt,name=stream,proto3" json:"stream,omitempty"`
+	// Version the stream must be at for the append to succeed, either exact
+	// or one of WireExpectedVersion.
+	ExpectedVersion int64            `protobuf:"varint,2,opt,name=expectedVersion,proto3" json:"expectedVersion,omitempty"`
+	Events          []*WireEventData `protobuf:"bytes,3,rep,name=events,proto3" json:"events,omitempty"`
+}
+
+func (x *WireAppendRequest) Reset() {
+	*x = WireAppendRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireAppendRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireAppendRequest) ProtoMessage() {}
+
+func (x *WireAppendRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireAppendRequest.ProtoReflect.Descriptor instead.
+func (*WireAppendRequest) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *WireAppendRequest) GetStream() string {
+	if x != nil {
+		return x.Stream
+	}
+	return ""
+}
+
+func (x *WireAppendRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+func (x *WireAppendRequest) GetEvents() []*WireEventData {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type WireAppendResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Version of the stream after the append.
+	Version uint64 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	// Global position of the last appended event.
+	Position uint64 `protobuf:"varint,2,opt,name=position,proto3" json:"position,omitempty"`
+}
+
+func (x *WireAppendResult) Reset() {
+	*x = WireAppendResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireAppendResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireAppendResult) ProtoMessage() {}
+
+func (x *WireAppendResult) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireAppendResult.ProtoReflect.Descriptor instead.
+func (*WireAppendResult) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *WireAppendResult) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *WireAppendResult) GetPosition() uint64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+type WireReadStreamRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Stream string `protobuf:"bytes,1,opt,name=stream,proto3" json:"stream,omitempty"`
+	// Read events with a version greater than fromVersion.
+	FromVersion uint64 `protobuf:"varint,2,opt,name=fromVersion,proto3" json:"fromVersion,omitempty"`
+	MaxCount    uint32 `protobuf:"varint,3,opt,name=maxCount,proto3" json:"maxCount,omitempty"`
+	// Only read events committed before asOf, in Unix nanoseconds. 0 reads
+	// all events.
+	AsOf int64 `protobuf:"varint,4,opt,name=asOf,proto3" json:"asOf,omitempty"`
+}
+
+func (x *WireReadStreamRequest) Reset() {
+	*x = WireReadStreamRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireReadStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireReadStreamRequest) ProtoMessage() {}
+
+func (x *WireReadStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireReadStreamRequest.ProtoReflect.Descriptor instead.
+func (*WireReadStreamRequest) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *WireReadStreamRequest) GetStream() string {
+	if x != nil {
+		return x.Stream
+	}
+	return ""
+}
+
+func (x *WireReadStreamRequest) GetFromVersion() uint64 {
+	if x != nil {
+		return x.FromVersion
+	}
+	return 0
+}
+
+func (x *WireReadStreamRequest) GetMaxCount() uint32 {
+	if x != nil {
+		return x.MaxCount
+	}
+	return 0
+}
+
+func (x *WireReadStreamRequest) GetAsOf() int64 {
+	if x != nil {
+		return x.AsOf
+	}
+	return 0
+}
+
+type WireReadStreamResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Events []*WireEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+}
+
+func (x *WireReadStreamResult) Reset() {
+	*x = WireReadStreamResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireReadStreamResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireReadStreamResult) ProtoMessage() {}
+
+func (x *WireReadStreamResult) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireReadStreamResult.ProtoReflect.Descriptor instead.
+func (*WireReadStreamResult) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *WireReadStreamResult) GetEvents() []*WireEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type WireSubscribeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Stream to subscribe to, or "$all" for all streams.
+	Stream string `protobuf:"bytes,1,opt,name=stream,proto3" json:"stream,omitempty"`
+	// When set, events after this version (position for "$all") are read
+	// from storage before switching to live events.
+	From *uint64 `protobuf:"varint,2,opt,name=from,proto3,oneof" json:"from,omitempty"`
+	// When above 1, the server may push up to maxBatch events in a single
+	// WireSubscriptionEvents frame rather than one frame per event.
+	MaxBatch uint32 `protobuf:"varint,3,opt,name=maxBatch,proto3" json:"maxBatch,omitempty"`
+	// When set, the server pushes at most credit events until granted more
+	// with WireSubscriptionCredit frames. Unlimited when unset.
+	Credit uint32 `protobuf:"varint,4,opt,name=credit,proto3" json:"credit,omitempty"`
+}
+
+func (x *WireSubscribeRequest) Reset() {
+	*x = WireSubscribeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireSubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireSubscribeRequest) ProtoMessage() {}
+
+func (x *WireSubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireSubscribeRequest.ProtoReflect.Descriptor instead.
+func (*WireSubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *WireSubscribeRequest) GetStream() string {
+	if x != nil {
+		return x.Stream
+	}
+	return ""
+}
+
+func (x *WireSubscribeRequest) GetFrom() uint64 {
+	if x != nil && x.From != nil {
+		return *x.From
+	}
+	return 0
+}
+
+func (x *WireSubscribeRequest) GetMaxBatch() uint32 {
+	if x != nil {
+		return x.MaxBatch
+	}
+	return 0
+}
+
+func (x *WireSubscribeRequest) GetCredit() uint32 {
+	if x != nil {
+		return x.Credit
+	}
+	return 0
+}
+
+// WireSubscriptionCredit grants a subscription credit for more events. It is
+// sent in response to the subscribe frame.
+type WireSubscriptionCredit struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Credit uint32 `protobuf:"varint,1,opt,name=credit,proto3" json:"credit,omitempty"`
+}
+
+func (x *WireSubscriptionCredit) Reset() {
+	*x = WireSubscriptionCredit{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireSubscriptionCredit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireSubscriptionCredit) ProtoMessage() {}
+
+func (x *WireSubscriptionCredit) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireSubscriptionCredit.ProtoReflect.Descriptor instead.
+func (*WireSubscriptionCredit) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *WireSubscriptionCredit) GetCredit() uint32 {
+	if x != nil {
+		return x.Credit
+	}
+	return 0
+}
+
+type WireSubscriptionEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Event *WireEvent `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+}
+
+func (x *WireSubscriptionEvent) Reset() {
+	*x = WireSubscriptionEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireSubscriptionEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireSubscriptionEvent) ProtoMessage() {}
+
+func (x *WireSubscriptionEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireSubscriptionEvent.ProtoReflect.Descriptor instead.
+func (*WireSubscriptionEvent) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *WireSubscriptionEvent) GetEvent() *WireEvent {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+// WireSubscriptionEvents carries consecutive events of a subscription.
+type WireSubscriptionEvents struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Events []*WireEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+}
+
+func (x *WireSubscriptionEvents) Reset() {
+	*x = WireSubscriptionEvents{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireSubscriptionEvents) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireSubscriptionEvents) ProtoMessage() {}
+
+func (x *WireSubscriptionEvents) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireSubscriptionEvents.ProtoReflect.Descriptor instead.
+func (*WireSubscriptionEvents) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *WireSubscriptionEvents) GetEvents() []*WireEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type WireSnapshot struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Stream string `protobuf:"bytes,1,opt,name=stream,proto3" json:"stream,omitempty"`
+	// Version of the stream the snapshot state was taken at.
+	Version uint64         `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	State   *WireEventData `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+}
+
+func (x *WireSnapshot) Reset() {
+	*x = WireSnapshot{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireSnapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireSnapshot) ProtoMessage() {}
+
+func (x *WireSnapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireSnapshot.ProtoReflect.Descriptor instead.
+func (*WireSnapshot) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *WireSnapshot) GetStream() string {
+	if x != nil {
+		return x.Stream
+	}
+	return ""
+}
+
+func (x *WireSnapshot) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *WireSnapshot) GetState() *WireEventData {
+	if x != nil {
+		return x.State
+	}
+	return nil
+}
+
+type WireWriteSnapshotRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Snapshot *WireSnapshot `protobuf:"bytes,1,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+}
+
+func (x *WireWriteSnapshotRequest) Reset() {
+	*x = WireWriteSnapshotRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireWriteSnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireWriteSnapshotRequest) ProtoMessage() {}
+
+func (x *WireWriteSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireWriteSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*WireWriteSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *WireWriteSnapshotRequest) GetSnapshot() *WireSnapshot {
+	if x != nil {
+		return x.Snapshot
+	}
+	return nil
+}
+
+type WireReadSnapshotRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Stream string `protobuf:"bytes,1,opt,name=stream,proto3" json:"stream,omitempty"`
+}
+
+func (x *WireReadSnapshotRequest) Reset() {
+	*x = WireReadSnapshotRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireReadSnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireReadSnapshotRequest) ProtoMessage() {}
+
+func (x *WireReadSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireReadSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*WireReadSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *WireReadSnapshotRequest) GetStream() string {
+	if x != nil {
+		return x.Stream
+	}
+	return ""
+}
+
+type WireReadSnapshotResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Latest snapshot of the stream, unset if none was written.
+	Snapshot *WireSnapshot `protobuf:"bytes,1,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+}
+
+func (x *WireReadSnapshotResult) Reset() {
+	*x = WireReadSnapshotResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireReadSnapshotResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireReadSnapshotResult) ProtoMessage() {}
+
+func (x *WireReadSnapshotResult) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireReadSnapshotResult.ProtoReflect.Descriptor instead.
+func (*WireReadSnapshotResult) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *WireReadSnapshotResult) GetSnapshot() *WireSnapshot {
+	if x != nil {
+		return x.Snapshot
+	}
+	return nil
+}
+
+type WireCheckpoint struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Name of the checkpoint, typically of the projection it belongs to.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Position of the last event processed under the name.
+	Position uint64 `protobuf:"varint,2,opt,name=position,proto3" json:"position,omitempty"`
+	// Member of the consumer group writing the checkpoint. Writes are
+	// rejected unless the member holds the lease of the group, and writes
+	// without a member unless no member does.
+	Member string `protobuf:"bytes,3,opt,name=member,proto3" json:"member,omitempty"`
+}
+
+func (x *WireCheckpoint) Reset() {
+	*x = WireCheckpoint{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireCheckpoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireCheckpoint) ProtoMessage() {}
+
+func (x *WireCheckpoint) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireCheckpoint.ProtoReflect.Descriptor instead.
+func (*WireCheckpoint) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *WireCheckpoint) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *WireCheckpoint) GetPosition() uint64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *WireCheckpoint) GetMember() string {
+	if x != nil {
+		return x.Member
+	}
+	return ""
+}
+
+type WireReadCheckpointRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *WireReadCheckpointRequest) Reset() {
+	*x = WireReadCheckpointRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireReadCheckpointRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireReadCheckpointRequest) ProtoMessage() {}
+
+func (x *WireReadCheckpointRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireReadCheckpointRequest.ProtoReflect.Descriptor instead.
+func (*WireReadCheckpointRequest) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *WireReadCheckpointRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// WireCheckpointLeaseRequest acquires or renews the lease of a consumer group
+// for a member, or releases it when ttl is 0. The lease is granted when no
+// other member holds it.
+type WireCheckpointLeaseRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Name of the group, which is the name of its checkpoint.
+	Name   string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Member string `protobuf:"bytes,2,opt,name=member,proto3" json:"member,omitempty"`
+	// Milliseconds the lease is held for, unless renewed.
+	Ttl uint64 `protobuf:"varint,3,opt,name=ttl,proto3" json:"ttl,omitempty"`
+}
+
+func (x *WireCheckpointLeaseRequest) Reset() {
+	*x = WireCheckpointLeaseRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireCheckpointLeaseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireCheckpointLeaseRequest) ProtoMessage() {}
+
+func (x *WireCheckpointLeaseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireCheckpointLeaseRequest.ProtoReflect.Descriptor instead.
+func (*WireCheckpointLeaseRequest) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *WireCheckpointLeaseRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *WireCheckpointLeaseRequest) GetMember() string {
+	if x != nil {
+		return x.Member
+	}
+	return ""
+}
+
+func (x *WireCheckpointLeaseRequest) GetTtl() uint64 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+// WireCheckpointLease tells which member holds the lease of a consumer group.
+type WireCheckpointLease struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Member holding the lease, empty when none does.
+	Holder string `protobuf:"bytes,2,opt,name=holder,proto3" json:"holder,omitempty"`
+}
+
+func (x *WireCheckpointLease) Reset() {
+	*x = WireCheckpointLease{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireCheckpointLease) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireCheckpointLease) ProtoMessage() {}
+
+func (x *WireCheckpointLease) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireCheckpointLease.ProtoReflect.Descriptor instead.
+func (*WireCheckpointLease) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *WireCheckpointLease) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *WireCheckpointLease) GetHolder() string {
+	if x != nil {
+		return x.Holder
+	}
+	return ""
+}
+
+type WireStreamMetadata struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Stream string `protobuf:"bytes,1,opt,name=stream,proto3" json:"stream,omitempty"`
+	// Events appended longer than maxAge milliseconds ago are expired. 0 for
+	// no limit.
+	MaxAge uint64 `protobuf:"varint,2,opt,name=maxAge,proto3" json:"maxAge,omitempty"`
+	// Only the last maxCount events are kept. 0 for no limit.
+	MaxCount uint64 `protobuf:"varint,3,opt,name=maxCount,proto3" json:"maxCount,omitempty"`
+	// Events with a version less than truncateBefore are expired.
+	TruncateBefore uint64            `protobuf:"varint,4,opt,name=truncateBefore,proto3" json:"truncateBefore,omitempty"`
+	Custom         map[string]string `protobuf:"bytes,5,rep,name=custom,proto3" json:"custom,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *WireStreamMetadata) Reset() {
+	*x = WireStreamMetadata{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireStreamMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireStreamMetadata) ProtoMessage() {}
+
+func (x *WireStreamMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireStreamMetadata.ProtoReflect.Descriptor instead.
+func (*WireStreamMetadata) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *WireStreamMetadata) GetStream() string {
+	if x != nil {
+		return x.Stream
+	}
+	return ""
+}
+
+func (x *WireStreamMetadata) GetMaxAge() uint64 {
+	if x != nil {
+		return x.MaxAge
+	}
+	return 0
+}
+
+func (x *WireStreamMetadata) GetMaxCount() uint64 {
+	if x != nil {
+		return x.MaxCount
+	}
+	return 0
+}
+
+func (x *WireStreamMetadata) GetTruncateBefore() uint64 {
+	if x != nil {
+		return x.TruncateBefore
+	}
+	return 0
+}
+
+func (x *WireStreamMetadata) GetCustom() map[string]string {
+	if x != nil {
+		return x.Custom
+	}
+	return nil
+}
+
+type WireReadStreamMetadataRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Stream string `protobuf:"bytes,1,opt,name=stream,proto3" json:"stream,omitempty"`
+}
+
+func (x *WireReadStreamMetadataRequest) Reset() {
+	*x = WireReadStreamMetadataRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireReadStreamMetadataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireReadStreamMetadataRequest) ProtoMessage() {}
+
+func (x *WireReadStreamMetadataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireReadStreamMetadataRequest.ProtoReflect.Descriptor instead.
+func (*WireReadStreamMetadataRequest) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *WireReadStreamMetadataRequest) GetStream() string {
+	if x != nil {
+		return x.Stream
+	}
+	return ""
+}
+
+type WireDeleteStreamRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Stream string `protobuf:"bytes,1,opt,name=stream,proto3" json:"stream,omitempty"`
+	// Version the stream must be at for the delete to succeed, either exact
+	// or one of WireExpectedVersion.
+	ExpectedVersion int64 `protobuf:"varint,2,opt,name=expectedVersion,proto3" json:"expectedVersion,omitempty"`
+	// Delete permanently, forbidding future appends.
+	Hard bool `protobuf:"varint,3,opt,name=hard,proto3" json:"hard,omitempty"`
+}
+
+func (x *WireDeleteStreamRequest) Reset() {
+	*x = WireDeleteStreamRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireDeleteStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireDeleteStreamRequest) ProtoMessage() {}
+
+func (x *WireDeleteStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireDeleteStreamRequest.ProtoReflect.Descriptor instead.
+func (*WireDeleteStreamRequest) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *WireDeleteStreamRequest) GetStream() string {
+	if x != nil {
+		return x.Stream
+	}
+	return ""
+}
+
+func (x *WireDeleteStreamRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+func (x *WireDeleteStreamRequest) GetHard() bool {
+	if x != nil {
+		return x.Hard
+	}
+	return false
+}
+
+type WireStreamDeleted struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Stream string `protobuf:"bytes,1,opt,name=stream,proto3" json:"stream,omitempty"`
+	// Version the stream was deleted at.
+	Version uint64 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	Hard    bool   `protobuf:"varint,3,opt,name=hard,proto3" json:"hard,omitempty"`
+}
+
+func (x *WireStreamDeleted) Reset() {
+	*x = WireStreamDeleted{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireStreamDeleted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireStreamDeleted) ProtoMessage() {}
+
+func (x *WireStreamDeleted) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireStreamDeleted.ProtoReflect.Descriptor instead.
+func (*WireStreamDeleted) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *WireStreamDeleted) GetStream() string {
+	if x != nil {
+		return x.Stream
+	}
+	return ""
+}
+
+func (x *WireStreamDeleted) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *WireStreamDeleted) GetHard() bool {
+	if x != nil {
+		return x.Hard
+	}
+	return false
+}
+
+type WireForgetSubjectRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Subject string `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+}
+
+func (x *WireForgetSubjectRequest) Reset() {
+	*x = WireForgetSubjectRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireForgetSubjectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireForgetSubjectRequest) ProtoMessage() {}
+
+func (x *WireForgetSubjectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireForgetSubjectRequest.ProtoReflect.Descriptor instead.
+func (*WireForgetSubjectRequest) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *WireForgetSubjectRequest) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+type WireTransaction struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// ID assigned by the server when beginning the transaction.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *WireTransaction) Reset() {
+	*x = WireTransaction{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireTransaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireTransaction) ProtoMessage() {}
+
+func (x *WireTransaction) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireTransaction.ProtoReflect.Descriptor instead.
+func (*WireTransaction) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *WireTransaction) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type WireTransactionAppendRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TransactionId string             `protobuf:"bytes,1,opt,name=transactionId,proto3" json:"transactionId,omitempty"`
+	Append        *WireAppendRequest `protobuf:"bytes,2,opt,name=append,proto3" json:"append,omitempty"`
+}
+
+func (x *WireTransactionAppendRequest) Reset() {
+	*x = WireTransactionAppendRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireTransactionAppendRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireTransactionAppendRequest) ProtoMessage() {}
+
+func (x *WireTransactionAppendRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireTransactionAppendRequest.ProtoReflect.Descriptor instead.
+func (*WireTransactionAppendRequest) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *WireTransactionAppendRequest) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *WireTransactionAppendRequest) GetAppend() *WireAppendRequest {
+	if x != nil {
+		return x.Append
+	}
+	return nil
+}
+
+type WireTransactionCommitted struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Results of the appends, in the order they were made.
+	Results []*WireAppendResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	// Range of global positions of the committed events.
+	FirstPosition uint64 `protobuf:"varint,2,opt,name=firstPosition,proto3" json:"firstPosition,omitempty"`
+	LastPosition  uint64 `protobuf:"varint,3,opt,name=lastPosition,proto3" json:"lastPosition,omitempty"`
+}
+
+func (x *WireTransactionCommitted) Reset() {
+	*x = WireTransactionCommitted{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireTransactionCommitted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireTransactionCommitted) ProtoMessage() {}
+
+func (x *WireTransactionCommitted) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireTransactionCommitted.ProtoReflect.Descriptor instead.
+func (*WireTransactionCommitted) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *WireTransactionCommitted) GetResults() []*WireAppendResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *WireTransactionCommitted) GetFirstPosition() uint64 {
+	if x != nil {
+		return x.FirstPosition
+	}
+	return 0
+}
+
+func (x *WireTransactionCommitted) GetLastPosition() uint64 {
+	if x != nil {
+		return x.LastPosition
+	}
+	return 0
+}
+
+type WireFilter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Match events of streams starting with any of the prefixes.
+	StreamPrefixes []string `protobuf:"bytes,1,rep,name=streamPrefixes,proto3" json:"streamPrefixes,omitempty"`
+	// Match events with a type starting with any of the prefixes.
+	EventTypePrefixes []string `protobuf:"bytes,2,rep,name=eventTypePrefixes,proto3" json:"eventTypePrefixes,omitempty"`
+	// Match events of streams matching the RE2 regular expression.
+	StreamRegex string `protobuf:"bytes,3,opt,name=streamRegex,proto3" json:"streamRegex,omitempty"`
+	// Match events with a type matching the RE2 regular expression.
+	EventTypeRegex string `protobuf:"bytes,4,opt,name=eventTypeRegex,proto3" json:"eventTypeRegex,omitempty"`
+}
+
+func (x *WireFilter) Reset() {
+	*x = WireFilter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireFilter) ProtoMessage() {}
+
+func (x *WireFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireFilter.ProtoReflect.Descriptor instead.
+func (*WireFilter) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *WireFilter) GetStreamPrefixes() []string {
+	if x != nil {
+		return x.StreamPrefixes
+	}
+	return nil
+}
+
+func (x *WireFilter) GetEventTypePrefixes() []string {
+	if x != nil {
+		return x.EventTypePrefixes
+	}
+	return nil
+}
+
+func (x *WireFilter) GetStreamRegex() string {
+	if x != nil {
+		return x.StreamRegex
+	}
+	return ""
+}
+
+func (x *WireFilter) GetEventTypeRegex() string {
+	if x != nil {
+		return x.EventTypeRegex
+	}
+	return ""
+}
+
+type WireReadAllRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Read events after fromPosition, or before it when reading backwards,
+	// where 0 means the end of the log.
+	FromPosition uint64      `protobuf:"varint,1,opt,name=fromPosition,proto3" json:"fromPosition,omitempty"`
+	Backwards    bool        `protobuf:"varint,2,opt,name=backwards,proto3" json:"backwards,omitempty"`
+	MaxCount     uint32      `protobuf:"varint,3,opt,name=maxCount,proto3" json:"maxCount,omitempty"`
+	Filter       *WireFilter `protobuf:"bytes,4,opt,name=filter,proto3" json:"filter,omitempty"`
+	// Only read events committed before asOf, in Unix nanoseconds. 0 reads
+	// all events.
+	AsOf int64 `protobuf:"varint,5,opt,name=asOf,proto3" json:"asOf,omitempty"`
+}
+
+func (x *WireReadAllRequest) Reset() {
+	*x = WireReadAllRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireReadAllRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireReadAllRequest) ProtoMessage() {}
+
+func (x *WireReadAllRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireReadAllRequest.ProtoReflect.Descriptor instead.
+func (*WireReadAllRequest) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *WireReadAllRequest) GetFromPosition() uint64 {
+	if x != nil {
+		return x.FromPosition
+	}
+	return 0
+}
+
+func (x *WireReadAllRequest) GetBackwards() bool {
+	if x != nil {
+		return x.Backwards
+	}
+	return false
+}
+
+func (x *WireReadAllRequest) GetMaxCount() uint32 {
+	if x != nil {
+		return x.MaxCount
+	}
+	return 0
+}
+
+func (x *WireReadAllRequest) GetFilter() *WireFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *WireReadAllRequest) GetAsOf() int64 {
+	if x != nil {
+		return x.AsOf
+	}
+	return 0
+}
+
+type WireReadAllResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Events []*WireEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	// Position of the last event scanned, matching the filter or not, to
+	// continue reading from.
+	Checkpoint uint64 `protobuf:"varint,2,opt,name=checkpoint,proto3" json:"checkpoint,omitempty"`
+	// Set when the end of the log was reached.
+	End bool `protobuf:"varint,3,opt,name=end,proto3" json:"end,omitempty"`
+}
+
+func (x *WireReadAllResult) Reset() {
+	*x = WireReadAllResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v1_tcp_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WireReadAllResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WireReadAllResult) ProtoMessage() {}
+
+func (x *WireReadAllResult) ProtoReflect() protoreflect.Message {
+	mi := &file_v1_tcp_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WireReadAllResult.ProtoReflect.Descriptor instead.
+func (*WireReadAllResult) Descriptor() ([]byte, []int) {
+	return file_v1_tcp_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *WireReadAllResult) GetEvents() []*WireEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *WireReadAllResult) GetCheckpoint() uint64 {
+	if x != nil {
+		return x.Checkpoint
+	}
+	return 0
+}
+
+func (x *WireReadAllResult) GetEnd() bool {
+	if x != nil {
+		return x.End
+	}
+	return false
+}
+
+var File_v1_tcp_proto protoreflect.FileDescriptor
+
+var file_v1_tcp_proto_rawDesc = []byte{
+	0x0a, 0x0c, 0x76, 0x31, 0x2f, 0x74, 0x63, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x08,
+	0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x22, 0x53, 0x0a, 0x0f, 0x53, 0x65, 0x6d, 0x61,
+	0x6e, 0x74, 0x69, 0x63, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x6d,
+	0x61, 0x6a, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6d, 0x61, 0x6a, 0x6f,
+	0x72, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x69, 0x6e, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x05, 0x6d, 0x69, 0x6e, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x61, 0x74, 0x63, 0x68,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x70, 0x61, 0x74, 0x63, 0x68, 0x22, 0xd3, 0x01,
+	0x0a, 0x0f, 0x57, 0x69, 0x72, 0x65, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x48, 0x65, 0x6c, 0x6c,
+	0x6f, 0x12, 0x3f, 0x0a, 0x0d, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74,
+	0x61, 0x6c, 0x65, 0x2e, 0x53, 0x65, 0x6d, 0x61, 0x6e, 0x74, 0x69, 0x63, 0x56, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x52, 0x0d, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65,
+	0x12, 0x3d, 0x0a, 0x0c, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c,
+	0x65, 0x2e, 0x57, 0x69, 0x72, 0x65, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x52, 0x0c, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12,
+	0x22, 0x0a, 0x0c, 0x6d, 0x61, 0x78, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x6d, 0x61, 0x78, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x53,
+	0x69, 0x7a, 0x65, 0x22, 0xd9, 0x01, 0x0a, 0x0f, 0x57, 0x69, 0x72, 0x65, 0x53, 0x65, 0x72, 0x76,
+	0x65, 0x72, 0x48, 0x65, 0x6c, 0x6c, 0x6f, 0x12, 0x3f, 0x0a, 0x0d, 0x73, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19,
+	0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2e, 0x53, 0x65, 0x6d, 0x61, 0x6e, 0x74,
+	0x69, 0x63, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0d, 0x73, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x24, 0x0a, 0x0d, 0x65, 0x6e, 0x63, 0x72,
+	0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x4b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x0d, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x4b, 0x65, 0x79, 0x12, 0x3b,
+	0x0a, 0x0b, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2e, 0x57,
+	0x69, 0x72, 0x65, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0b,
+	0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x22, 0x0a, 0x0c, 0x6d,
+	0x61, 0x78, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x0c, 0x6d, 0x61, 0x78, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x22,
+	0x78, 0x0a, 0x09, 0x57, 0x69, 0x72, 0x65, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x2b, 0x0a, 0x04,
+	0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x65, 0x76, 0x65,
+	0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2e, 0x57, 0x69, 0x72, 0x65, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x43,
+	0x6f, 0x64, 0x65, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x12, 0x24, 0x0a, 0x0d, 0x61, 0x63, 0x74, 0x75, 0x61, 0x6c, 0x56, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0d, 0x61, 0x63, 0x74, 0x75,
+	0x61, 0x6c, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0xbc, 0x01, 0x0a, 0x0d, 0x57, 0x69,
+	0x72, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x44, 0x61, 0x74, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x74,
+	0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12,
+	0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x12,
+	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x02, 0x69, 0x64, 0x12,
+	0x37, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1b, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2e, 0x57, 0x69, 0x72,
+	0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x52, 0x08,
+	0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x22, 0xf9, 0x01, 0x0a, 0x11, 0x57, 0x69, 0x72,
+	0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x24,
+	0x0a, 0x0d, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x49, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x61, 0x75, 0x73, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x49, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x61, 0x75, 0x73, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x72, 0x69, 0x6e, 0x63, 0x69,
+	0x70, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x72, 0x69, 0x6e, 0x63,
+	0x69, 0x70, 0x61, 0x6c, 0x12, 0x42, 0x0a, 0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x18,
+	0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65,
+	0x2e, 0x57, 0x69, 0x72, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61,
+	0x74, 0x61, 0x2e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x1a, 0x3a, 0x0a, 0x0c, 0x48, 0x65, 0x61, 0x64,
+	0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x22, 0x3c, 0x0a, 0x08, 0x57, 0x69, 0x72, 0x65, 0x4c, 0x69, 0x6e, 0x6b,
+	0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x22, 0xe8, 0x02, 0x0a, 0x09, 0x57, 0x69, 0x72, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12,
+	0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79,
+	0x70, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70,
+	0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74,
+	0x54, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x26, 0x0a, 0x04, 0x6c, 0x69, 0x6e, 0x6b,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c,
+	0x65, 0x2e, 0x57, 0x69, 0x72, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x52, 0x04, 0x6c, 0x69, 0x6e, 0x6b,
+	0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x73, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x68,
+	0x72, 0x65, 0x64, 0x64, 0x65, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x73, 0x68,
+	0x72, 0x65, 0x64, 0x64, 0x65, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x0a, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x02, 0x69, 0x64, 0x12, 0x37, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61,
+	0x74, 0x61, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74,
+	0x61, 0x6c, 0x65, 0x2e, 0x57, 0x69, 0x72, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x4d, 0x65, 0x74,
+	0x61, 0x64, 0x61, 0x74, 0x61, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12,
+	0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x18, 0x0c, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x09, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x22, 0x86, 0x01,
+	0x0a, 0x11, 0x57, 0x69, 0x72, 0x65, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x28, 0x0a, 0x0f, 0x65,
+	0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x56, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2f, 0x0a, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x18,
+	0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65,
+	0x2e, 0x57, 0x69, 0x72, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x06,
+	0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x22, 0x48, 0x0a, 0x10, 0x57, 0x69, 0x72, 0x65, 0x41, 0x70,
+	0x70, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x76, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+	0x22, 0x81, 0x01, 0x0a, 0x15, 0x57, 0x69, 0x72, 0x65, 0x52, 0x65, 0x61, 0x64, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x12, 0x20, 0x0a, 0x0b, 0x66, 0x72, 0x6f, 0x6d, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x66, 0x72, 0x6f, 0x6d, 0x56, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x61, 0x78, 0x43, 0x6f, 0x75, 0x6e, 0x74,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x43, 0x6f, 0x75, 0x6e, 0x74,
+	0x12, 0x12, 0x0a, 0x04, 0x61, 0x73, 0x4f, 0x66, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04,
+	0x61, 0x73, 0x4f, 0x66, 0x22, 0x43, 0x0a, 0x14, 0x57, 0x69, 0x72, 0x65, 0x52, 0x65, 0x61, 0x64,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x2b, 0x0a, 0x06,
+	0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x65,
+	0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2e, 0x57, 0x69, 0x72, 0x65, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x52, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x22, 0x84, 0x01, 0x0a, 0x14, 0x57, 0x69,
+	0x72, 0x65, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x17, 0x0a, 0x04, 0x66, 0x72,
+	0x6f, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x48, 0x00, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d,
+	0x88, 0x01, 0x01, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x61, 0x78, 0x42, 0x61, 0x74, 0x63, 0x68, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12,
+	0x16, 0x0a, 0x06, 0x63, 0x72, 0x65, 0x64, 0x69, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x06, 0x63, 0x72, 0x65, 0x64, 0x69, 0x74, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x66, 0x72, 0x6f, 0x6d,
+	0x22, 0x30, 0x0a, 0x16, 0x57, 0x69, 0x72, 0x65, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x43, 0x72, 0x65, 0x64, 0x69, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x72,
+	0x65, 0x64, 0x69, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x63, 0x72, 0x65, 0x64,
+	0x69, 0x74, 0x22, 0x42, 0x0a, 0x15, 0x57, 0x69, 0x72, 0x65, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72,
+	0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x29, 0x0a, 0x05, 0x65,
+	0x76, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x65, 0x76, 0x65,
+	0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2e, 0x57, 0x69, 0x72, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52,
+	0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x22, 0x45, 0x0a, 0x16, 0x57, 0x69, 0x72, 0x65, 0x53, 0x75,
+	0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73,
+	0x12, 0x2b, 0x0a, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x13, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2e, 0x57, 0x69, 0x72, 0x65,
+	0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x22, 0x6f, 0x0a,
+	0x0c, 0x57, 0x69, 0x72, 0x65, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x16, 0x0a,
+	0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73,
+	0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x2d, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17,
+	0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2e, 0x57, 0x69, 0x72, 0x65, 0x45, 0x76,
+	0x65, 0x6e, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x22, 0x4e,
+	0x0a, 0x18, 0x57, 0x69, 0x72, 0x65, 0x57, 0x72, 0x69, 0x74, 0x65, 0x53, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x32, 0x0a, 0x08, 0x73, 0x6e,
+	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x65,
+	0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2e, 0x57, 0x69, 0x72, 0x65, 0x53, 0x6e, 0x61, 0x70,
+	0x73, 0x68, 0x6f, 0x74, 0x52, 0x08, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x22, 0x31,
+	0x0a, 0x17, 0x57, 0x69, 0x72, 0x65, 0x52, 0x65, 0x61, 0x64, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68,
+	0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x22, 0x4c, 0x0a, 0x16, 0x57, 0x69, 0x72, 0x65, 0x52, 0x65, 0x61, 0x64, 0x53, 0x6e, 0x61,
+	0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x32, 0x0a, 0x08, 0x73,
+	0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e,
+	0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2e, 0x57, 0x69, 0x72, 0x65, 0x53, 0x6e, 0x61,
+	0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x08, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x22,
+	0x58, 0x0a, 0x0e, 0x57, 0x69, 0x72, 0x65, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e,
+	0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x22, 0x2f, 0x0a, 0x19, 0x57, 0x69, 0x72,
+	0x65, 0x52, 0x65, 0x61, 0x64, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x5a, 0x0a, 0x1a, 0x57, 0x69,
+	0x72, 0x65, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x4c, 0x65, 0x61, 0x73,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06,
+	0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65,
+	0x6d, 0x62, 0x65, 0x72, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x74, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x03, 0x74, 0x74, 0x6c, 0x22, 0x41, 0x0a, 0x13, 0x57, 0x69, 0x72, 0x65, 0x43, 0x68,
+	0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x4c, 0x65, 0x61, 0x73, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x68, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x22, 0x85, 0x02, 0x0a, 0x12, 0x57, 0x69,
+	0x72, 0x65, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61,
+	0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x61, 0x78, 0x41,
+	0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x6d, 0x61, 0x78, 0x41, 0x67, 0x65,
+	0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x61, 0x78, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x26, 0x0a, 0x0e,
+	0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x0e, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x42, 0x65,
+	0x66, 0x6f, 0x72, 0x65, 0x12, 0x40, 0x0a, 0x06, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x18, 0x05,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2e,
+	0x57, 0x69, 0x72, 0x65, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61,
+	0x74, 0x61, 0x2e, 0x43, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06,
+	0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x1a, 0x39, 0x0a, 0x0b, 0x43, 0x75, 0x73, 0x74, 0x6f, 0x6d,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38,
+	0x01, 0x22, 0x37, 0x0a, 0x1d, 0x57, 0x69, 0x72, 0x65, 0x52, 0x65, 0x61, 0x64, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x22, 0x6f, 0x0a, 0x17, 0x57, 0x69,
+	0x72, 0x65, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x28, 0x0a,
+	0x0f, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64,
+	0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x72, 0x64, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x68, 0x61, 0x72, 0x64, 0x22, 0x59, 0x0a, 0x11, 0x57,
+	0x69, 0x72, 0x65, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64,
+	0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x72, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x04, 0x68, 0x61, 0x72, 0x64, 0x22, 0x34, 0x0a, 0x18, 0x57, 0x69, 0x72, 0x65, 0x46, 0x6f,
+	0x72, 0x67, 0x65, 0x74, 0x53, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x22, 0x21, 0x0a, 0x0f,
+	0x57, 0x69, 0x72, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22,
+	0x79, 0x0a, 0x1c, 0x57, 0x69, 0x72, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x24, 0x0a, 0x0d, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x33, 0x0a, 0x06, 0x61, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65,
+	0x2e, 0x57, 0x69, 0x72, 0x65, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x52, 0x06, 0x61, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x22, 0x9a, 0x01, 0x0a, 0x18, 0x57,
+	0x69, 0x72, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6f,
+	0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x12, 0x34, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74,
+	0x61, 0x6c, 0x65, 0x2e, 0x57, 0x69, 0x72, 0x65, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x52, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x52, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x12, 0x24, 0x0a,
+	0x0d, 0x66, 0x69, 0x72, 0x73, 0x74, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x0d, 0x66, 0x69, 0x72, 0x73, 0x74, 0x50, 0x6f, 0x73, 0x69, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x22, 0x0a, 0x0c, 0x6c, 0x61, 0x73, 0x74, 0x50, 0x6f, 0x73, 0x69, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x6c, 0x61, 0x73, 0x74, 0x50,
+	0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xac, 0x01, 0x0a, 0x0a, 0x57, 0x69, 0x72, 0x65,
+	0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x26, 0x0a, 0x0e, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d,
+	0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e,
+	0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x65, 0x73, 0x12, 0x2c,
+	0x0a, 0x11, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x50, 0x72, 0x65, 0x66, 0x69,
+	0x78, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x11, 0x65, 0x76, 0x65, 0x6e, 0x74,
+	0x54, 0x79, 0x70, 0x65, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x65, 0x73, 0x12, 0x20, 0x0a, 0x0b,
+	0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x67, 0x65, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x67, 0x65, 0x78, 0x12, 0x26,
+	0x0a, 0x0e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x65, 0x67, 0x65, 0x78,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70,
+	0x65, 0x52, 0x65, 0x67, 0x65, 0x78, 0x22, 0xb4, 0x01, 0x0a, 0x12, 0x57, 0x69, 0x72, 0x65, 0x52,
+	0x65, 0x61, 0x64, 0x41, 0x6c, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x22, 0x0a,
+	0x0c, 0x66, 0x72, 0x6f, 0x6d, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0c, 0x66, 0x72, 0x6f, 0x6d, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x62, 0x61, 0x63, 0x6b, 0x77, 0x61, 0x72, 0x64, 0x73, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x62, 0x61, 0x63, 0x6b, 0x77, 0x61, 0x72, 0x64, 0x73, 0x12,
+	0x1a, 0x0a, 0x08, 0x6d, 0x61, 0x78, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x2c, 0x0a, 0x06, 0x66,
+	0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x65, 0x76,
+	0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2e, 0x57, 0x69, 0x72, 0x65, 0x46, 0x69, 0x6c, 0x74, 0x65,
+	0x72, 0x52, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x73, 0x4f,
+	0x66, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x61, 0x73, 0x4f, 0x66, 0x22, 0x72, 0x0a,
+	0x11, 0x57, 0x69, 0x72, 0x65, 0x52, 0x65, 0x61, 0x64, 0x41, 0x6c, 0x6c, 0x52, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x12, 0x2b, 0x0a, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x13, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x65, 0x2e, 0x57, 0x69,
+	0x72, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x12,
+	0x1e, 0x0a, 0x0a, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0a, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x12,
+	0x10, 0x0a, 0x03, 0x65, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x03, 0x65, 0x6e,
+	0x64, 0x2a, 0x7f, 0x0a, 0x0f, 0x57, 0x69, 0x72, 0x65, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x19, 0x0a, 0x15, 0x57, 0x49, 0x52, 0x45, 0x5f, 0x43, 0x4f, 0x4d,
+	0x50, 0x52, 0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x5f, 0x4e, 0x4f, 0x4e, 0x45, 0x10, 0x00, 0x12,
+	0x19, 0x0a, 0x15, 0x57, 0x49, 0x52, 0x45, 0x5f, 0x43, 0x4f, 0x4d, 0x50, 0x52, 0x45, 0x53, 0x53,
+	0x49, 0x4f, 0x4e, 0x5f, 0x47, 0x5a, 0x49, 0x50, 0x10, 0x01, 0x12, 0x19, 0x0a, 0x15, 0x57, 0x49,
+	0x52, 0x45, 0x5f, 0x43, 0x4f, 0x4d, 0x50, 0x52, 0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x5f, 0x5a,
+	0x53, 0x54, 0x44, 0x10, 0x02, 0x12, 0x1b, 0x0a, 0x17, 0x57, 0x49, 0x52, 0x45, 0x5f, 0x43, 0x4f,
+	0x4d, 0x50, 0x52, 0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x5f, 0x53, 0x4e, 0x41, 0x50, 0x50, 0x59,
+	0x10, 0x03, 0x2a, 0xda, 0x02, 0x0a, 0x0d, 0x57, 0x69, 0x72, 0x65, 0x45, 0x72, 0x72, 0x6f, 0x72,
+	0x43, 0x6f, 0x64, 0x65, 0x12, 0x1b, 0x0a, 0x17, 0x57, 0x49, 0x52, 0x45, 0x5f, 0x45, 0x52, 0x52,
+	0x4f, 0x52, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10,
+	0x00, 0x12, 0x1f, 0x0a, 0x1b, 0x57, 0x49, 0x52, 0x45, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f,
+	0x43, 0x4f, 0x44, 0x45, 0x5f, 0x42, 0x41, 0x44, 0x5f, 0x52, 0x45, 0x51, 0x55, 0x45, 0x53, 0x54,
+	0x10, 0x01, 0x12, 0x20, 0x0a, 0x1c, 0x57, 0x49, 0x52, 0x45, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52,
+	0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x55, 0x4e, 0x41, 0x55, 0x54, 0x48, 0x4f, 0x52, 0x49, 0x5a,
+	0x45, 0x44, 0x10, 0x02, 0x12, 0x2a, 0x0a, 0x26, 0x57, 0x49, 0x52, 0x45, 0x5f, 0x45, 0x52, 0x52,
+	0x4f, 0x52, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x57, 0x52, 0x4f, 0x4e, 0x47, 0x5f, 0x45, 0x58,
+	0x50, 0x45, 0x43, 0x54, 0x45, 0x44, 0x5f, 0x56, 0x45, 0x52, 0x53, 0x49, 0x4f, 0x4e, 0x10, 0x03,
+	0x12, 0x1e, 0x0a, 0x1a, 0x57, 0x49, 0x52, 0x45, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x43,
+	0x4f, 0x44, 0x45, 0x5f, 0x4c, 0x45, 0x41, 0x53, 0x45, 0x5f, 0x4c, 0x4f, 0x53, 0x54, 0x10, 0x04,
+	0x12, 0x22, 0x0a, 0x1e, 0x57, 0x49, 0x52, 0x45, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x43,
+	0x4f, 0x44, 0x45, 0x5f, 0x53, 0x54, 0x52, 0x45, 0x41, 0x4d, 0x5f, 0x44, 0x45, 0x4c, 0x45, 0x54,
+	0x45, 0x44, 0x10, 0x05, 0x12, 0x29, 0x0a, 0x25, 0x57, 0x49, 0x52, 0x45, 0x5f, 0x45, 0x52, 0x52,
+	0x4f, 0x52, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x54, 0x52, 0x41, 0x4e, 0x53, 0x41, 0x43, 0x54,
+	0x49, 0x4f, 0x4e, 0x5f, 0x4e, 0x4f, 0x54, 0x5f, 0x46, 0x4f, 0x55, 0x4e, 0x44, 0x10, 0x06, 0x12,
+	0x23, 0x0a, 0x1f, 0x57, 0x49, 0x52, 0x45, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x43, 0x4f,
+	0x44, 0x45, 0x5f, 0x46, 0x52, 0x41, 0x4d, 0x45, 0x5f, 0x54, 0x4f, 0x4f, 0x5f, 0x4c, 0x41, 0x52,
+	0x47, 0x45, 0x10, 0x07, 0x12, 0x29, 0x0a, 0x25, 0x57, 0x49, 0x52, 0x45, 0x5f, 0x45, 0x52, 0x52,
+	0x4f, 0x52, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x53, 0x55, 0x42, 0x53, 0x43, 0x52, 0x49, 0x50,
+	0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x54, 0x4f, 0x4f, 0x5f, 0x53, 0x4c, 0x4f, 0x57, 0x10, 0x08, 0x2a,
+	0x94, 0x01, 0x0a, 0x13, 0x57, 0x69, 0x72, 0x65, 0x45, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64,
+	0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x23, 0x0a, 0x1f, 0x57, 0x49, 0x52, 0x45, 0x5f,
+	0x45, 0x58, 0x50, 0x45, 0x43, 0x54, 0x45, 0x44, 0x5f, 0x56, 0x45, 0x52, 0x53, 0x49, 0x4f, 0x4e,
+	0x5f, 0x4e, 0x4f, 0x5f, 0x53, 0x54, 0x52, 0x45, 0x41, 0x4d, 0x10, 0x00, 0x12, 0x26, 0x0a, 0x19,
+	0x57, 0x49, 0x52, 0x45, 0x5f, 0x45, 0x58, 0x50, 0x45, 0x43, 0x54, 0x45, 0x44, 0x5f, 0x56, 0x45,
+	0x52, 0x53, 0x49, 0x4f, 0x4e, 0x5f, 0x41, 0x4e, 0x59, 0x10, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0x01, 0x12, 0x30, 0x0a, 0x23, 0x57, 0x49, 0x52, 0x45, 0x5f, 0x45, 0x58, 0x50,
+	0x45, 0x43, 0x54, 0x45, 0x44, 0x5f, 0x56, 0x45, 0x52, 0x53, 0x49, 0x4f, 0x4e, 0x5f, 0x53, 0x54,
+	0x52, 0x45, 0x41, 0x4d, 0x5f, 0x45, 0x58, 0x49, 0x53, 0x54, 0x53, 0x10, 0xfe, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0x01, 0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6e, 0x6f, 0x68, 0x6e, 0x73, 0x2f, 0x65, 0x76, 0x65, 0x6e, 0x74,
+	0x61, 0x6c, 0x65, 0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x76, 0x31, 0x2f, 0x65, 0x76, 0x65, 0x6e, 0x74,
+	0x61, 0x6c, 0x65, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_v1_tcp_proto_rawDescOnce sync.Once
+	file_v1_tcp_proto_rawDescData = file_v1_tcp_proto_rawDesc
+)
+
+func file_v1_tcp_proto_rawDescGZIP() []byte {
+	file_v1_tcp_proto_rawDescOnce.Do(func() {
+		file_v1_tcp_proto_rawDescData = protoimpl.X.CompressGZIP(file_v1_tcp_proto_rawDescData)
+	})
+	return file_v1_tcp_proto_rawDescData
+}
+
+var file_v1_tcp_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_v1_tcp_proto_msgTypes = make([]protoimpl.MessageInfo, 37)
+var file_v1_tcp_proto_goTypes = []interface{}{
+	(WireCompression)(0),                  // 0: eventale.WireCompression
+	(WireErrorCode)(0),                    // 1: eventale.WireErrorCode
+	(WireExpectedVersion)(0),              // 2: eventale.WireExpectedVersion
+	(*SemanticVersion)(nil),               // 3: eventale.SemanticVersion
+	(*WireClientHello)(nil),               // 4: eventale.WireClientHello
+	(*WireServerHello)(nil),               // 5: eventale.WireServerHello
+	(*WireError)(nil),                     // 6: eventale.WireError
+	(*WireEventData)(nil),                 // 7: eventale.WireEventData
+	(*WireEventMetadata)(nil),             // 8: eventale.WireEventMetadata
+	(*WireLink)(nil),                      // 9: eventale.WireLink
+	(*WireEvent)(nil),                     // 10: eventale.WireEvent
+	(*WireAppendRequest)(nil),             // 11: eventale.WireAppendRequest
+	(*WireAppendResult)(nil),              // 12: eventale.WireAppendResult
+	(*WireReadStreamRequest)(nil),         // 13: eventale.WireReadStreamRequest
+	(*WireReadStreamResult)(nil),          // 14: eventale.WireReadStreamResult
+	(*WireSubscribeRequest)(nil),          // 15: eventale.WireSubscribeRequest
+	(*WireSubscriptionCredit)(nil),        // 16: eventale.WireSubscriptionCredit
+	(*WireSubscriptionEvent)(nil),         // 17: eventale.WireSubscriptionEvent
+	(*WireSubscriptionEvents)(nil),        // 18: eventale.WireSubscriptionEvents
+	(*WireSnapshot)(nil),                  // 19: eventale.WireSnapshot
+	(*WireWriteSnapshotRequest)(nil),      // 20: eventale.WireWriteSnapshotRequest
+	(*WireReadSnapshotRequest)(nil),       // 21: eventale.WireReadSnapshotRequest
+	(*WireReadSnapshotResult)(nil),        // 22: eventale.WireReadSnapshotResult
+	(*WireCheckpoint)(nil),                // 23: eventale.WireCheckpoint
+	(*WireReadCheckpointRequest)(nil),     // 24: eventale.WireReadCheckpointRequest
+	(*WireCheckpointLeaseRequest)(nil),    // 25: eventale.WireCheckpointLeaseRequest
+	(*WireCheckpointLease)(nil),           // 26: eventale.WireCheckpointLease
+	(*WireStreamMetadata)(nil),            // 27: eventale.WireStreamMetadata
+	(*WireReadStreamMetadataRequest)(nil), // 28: eventale.WireReadStreamMetadataRequest
+	(*WireDeleteStreamRequest)(nil),       // 29: eventale.WireDeleteStreamRequest
+	(*WireStreamDeleted)(nil),             // 30: eventale.WireStreamDeleted
+	(*WireForgetSubjectRequest)(nil),      // 31: eventale.WireForgetSubjectRequest
+	(*WireTransaction)(nil),               // 32: eventale.WireTransaction
+	(*WireTransactionAppendRequest)(nil),  // 33: eventale.WireTransactionAppendRequest
+	(*WireTransactionCommitted)(nil),      // 34: eventale.WireTransactionCommitted
+	(*WireFilter)(nil),                    // 35: eventale.WireFilter
+	(*WireReadAllRequest)(nil),            // 36: eventale.WireReadAllRequest
+	(*WireReadAllResult)(nil),             // 37: eventale.WireReadAllResult
+	nil,                                   // 38: eventale.WireEventMetadata.HeadersEntry
+	nil,                                   // 39: eventale.WireStreamMetadata.CustomEntry
+}
+var file_v1_tcp_proto_depIdxs = []int32{
+	3,  // 0: eventale.WireClientHello.clientVersion:type_name -> eventale.SemanticVersion
+	0,  // 1: eventale.WireClientHello.compressions:type_name -> eventale.WireCompression
+	3,  // 2: eventale.WireServerHello.serverVersion:type_name -> eventale.SemanticVersion
+	0,  // 3: eventale.WireServerHello.compression:type_name -> eventale.WireCompression
+	1,  // 4: eventale.WireError.code:type_name -> eventale.WireErrorCode
+	8,  // 5: eventale.WireEventData.metadata:type_name -> eventale.WireEventMetadata
+	38, // 6: eventale.WireEventMetadata.headers:type_name -> eventale.WireEventMetadata.HeadersEntry
+	9,  // 7: eventale.WireEvent.link:type_name -> eventale.WireLink
+	8,  // 8: eventale.WireEvent.metadata:type_name -> eventale.WireEventMetadata
+	7,  // 9: eventale.WireAppendRequest.events:type_name -> eventale.WireEventData
+	10, // 10: eventale.WireReadStreamResult.events:type_name -> eventale.WireEvent
+	10, // 11: eventale.WireSubscriptionEvent.event:type_name -> eventale.WireEvent
+	10, // 12: eventale.WireSubscriptionEvents.events:type_name -> eventale.WireEvent
+	7,  // 13: eventale.WireSnapshot.state:type_name -> eventale.WireEventData
+	19, // 14: eventale.WireWriteSnapshotRequest.snapshot:type_name -> eventale.WireSnapshot
+	19, // 15: eventale.WireReadSnapshotResult.snapshot:type_name -> eventale.WireSnapshot
+	39, // 16: eventale.WireStreamMetadata.custom:type_name -> eventale.WireStreamMetadata.CustomEntry
+	11, // 17: eventale.WireTransactionAppendRequest.append:type_name -> eventale.WireAppendRequest
+	12, // 18: eventale.WireTransactionCommitted.results:type_name -> eventale.WireAppendResult
+	35, // 19: eventale.WireReadAllRequest.filter:type_name -> eventale.WireFilter
+	10, // 20: eventale.WireReadAllResult.events:type_name -> eventale.WireEvent
+	21, // [21:21] is the sub-list for method output_type
+	21, // [21:21] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
+}
+
+func init() { file_v1_tcp_proto_init() }
+func file_v1_tcp_proto_init() {
+	if File_v1_tcp_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_v1_tcp_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SemanticVersion); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireClientHello); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireServerHello); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireError); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireEventData); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireEventMetadata); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireLink); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireAppendRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireAppendResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireReadStreamRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireReadStreamResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireSubscribeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireSubscriptionCredit); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireSubscriptionEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireSubscriptionEvents); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireSnapshot); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireWriteSnapshotRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireReadSnapshotRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireReadSnapshotResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireCheckpoint); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireReadCheckpointRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireCheckpointLeaseRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireCheckpointLease); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireStreamMetadata); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireReadStreamMetadataRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireDeleteStreamRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireStreamDeleted); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireForgetSubjectRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireTransaction); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireTransactionAppendRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireTransactionCommitted); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireFilter); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireReadAllRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v1_tcp_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WireReadAllResult); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -294,18 +3269,20 @@ func file_v1_tcp_proto_init() {
 			}
 		}
 	}
+	file_v1_tcp_proto_msgTypes[12].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_v1_tcp_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   3,
+			NumEnums:      3,
+			NumMessages:   37,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_v1_tcp_proto_goTypes,
 		DependencyIndexes: file_v1_tcp_proto_depIdxs,
+		EnumInfos:         file_v1_tcp_proto_enumTypes,
 		MessageInfos:      file_v1_tcp_proto_msgTypes,
 	}.Build()
 	File_v1_tcp_proto = out.File
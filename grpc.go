@@ -0,0 +1,249 @@
+package eventale
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	eventalepb "github.com/nohns/eventale/gen/v1"
+	"github.com/nohns/eventale/internal/auth"
+	"github.com/nohns/eventale/internal/store"
+	"github.com/nohns/eventale/internal/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// ListenAndServeGRPC listens on the TCP address addr, and serves the gRPC
+// service on it, see ServeGRPC.
+func (s *Server) ListenAndServeGRPC(addr string) error {
+	s.Logger.Info("Listening for gRPC traffic", slog.String("addr", addr))
+	lnr, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.ServeGRPC(lnr)
+}
+
+// ServeGRPC accepts connections on lnr, and serves the EventStore gRPC service
+// defined in proto/v1 on them alongside Serve, for clients generated from the
+// definition. Calls are authenticated with the bearer tokens of the HTTP
+// gateway, signed for the full method name and request message and given in
+// the authorization metadata. ServeGRPC always returns a non-nil error and closes lnr.
+func (s *Server) ServeGRPC(lnr net.Listener) error {
+	if err := s.listen(lnr); err != nil {
+		return err
+	}
+	defer s.Close()
+
+	gs := grpc.NewServer()
+	eventalepb.RegisterEventStoreServer(gs, &grpcService{s: s})
+	go func() {
+		<-s.done
+		gs.Stop()
+	}()
+	err := gs.Serve(lnr)
+	if s.readState() == serverStatusClosed {
+		return ErrServerClosed
+	}
+	return err
+}
+
+// grpcService implements the EventStore gRPC service on top of the server.
+type grpcService struct {
+	eventalepb.UnimplementedEventStoreServer
+	s *Server
+
+	mu   sync.Mutex
+	subs map[string]*grpcSubscription
+}
+
+// grpcSubscription is a live subscription of a Subscribe call, granted credit
+// by GrantCredit calls of the same principal.
+type grpcSubscription struct {
+	principal string
+	credit    *credit
+}
+
+// authenticate returns the principal of the bearer token of the call with
+// request req.
+func (g *grpcService) authenticate(ctx context.Context, req proto.Message) (string, error) {
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if vs := md.Get("authorization"); len(vs) > 0 {
+			token, _ = strings.CutPrefix(vs[0], "Bearer ")
+		}
+	}
+	tok, err := g.s.parseToken(token)
+	if err != nil {
+		return "", grpcError(err)
+	}
+	// Tokens are signed for the full method name as the path of the call,
+	// and the request message as its body
+	method, _ := grpc.Method(ctx)
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", grpcError(err)
+	}
+	principal, err := g.s.verifyToken(tok, auth.TokenRequest{Method: http.MethodPost, Path: method, Body: body}, false)
+	if err != nil {
+		return "", grpcError(err)
+	}
+	return principal, nil
+}
+
+func (g *grpcService) Append(ctx context.Context, req *eventalepb.WireAppendRequest) (*eventalepb.WireAppendResult, error) {
+	principal, err := g.authenticate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := g.s.append(ctx, principal, req)
+	return res, grpcError(err)
+}
+
+func (g *grpcService) ReadStream(ctx context.Context, req *eventalepb.WireReadStreamRequest) (*eventalepb.WireReadStreamResult, error) {
+	if _, err := g.authenticate(ctx, req); err != nil {
+		return nil, err
+	}
+	res, err := g.s.readStream(ctx, req)
+	return res, grpcError(err)
+}
+
+func (g *grpcService) ReadAll(ctx context.Context, req *eventalepb.WireReadAllRequest) (*eventalepb.WireReadAllResult, error) {
+	if _, err := g.authenticate(ctx, req); err != nil {
+		return nil, err
+	}
+	res, err := g.s.readAll(ctx, req)
+	return res, grpcError(err)
+}
+
+func (g *grpcService) DeleteStream(ctx context.Context, req *eventalepb.WireDeleteStreamRequest) (*eventalepb.WireStreamDeleted, error) {
+	if _, err := g.authenticate(ctx, req); err != nil {
+		return nil, err
+	}
+	res, err := g.s.deleteStream(ctx, req)
+	return res, grpcError(err)
+}
+
+func (g *grpcService) Subscribe(req *eventalepb.WireSubscribeRequest, ss eventalepb.EventStore_SubscribeServer) error {
+	ctx, cancel := context.WithCancel(ss.Context())
+	defer cancel()
+	principal, err := g.authenticate(ctx, req)
+	if err != nil {
+		return err
+	}
+	id, err := uuid.Gen()
+	if err != nil {
+		return grpcError(err)
+	}
+
+	// Register with the broker before catching up, so no events appended in
+	// between are missed, and tell the client the ID once registered
+	stream := req.Stream
+	if stream == AllStreams {
+		stream = ""
+	}
+	sub := g.s.broker.Subscribe(stream, g.s.subscriptionBuffer())
+	defer g.s.broker.Unsubscribe(sub)
+	gsub := &grpcSubscription{principal: principal, credit: newCredit(req.Credit)}
+	g.mu.Lock()
+	if g.subs == nil {
+		g.subs = make(map[string]*grpcSubscription)
+	}
+	g.subs[id.String()] = gsub
+	g.mu.Unlock()
+	defer func() {
+		g.mu.Lock()
+		delete(g.subs, id.String())
+		g.mu.Unlock()
+	}()
+	if err := ss.SendHeader(metadata.Pairs("subscription-id", id.String())); err != nil {
+		return err
+	}
+
+	err = g.s.runSubscription(ctx, &serverSubscription{
+		stream: stream,
+		from:   req.From,
+		batch:  min(max(int(req.MaxBatch), 1), _maxSubscriptionBatch),
+		credit: gsub.credit,
+		cancel: cancel,
+		sink:   &grpcSink{ss: ss},
+	}, sub)
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	return grpcError(err)
+}
+
+func (g *grpcService) GrantCredit(ctx context.Context, req *eventalepb.WireGrantCreditRequest) (*eventalepb.WireCreditGranted, error) {
+	principal, err := g.authenticate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	gsub, ok := g.subs[req.SubscriptionId]
+	g.mu.Unlock()
+	// Subscriptions of other principals are reported as not found, so their
+	// IDs are not revealed
+	if !ok || gsub.principal != principal {
+		return nil, status.Errorf(codes.NotFound, "subscription %s not found", req.SubscriptionId)
+	}
+	gsub.credit.grant(int(req.Credit))
+	return &eventalepb.WireCreditGranted{}, nil
+}
+
+// grpcSink streams the events of a subscription to a gRPC call.
+type grpcSink struct {
+	ss eventalepb.EventStore_SubscribeServer
+}
+
+func (gs *grpcSink) push(ctx context.Context, events []store.Event) error {
+	pb := &eventalepb.WireSubscriptionEvents{Events: make([]*eventalepb.WireEvent, len(events))}
+	for i, ev := range events {
+		pb.Events[i] = eventToWire(ev)
+	}
+	return gs.ss.Send(&eventalepb.WireSubscriptionMessage{
+		Message: &eventalepb.WireSubscriptionMessage_Events{Events: pb},
+	})
+}
+
+func (gs *grpcSink) deleted(ctx context.Context, del *eventalepb.WireStreamDeleted) error {
+	return gs.ss.Send(&eventalepb.WireSubscriptionMessage{
+		Message: &eventalepb.WireSubscriptionMessage_Deleted{Deleted: del},
+	})
+}
+
+// grpcError turns err into a gRPC status with the code closest to its
+// WireErrorCode, and the WireError in its details.
+func grpcError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	pb := errorToWire(err)
+	code := codes.Unknown
+	switch pb.Code {
+	case eventalepb.WireErrorCode_WIRE_ERROR_CODE_BAD_REQUEST:
+		code = codes.InvalidArgument
+	case eventalepb.WireErrorCode_WIRE_ERROR_CODE_UNAUTHORIZED:
+		code = codes.Unauthenticated
+	case eventalepb.WireErrorCode_WIRE_ERROR_CODE_WRONG_EXPECTED_VERSION:
+		code = codes.Aborted
+	case eventalepb.WireErrorCode_WIRE_ERROR_CODE_STREAM_DELETED, eventalepb.WireErrorCode_WIRE_ERROR_CODE_LEASE_LOST:
+		code = codes.FailedPrecondition
+	case eventalepb.WireErrorCode_WIRE_ERROR_CODE_SUBSCRIPTION_TOO_SLOW:
+		code = codes.ResourceExhausted
+	}
+	st, derr := status.New(code, pb.Message).WithDetails(pb)
+	if derr != nil {
+		return status.Error(code, pb.Message)
+	}
+	return st.Err()
+}
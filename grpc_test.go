@@ -0,0 +1,203 @@
+package eventale_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/nohns/eventale"
+	eventalepb "github.com/nohns/eventale/gen/v1"
+	"github.com/nohns/eventale/internal/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// grpcClient serves srv over gRPC, returning a client connected to it.
+func grpcClient(t *testing.T, srv *eventale.Server) eventalepb.EventStoreClient {
+	t.Helper()
+	lnr, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go srv.ServeGRPC(lnr)
+	cc, err := grpc.Dial(lnr.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { cc.Close() })
+	return eventalepb.NewEventStoreClient(cc)
+}
+
+// grpcSubscribe starts a subscription, waiting for the headers of the server
+// telling it is live. The ID of the subscription is returned along with it.
+func grpcSubscribe(t *testing.T, ctx context.Context, gc eventalepb.EventStoreClient, req *eventalepb.WireSubscribeRequest) (eventalepb.EventStore_SubscribeClient, string) {
+	t.Helper()
+	sub, err := gc.Subscribe(ctx, req)
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	md, err := sub.Header()
+	if err != nil {
+		t.Fatalf("subscription headers: %v", err)
+	}
+	ids := md.Get("subscription-id")
+	if len(ids) != 1 {
+		t.Fatalf("expected a subscription ID, got headers %v", md)
+	}
+	return sub, ids[0]
+}
+
+func TestGRPC(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	srv, addr := startServer(t)
+	gc := grpcClient(t, srv)
+
+	sub, _ := grpcSubscribe(t, ctx, gc, &eventalepb.WireSubscribeRequest{Stream: "order-1", MaxBatch: 10})
+	res, err := gc.Append(ctx, &eventalepb.WireAppendRequest{
+		Stream:          "order-1",
+		ExpectedVersion: int64(eventalepb.WireExpectedVersion_WIRE_EXPECTED_VERSION_NO_STREAM),
+		Events:          []*eventalepb.WireEventData{{Type: "OrderPlaced"}, {Type: "ItemAdded"}},
+	})
+	if err != nil || res.Version != 2 {
+		t.Fatalf("expected version 2, got %v (err %v)", res, err)
+	}
+	_, err = gc.Append(ctx, &eventalepb.WireAppendRequest{Stream: "order-1", Events: []*eventalepb.WireEventData{{Type: "OrderPlaced"}}})
+	st, _ := status.FromError(err)
+	if st.Code() != codes.Aborted || len(st.Details()) != 1 || st.Details()[0].(*eventalepb.WireError).ActualVersion != 2 {
+		t.Fatalf("expected wrong expected version at 2, got %v", err)
+	}
+
+	// Events appended over gRPC are pushed to subscriptions, and read by TCP
+	// clients
+	var versions []uint64
+	for len(versions) < 2 {
+		msg, err := sub.Recv()
+		if err != nil {
+			t.Fatalf("recv: %v", err)
+		}
+		for _, ev := range msg.GetEvents().GetEvents() {
+			versions = append(versions, ev.Version)
+		}
+	}
+	if versions[0] != 1 || versions[1] != 2 {
+		t.Fatalf("expected versions 1 and 2, got %v", versions)
+	}
+	events, err := dial(t, addr).ReadStream(ctx, "order-1")
+	if err != nil || len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d (err %v)", len(events), err)
+	}
+
+	read, err := gc.ReadStream(ctx, &eventalepb.WireReadStreamRequest{Stream: "order-1", FromVersion: 1})
+	if err != nil || len(read.Events) != 1 || read.Events[0].Type != "ItemAdded" {
+		t.Fatalf("expected the added item, got %v (err %v)", read, err)
+	}
+	all, err := gc.ReadAll(ctx, &eventalepb.WireReadAllRequest{Filter: &eventalepb.WireFilter{EventTypePrefixes: []string{"Order"}}})
+	if err != nil || len(all.Events) != 1 || !all.End {
+		t.Fatalf("expected the placed order, got %v (err %v)", all, err)
+	}
+
+	deleted, err := gc.DeleteStream(ctx, &eventalepb.WireDeleteStreamRequest{Stream: "order-1", ExpectedVersion: 2})
+	if err != nil || deleted.Version != 2 {
+		t.Fatalf("expected deleted at version 2, got %v (err %v)", deleted, err)
+	}
+	msg, err := sub.Recv()
+	if err != nil || msg.GetDeleted().GetStream() != "order-1" {
+		t.Fatalf("expected deletion, got %v (err %v)", msg, err)
+	}
+}
+
+func TestGRPCAuth(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	srv, _ := startServer(t)
+	gc := grpcClient(t, srv)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	srv.AuthorizeKey(&key.PublicKey)
+
+	req := &eventalepb.WireAppendRequest{Stream: "a-1", ExpectedVersion: -1, Events: []*eventalepb.WireEventData{{Type: "A"}}}
+	if _, err := gc.Append(ctx, req); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected unauthenticated without token, got %v", err)
+	}
+	sub, err := gc.Subscribe(ctx, &eventalepb.WireSubscribeRequest{Stream: "a-1"})
+	if err == nil {
+		_, err = sub.Recv()
+	}
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected unauthenticated subscription without token, got %v", err)
+	}
+
+	// Tokens are signed for the full method name and request message of a
+	// call
+	authed := func(method string, msg proto.Message) context.Context {
+		t.Helper()
+		body, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		token, err := auth.SignToken(key, time.Now(), auth.TokenRequest{Method: "POST", Path: method, Body: body})
+		if err != nil {
+			t.Fatalf("sign token: %v", err)
+		}
+		return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	}
+	if _, err := gc.Append(authed(eventalepb.EventStore_ReadStream_FullMethodName, req), req); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected unauthenticated with token for another method, got %v", err)
+	}
+	other := &eventalepb.WireAppendRequest{Stream: "a-2", ExpectedVersion: -1, Events: []*eventalepb.WireEventData{{Type: "A"}}}
+	if _, err := gc.Append(authed(eventalepb.EventStore_Append_FullMethodName, other), req); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected unauthenticated with token for another request, got %v", err)
+	}
+	if _, err := gc.Append(authed(eventalepb.EventStore_Append_FullMethodName, req), req); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	readReq := &eventalepb.WireReadStreamRequest{Stream: "a-1"}
+	read, err := gc.ReadStream(authed(eventalepb.EventStore_ReadStream_FullMethodName, readReq), readReq)
+	if err != nil || len(read.Events) != 1 || read.Events[0].Metadata.GetPrincipal() != auth.KeyPrincipal(auth.Fingerprint(&key.PublicKey)) {
+		t.Fatalf("expected event appended by the key principal, got %v (err %v)", read, err)
+	}
+}
+
+func TestGRPCSubscriptionCredit(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	srv, _ := startServer(t)
+	gc := grpcClient(t, srv)
+
+	sub, id := grpcSubscribe(t, ctx, gc, &eventalepb.WireSubscribeRequest{Stream: "clock-1", MaxBatch: 10, Credit: 1})
+	_, err := gc.Append(ctx, &eventalepb.WireAppendRequest{
+		Stream:          "clock-1",
+		ExpectedVersion: int64(eventalepb.WireExpectedVersion_WIRE_EXPECTED_VERSION_NO_STREAM),
+		Events:          []*eventalepb.WireEventData{{Type: "Ticked"}, {Type: "Ticked"}},
+	})
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	// Only as many events are pushed as credit was granted for
+	for version := uint64(1); version <= 2; version++ {
+		msg, err := sub.Recv()
+		if err != nil {
+			t.Fatalf("recv: %v", err)
+		}
+		if events := msg.GetEvents().GetEvents(); len(events) != 1 || events[0].Version != version {
+			t.Fatalf("expected only version %d, got %v", version, events)
+		}
+		if _, err := gc.GrantCredit(ctx, &eventalepb.WireGrantCreditRequest{SubscriptionId: id, Credit: 1}); err != nil {
+			t.Fatalf("grant credit: %v", err)
+		}
+	}
+	if _, err := gc.GrantCredit(ctx, &eventalepb.WireGrantCreditRequest{SubscriptionId: "unknown", Credit: 1}); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected unknown subscription not found, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+// Package aesgcm seals data with AES-GCM. Each ciphertext is prefixed with the
+// random nonce it was sealed with, so it can be opened with the key alone.
+package aesgcm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// ErrTooShort is returned when opening data shorter than a nonce.
+var ErrTooShort = errors.New("sealed data too short")
+
+// New returns an AES-GCM cipher for key, which must be 16, 24 or 32 bytes.
+func New(key []byte) (cipher.AEAD, error) {
+	b, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("aes cipher: %v", err)
+	}
+	aead, err := cipher.NewGCM(b)
+	if err != nil {
+		return nil, fmt.Errorf("aes gcm: %v", err)
+	}
+	return aead, nil
+}
+
+// Seal encrypts plain with a fresh random nonce, which is prepended to the
+// returned ciphertext.
+func Seal(aead cipher.AEAD, plain []byte) ([]byte, error) {
+	return AppendSeal(nil, aead, plain)
+}
+
+// AppendSeal is like Seal, but appends the nonce and ciphertext to dst, so a
+// buffer can be reused between calls.
+func AppendSeal(dst []byte, aead cipher.AEAD, plain []byte) ([]byte, error) {
+	n := len(dst)
+	dst = slices.Grow(dst, aead.NonceSize()+len(plain)+aead.Overhead())
+	dst = dst[:n+aead.NonceSize()]
+	nonce := dst[n:]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(dst, nonce, plain, nil), nil
+}
+
+// Open decrypts data sealed with Seal.
+func Open(aead cipher.AEAD, sealed []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrTooShort
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
+
+// OpenInPlace is like Open, but decrypts into the memory of sealed, which is
+// overwritten.
+func OpenInPlace(aead cipher.AEAD, sealed []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrTooShort
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(ciphertext[:0], nonce, ciphertext, nil)
+}
@@ -1 +1,167 @@
 package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Fingerprint identifies a client public key. Clients send it in their hello,
+// so the server can look up the key to encrypt the session key with.
+func Fingerprint(pub *rsa.PublicKey) [32]byte {
+	return sha256.Sum256(x509.MarshalPKCS1PublicKey(pub))
+}
+
+// KeyPrincipal returns the principal of clients authenticated with the key
+// identified by fingerprint.
+func KeyPrincipal(fingerprint [32]byte) string {
+	return "key:" + hex.EncodeToString(fingerprint[:8])
+}
+
+// ErrInvalidToken is returned when verifying a token not signed by an
+// authorized key, signed for another request, signed too long ago, or used
+// before.
+var ErrInvalidToken = errors.New("invalid token")
+
+const (
+	_nonceLen        = 16
+	_tokenPayloadLen = sha256.Size + 8 + _nonceLen
+)
+
+// TokenRequest is the request a token is signed for. A token only
+// authenticates the request it is signed for.
+type TokenRequest struct {
+	// Method is the HTTP method of the request, or POST for gRPC calls.
+	Method string
+	// Path is the escaped path of the request URL, without query, or the
+	// full method name of gRPC calls, e.g. /eventale.EventStore/Append.
+	Path string
+	// Body is the body of the request, empty for gRPC calls.
+	Body []byte
+}
+
+// hash returns the SHA-256 hash signed for req by a token with payload.
+func (req TokenRequest) hash(payload []byte) []byte {
+	body := sha256.Sum256(req.Body)
+	h := sha256.New()
+	h.Write(payload)
+	h.Write([]byte(req.Method + "\n" + req.Path + "\n"))
+	h.Write(body[:])
+	return h.Sum(nil)
+}
+
+// SignToken returns a bearer token authenticating req as the holder of key,
+// the way the key authenticates TCP clients. The token is the unpadded
+// base64url encoding of the key fingerprint, the Unix time in seconds it was
+// signed at as 8 bytes big endian, a random 16 byte nonce, and an RSA PKCS #1
+// v1.5 signature of the SHA-256 hash of the preceding bytes, the method and
+// path of req each followed by a newline, and the SHA-256 hash of its body.
+func SignToken(key *rsa.PrivateKey, at time.Time, req TokenRequest) (string, error) {
+	fp := Fingerprint(&key.PublicKey)
+	payload := binary.BigEndian.AppendUint64(fp[:], uint64(at.Unix()))
+	payload = append(payload, make([]byte, _nonceLen)...)
+	if _, err := rand.Read(payload[len(payload)-_nonceLen:]); err != nil {
+		return "", err
+	}
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, req.hash(payload))
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(append(payload, sig...)), nil
+}
+
+// TokenVerifier verifies tokens signed with SignToken. It remembers the
+// nonces of the tokens it accepted until they expire, so a token can not be
+// replayed unless verified for reuse. It is safe for concurrent use.
+type TokenVerifier struct {
+	// Lookup returns the authorized key with fingerprint.
+	Lookup func(fingerprint [32]byte) (*rsa.PublicKey, bool)
+	// MaxAge is how long before or after signing a token is accepted.
+	MaxAge time.Duration
+
+	mu     sync.Mutex
+	nonces map[[_nonceLen]byte]time.Time
+	swept  time.Time
+}
+
+// Token is a token decoded by TokenVerifier.Parse, whose signature is not
+// verified yet.
+type Token struct {
+	fingerprint [32]byte
+	pub         *rsa.PublicKey
+	payload     []byte
+	sig         []byte
+	signed      time.Time
+}
+
+// Parse decodes token, and checks that it was signed with an authorized key
+// no more than MaxAge before or after now. The signature is checked by
+// Verify, so servers can reject tokens before reading the request body it
+// covers.
+func (v *TokenVerifier) Parse(token string, now time.Time) (*Token, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(b) <= _tokenPayloadLen {
+		return nil, ErrInvalidToken
+	}
+	tok := &Token{
+		fingerprint: [32]byte(b[:sha256.Size]),
+		payload:     b[:_tokenPayloadLen],
+		sig:         b[_tokenPayloadLen:],
+		signed:      time.Unix(int64(binary.BigEndian.Uint64(b[sha256.Size:])), 0),
+	}
+	if d := now.Sub(tok.signed); d > v.MaxAge || d < -v.MaxAge {
+		return nil, fmt.Errorf("signed at %v: %w", tok.signed, ErrInvalidToken)
+	}
+	pub, ok := v.Lookup(tok.fingerprint)
+	if !ok {
+		return nil, fmt.Errorf("unknown key: %w", ErrInvalidToken)
+	}
+	tok.pub = pub
+	return tok, nil
+}
+
+// Verify verifies that tok was signed for req and, unless reuse is set, was
+// not used before. Reuse is meant for requests safe to repeat which clients
+// can only retry with the same token, such as server-sent event streams
+// reconnected by browsers. The principal of the key is returned.
+func (v *TokenVerifier) Verify(tok *Token, req TokenRequest, reuse bool, now time.Time) (string, error) {
+	if err := rsa.VerifyPKCS1v15(tok.pub, crypto.SHA256, req.hash(tok.payload), tok.sig); err != nil {
+		return "", fmt.Errorf("signature: %v: %w", err, ErrInvalidToken)
+	}
+	if !reuse && !v.use([_nonceLen]byte(tok.payload[sha256.Size+8:]), tok.signed.Add(v.MaxAge), now) {
+		return "", fmt.Errorf("used before: %w", ErrInvalidToken)
+	}
+	return KeyPrincipal(tok.fingerprint), nil
+}
+
+// use records nonce as used until expires, reporting whether it was unused.
+// Expired nonces are forgotten, as tokens carrying them are rejected anyway.
+func (v *TokenVerifier) use(nonce [_nonceLen]byte, expires, now time.Time) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.nonces == nil {
+		v.nonces = make(map[[_nonceLen]byte]time.Time)
+	}
+	if now.Sub(v.swept) > v.MaxAge {
+		for n, exp := range v.nonces {
+			if exp.Before(now) {
+				delete(v.nonces, n)
+			}
+		}
+		v.swept = now
+	}
+	if _, ok := v.nonces[nonce]; ok {
+		return false
+	}
+	v.nonces[nonce] = expires
+	return true
+}
@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"errors"
+	"strconv"
+)
+
+// ErrPeerCredUnsupported is returned when reading peer credentials on
+// platforms without SO_PEERCRED.
+var ErrPeerCredUnsupported = errors.New("peer credentials not supported")
+
+// PeerCred holds the credentials of the process on the other end of a Unix
+// socket, as of when it connected.
+type PeerCred struct {
+	PID int32
+	UID uint32
+	GID uint32
+}
+
+// UIDPrincipal returns the principal of clients authenticated by the user ID
+// of their process.
+func UIDPrincipal(uid uint32) string {
+	return "uid:" + strconv.FormatUint(uint64(uid), 10)
+}
@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"net"
+	"syscall"
+)
+
+// PeerCredentials reads the credentials of the peer of conn with SO_PEERCRED.
+func PeerCredentials(conn *net.UnixConn) (PeerCred, error) {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return PeerCred{}, err
+	}
+	var (
+		ucred *syscall.Ucred
+		uerr  error
+	)
+	if err := raw.Control(func(fd uintptr) {
+		ucred, uerr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	}); err != nil {
+		return PeerCred{}, err
+	}
+	if uerr != nil {
+		return PeerCred{}, uerr
+	}
+	return PeerCred{PID: ucred.Pid, UID: ucred.Uid, GID: ucred.Gid}, nil
+}
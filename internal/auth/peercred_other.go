@@ -0,0 +1,11 @@
+//go:build !linux
+
+package auth
+
+import "net"
+
+// PeerCredentials reads the credentials of the peer of conn with SO_PEERCRED,
+// which is only supported on Linux.
+func PeerCredentials(conn *net.UnixConn) (PeerCred, error) {
+	return PeerCred{}, ErrPeerCredUnsupported
+}
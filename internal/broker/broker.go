@@ -0,0 +1,226 @@
+// Package broker fans out newly appended events to live subscribers.
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/nohns/eventale/internal/store"
+)
+
+var (
+	// ErrUnsubscribed is returned from Subscriber.Next after the subscriber
+	// has been removed from the broker.
+	ErrUnsubscribed = errors.New("unsubscribed")
+	// ErrOverflow is returned from Subscriber.Next after messages were
+	// dropped, as the subscriber fell too far behind.
+	ErrOverflow = errors.New("subscriber overflowed")
+)
+
+// OverflowError is returned from Subscriber.Next after the queue of the
+// subscriber exceeded its limit, and was dropped.
+type OverflowError struct {
+	// First is the first event dropped, or the zero Event if only deletions
+	// were dropped. Later events published after the overflow are queued
+	// again.
+	First store.Event
+	// Deletions is the number of deletions dropped.
+	Deletions int
+}
+
+func (e *OverflowError) Error() string {
+	if e.First.Position == 0 {
+		return fmt.Sprintf("%v dropping %d deletions", ErrOverflow, e.Deletions)
+	}
+	return fmt.Sprintf("%v at position %d", ErrOverflow, e.First.Position)
+}
+
+func (e *OverflowError) Unwrap() error {
+	return ErrOverflow
+}
+
+type Broker struct {
+	mu   sync.RWMutex
+	subs map[*Subscriber]struct{}
+}
+
+func New() *Broker {
+	return &Broker{
+		subs: make(map[*Subscriber]struct{}),
+	}
+}
+
+// Subscribe registers a subscriber receiving events of stream. An empty stream
+// receives events of all streams. Subscribers of a derived stream receive the
+// events linked to it. When more than limit messages are queued, the queue is
+// dropped and Next fails with an *OverflowError. A limit of 0 is unlimited.
+func (b *Broker) Subscribe(stream string, limit int) *Subscriber {
+	sub := &Subscriber{
+		stream: stream,
+		limit:  limit,
+		notify: make(chan struct{}, 1),
+	}
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+	return sub
+}
+
+// Unsubscribe removes sub from the broker, waking up any pending call to Next.
+func (b *Broker) Unsubscribe(sub *Subscriber) {
+	b.mu.Lock()
+	delete(b.subs, sub)
+	b.mu.Unlock()
+
+	sub.mu.Lock()
+	sub.closed = true
+	sub.mu.Unlock()
+	sub.wake()
+}
+
+// Publish queues events for all subscribers interested in them. Events must be
+// published in the order they were persisted.
+func (b *Broker) Publish(events []store.Event) {
+	msgs := make([]Message, len(events))
+	for i, ev := range events {
+		msgs[i] = Message{Event: ev}
+	}
+	b.publish(msgs)
+}
+
+// PublishDeletion notifies the subscribers of the deleted stream, and of all
+// streams, about del.
+func (b *Broker) PublishDeletion(del Deletion) {
+	b.publish([]Message{{Deletion: &del}})
+}
+
+func (b *Broker) publish(msgs []Message) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.subs {
+		sub.push(msgs)
+	}
+}
+
+// Message is either a published event, or the deletion of a stream.
+type Message struct {
+	Event    store.Event
+	Deletion *Deletion
+}
+
+// Deletion describes a deleted stream.
+type Deletion struct {
+	Stream string
+	// Version the stream was deleted at.
+	Version uint64
+	// Hard is set when the stream was deleted permanently.
+	Hard bool
+}
+
+// Subscriber queues published events until they are consumed with Next.
+type Subscriber struct {
+	stream string
+	limit  int
+	notify chan struct{}
+
+	mu       sync.Mutex
+	queue    []Message
+	closed   bool
+	overflow *OverflowError
+}
+
+func (s *Subscriber) push(msgs []Message) {
+	s.mu.Lock()
+	pushed := false
+	for _, msg := range msgs {
+		if !s.matches(msg) {
+			continue
+		}
+		s.queue = append(s.queue, msg)
+		pushed = true
+	}
+	if s.limit > 0 && len(s.queue) > s.limit {
+		s.drop()
+	}
+	s.mu.Unlock()
+	if pushed {
+		s.wake()
+	}
+}
+
+// drop empties the queue, remembering the first event dropped and counting
+// the deletions dropped. mu must be held.
+func (s *Subscriber) drop() {
+	if s.overflow == nil {
+		s.overflow = new(OverflowError)
+	}
+	for _, msg := range s.queue {
+		switch {
+		case msg.Deletion != nil:
+			s.overflow.Deletions++
+		case s.overflow.First.Position == 0:
+			s.overflow.First = msg.Event
+		}
+	}
+	s.queue = nil
+}
+
+func (s *Subscriber) matches(msg Message) bool {
+	if msg.Deletion != nil {
+		return s.stream == "" || msg.Deletion.Stream == s.stream
+	}
+	ev := msg.Event
+	if ev.Link != nil {
+		return ev.Link.Stream == s.stream
+	}
+	return s.stream == "" || ev.Stream == s.stream
+}
+
+func (s *Subscriber) wake() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Next returns the next queued message, blocking until one is published, the
+// subscriber is unsubscribed or ctx is done.
+func (s *Subscriber) Next(ctx context.Context) (Message, error) {
+	msgs, err := s.NextBatch(ctx, 1)
+	if err != nil {
+		return Message{}, err
+	}
+	return msgs[0], nil
+}
+
+// NextBatch is like Next, but returns up to max queued messages at once.
+func (s *Subscriber) NextBatch(ctx context.Context, max int) ([]Message, error) {
+	for {
+		s.mu.Lock()
+		if err := s.overflow; err != nil {
+			s.overflow = nil
+			s.mu.Unlock()
+			return nil, err
+		}
+		if n := min(len(s.queue), max); n > 0 {
+			msgs := make([]Message, n)
+			copy(msgs, s.queue)
+			s.queue = s.queue[n:]
+			s.mu.Unlock()
+			return msgs, nil
+		}
+		closed := s.closed
+		s.mu.Unlock()
+		if closed {
+			return nil, ErrUnsubscribed
+		}
+
+		select {
+		case <-s.notify:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
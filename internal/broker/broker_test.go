@@ -0,0 +1,34 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nohns/eventale/internal/store"
+)
+
+func TestOverflow(t *testing.T) {
+	ctx := context.Background()
+	b := New()
+	sub := b.Subscribe("", 2)
+	defer b.Unsubscribe(sub)
+
+	b.Publish([]store.Event{{Stream: "a", Version: 1, Position: 1}})
+	b.PublishDeletion(Deletion{Stream: "a", Version: 1})
+	b.Publish([]store.Event{{Stream: "b", Version: 1, Position: 2}})
+	_, err := sub.Next(ctx)
+	var overflow *OverflowError
+	if !errors.As(err, &overflow) || overflow.First.Position != 1 || overflow.Deletions != 1 {
+		t.Fatalf("expected overflow at position 1 with 1 deletion, got %v", err)
+	}
+
+	// Deletions alone overflow the queue too
+	for _, stream := range []string{"b", "c", "d"} {
+		b.PublishDeletion(Deletion{Stream: stream, Version: 1})
+	}
+	_, err = sub.Next(ctx)
+	if !errors.As(err, &overflow) || overflow.First.Position != 0 || overflow.Deletions != 3 {
+		t.Fatalf("expected overflow with 3 deletions, got %v", err)
+	}
+}
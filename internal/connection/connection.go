@@ -1,13 +1,18 @@
 package connection
 
 import (
+	"bufio"
 	"context"
-	"crypto/aes"
+	"crypto/cipher"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/nohns/eventale/internal/aesgcm"
 	"github.com/nohns/eventale/internal/frame"
 )
 
@@ -17,33 +22,288 @@ type FrameHandler interface {
 	Handle(conn *Conn, frm *frame.Frame) error
 }
 
+const (
+	// DefaultQueueSize is the number of frames queued for writing before
+	// Send blocks, unless configured otherwise.
+	DefaultQueueSize = 64
+	// DefaultFlushSize is the size of the write buffer, unless configured
+	// otherwise.
+	DefaultFlushSize = 32 << 10
+)
+
+// Conn sends and receives frames over a network connection. Frames are
+// written by a single writer goroutine from a bounded queue, so concurrently
+// sent frames never interleave, and read by a single reader goroutine. Both
+// are started on first use, and stop when the connection is closed.
 type Conn struct {
 	ID      int
 	NetConn net.Conn
 	Logger  *slog.Logger
+	// MaxFrameSize is the maximum payload size of received frames, see
+	// frame.FrameDecoder.SetMaxFrameSize. Defaults to
+	// frame.DefaultMaxFrameSize.
+	MaxFrameSize int
+	// MaxPayloadSize is the maximum size of payloads reassembled from
+	// chunks, see frame.FrameDecoder.SetMaxPayloadSize. Defaults to
+	// frame.DefaultMaxPayloadSize.
+	MaxPayloadSize int
+	// QueueSize is the number of frames queued for writing before Send
+	// blocks. Defaults to DefaultQueueSize.
+	QueueSize int
+	// FlushInterval is how long written frames are buffered waiting for more
+	// frames to coalesce with. When zero, frames are flushed as soon as no
+	// more are queued, and when negative after every frame.
+	FlushInterval time.Duration
+	// FlushSize is the size of the write buffer in bytes, which is flushed
+	// when full. Defaults to DefaultFlushSize.
+	FlushSize int
+
+	start     sync.Once
+	closeInit sync.Once
+	closed    chan struct{}
+	queue     chan outbound
+	want      chan struct{}
+	in        chan inbound
+	// requested is set while a frame requested by Recv has not been
+	// received, so a Recv given up on does not make the reader read ahead.
+	requested atomic.Bool
+
+	mu   sync.Mutex
+	dec  *frame.FrameDecoder
+	werr error
 
-	enckey []byte
-	mu     sync.RWMutex
-	dec    *frame.FrameDecoder
-	enc    *frame.FrameEncoder
+	// Owned by the writer goroutine
+	bw  *bufio.Writer
+	enc *frame.FrameEncoder
+	// buffered counts the frames written since the last flush, unflushed
+	// holds the senders waiting for them to be flushed.
+	buffered     int
+	unflushed    []chan error
+	deadline     time.Time
+	flushTimer   *time.Timer
+	cipher       *aesCipher
+	compression  frame.Compression
+	threshold    int
+	peerMaxFrame int
 }
 
+// outbound is a frame queued for writing, or an operation to run on the
+// writer goroutine when apply is set.
+type outbound struct {
+	ctx   context.Context
+	frm   *frame.Frame
+	apply func() error
+	errc  chan error
+}
+
+// reply reports the result of writing the frame to its sender, if waiting.
+func (out outbound) reply(err error) {
+	if out.errc != nil {
+		out.errc <- err
+	}
+}
+
+type inbound struct {
+	frm *frame.Frame
+	err error
+}
+
+func (tc *Conn) init() {
+	tc.start.Do(func() {
+		size := tc.QueueSize
+		if size <= 0 {
+			size = DefaultQueueSize
+		}
+		tc.done()
+		tc.queue = make(chan outbound, size)
+		tc.want = make(chan struct{}, 1)
+		tc.in = make(chan inbound, 1)
+		go tc.writeLoop()
+		go tc.readLoop()
+	})
+}
+
+// Send queues frm for writing, and waits for it to be flushed to the
+// connection. Send blocks while the queue is full. Frames whose ctx is done
+// before they are written are dropped. Once writing, the deadline of ctx is
+// used as write deadline, and a frame timing out midway closes the
+// connection, as the peer can not read past a partial frame.
 func (tc *Conn) Send(ctx context.Context, frm *frame.Frame) error {
-	// Run in goroutine, so we can return on timeout, or encode result
-	errc := make(chan error)
-	go func() {
-		defer close(errc)
-		errc <- tc.encoder().Encode(frm)
-	}()
+	return tc.enqueue(ctx, outbound{ctx: ctx, frm: frm, errc: make(chan error, 1)})
+}
+
+// Queue is like Send, but returns once frm is queued, without waiting for it
+// to be written, so a single sender can pipeline frames. The frame is still
+// dropped if ctx is done before it is written. A failed write closes the
+// connection.
+func (tc *Conn) Queue(ctx context.Context, frm *frame.Frame) error {
+	return tc.enqueue(ctx, outbound{ctx: ctx, frm: frm})
+}
+
+// do runs fn on the writer goroutine, after the frames queued before it are
+// written.
+func (tc *Conn) do(fn func() error) error {
+	return tc.enqueue(context.Background(), outbound{apply: fn, errc: make(chan error, 1)})
+}
 
+func (tc *Conn) enqueue(ctx context.Context, out outbound) error {
+	tc.init()
 	select {
-	case err := <-errc:
+	case tc.queue <- out:
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	case <-tc.closed:
+		return tc.closeErr()
+	}
+	if out.errc == nil {
+		return nil
+	}
+	select {
+	case err := <-out.errc:
 		return err
 	case <-ctx.Done():
-		return ctx.Err()
+		return context.Cause(ctx)
+	case <-tc.closed:
+		return tc.closeErr()
+	}
+}
+
+// writeLoop writes queued frames until the connection is closed, or a write
+// fails. Frames are written to a buffer, which is flushed once no more frames
+// are queued, the flush interval passes or it fills up, so frames queued
+// together are sent with a single write.
+func (tc *Conn) writeLoop() {
+	size := tc.FlushSize
+	if size <= 0 {
+		size = DefaultFlushSize
+	}
+	tc.bw = bufio.NewWriterSize(tc.NetConn, size)
+	tc.enc, _ = tc.newEncoder()
+	tc.flushTimer = time.NewTimer(0)
+	tc.stopFlushTimer()
+	defer tc.flushTimer.Stop()
+
+	for {
+		var out outbound
+		switch {
+		case tc.buffered == 0:
+			select {
+			case out = <-tc.queue:
+			case <-tc.closed:
+				return
+			}
+		case tc.FlushInterval > 0:
+			select {
+			case out = <-tc.queue:
+			case <-tc.flushTimer.C:
+				if !tc.flush() {
+					return
+				}
+				continue
+			case <-tc.closed:
+				return
+			}
+		default:
+			select {
+			case out = <-tc.queue:
+			default:
+				if !tc.flush() {
+					return
+				}
+				continue
+			}
+		}
+
+		if out.apply != nil {
+			out.errc <- out.apply()
+			continue
+		}
+		if err := context.Cause(out.ctx); err != nil {
+			out.reply(err)
+			continue
+		}
+		if err := tc.write(out.ctx, out.frm); err != nil {
+			out.reply(err)
+			tc.fail(err)
+			return
+		}
+		if tc.buffered == 0 && tc.FlushInterval > 0 {
+			tc.flushTimer.Reset(tc.FlushInterval)
+		}
+		tc.buffered++
+		if out.errc != nil {
+			tc.unflushed = append(tc.unflushed, out.errc)
+		}
+		if tc.FlushInterval < 0 && !tc.flush() {
+			return
+		}
+	}
+}
+
+// write encodes frm into the write buffer. The write deadline is the earliest
+// deadline of the frames not yet flushed, as they are written together.
+func (tc *Conn) write(ctx context.Context, frm *frame.Frame) error {
+	if deadline, ok := ctx.Deadline(); ok && (tc.deadline.IsZero() || deadline.Before(tc.deadline)) {
+		tc.deadline = deadline
+		if err := tc.NetConn.SetWriteDeadline(deadline); err != nil {
+			return err
+		}
+	}
+	return tc.enc.Encode(frm)
+}
+
+// flush writes the buffered frames, and reports the result to their senders.
+// It returns false if the connection failed.
+func (tc *Conn) flush() bool {
+	err := tc.bw.Flush()
+	for _, errc := range tc.unflushed {
+		errc <- err
+	}
+	clear(tc.unflushed)
+	tc.unflushed = tc.unflushed[:0]
+	tc.buffered = 0
+	tc.stopFlushTimer()
+	if err != nil {
+		tc.fail(err)
+		return false
+	}
+	if !tc.deadline.IsZero() {
+		tc.deadline = time.Time{}
+		if err := tc.NetConn.SetWriteDeadline(tc.deadline); err != nil {
+			tc.fail(err)
+			return false
+		}
 	}
+	return true
 }
 
+// fail closes the connection after a failed write. Encoding only fails for
+// malformed frames or broken connections, which may have been left with a
+// partial frame.
+func (tc *Conn) fail(err error) {
+	for _, errc := range tc.unflushed {
+		errc <- err
+	}
+	tc.unflushed = nil
+	tc.mu.Lock()
+	tc.werr = err
+	tc.mu.Unlock()
+	tc.Logger.Debug("write frame failed, closing connection", slog.Int("connID", tc.ID), slog.String("error", err.Error()))
+	tc.Close()
+}
+
+// stopFlushTimer stops the flush timer, draining its channel if it fired.
+func (tc *Conn) stopFlushTimer() {
+	if !tc.flushTimer.Stop() {
+		select {
+		case <-tc.flushTimer.C:
+		default:
+		}
+	}
+}
+
+// Unary sends reqfrm and receives the next frame. It must not be used
+// concurrently with Recv.
 func (tc *Conn) Unary(ctx context.Context, reqfrm *frame.Frame) (resfrm *frame.Frame, err error) {
 	if err := tc.Send(ctx, reqfrm); err != nil {
 		return nil, err
@@ -55,53 +315,183 @@ func (tc *Conn) Unary(ctx context.Context, reqfrm *frame.Frame) (resfrm *frame.F
 	return resfrm, nil
 }
 
+// Recv receives the next frame. Frames are only read from the connection
+// when requested, so settings changed between calls, like Upgrade, apply to
+// the next frame. When ctx is done before a frame arrives, the frame is kept
+// for the next call rather than lost. Recv must not be called concurrently.
 func (tc *Conn) Recv(ctx context.Context) (*frame.Frame, error) {
-	// Run in goroutine, so we can return on timeout, or decode result
-	frmc := make(chan *frame.Frame)
-	errc := make(chan error)
-	go func() {
-		defer close(frmc)
-		defer close(errc)
-		frm, err := tc.decoder().Decode()
-		if err != nil {
-			errc <- err
+	tc.init()
+	if !tc.requested.Swap(true) {
+		tc.want <- struct{}{}
+	}
+	select {
+	case in := <-tc.in:
+		tc.requested.Store(false)
+		return in.frm, in.err
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	case <-tc.closed:
+		// A frame read before closing is still delivered
+		select {
+		case in := <-tc.in:
+			tc.requested.Store(false)
+			return in.frm, in.err
+		default:
+			return nil, tc.closeErr()
+		}
+	}
+}
+
+// readLoop reads a frame each time one is requested, until the connection is
+// closed. After a failed read, the error is returned for all requests.
+func (tc *Conn) readLoop() {
+	var err error
+	for {
+		select {
+		case <-tc.want:
+		case <-tc.closed:
+			return
+		}
+		var frm *frame.Frame
+		if err == nil {
+			frm, err = tc.decoder().Decode()
+		}
+		select {
+		case tc.in <- inbound{frm: frm, err: err}:
+		case <-tc.closed:
 			return
 		}
-		frmc <- frm
-	}()
+	}
+}
 
-	select {
-	case frm := <-frmc:
-		return frm, nil
-	case err := <-errc:
-		return nil, err
-	case <-ctx.Done():
-		return nil, ctx.Err()
+// Upgrade enabling encryption on communication. All frames sent and received
+// after the upgrade have their payload encrypted with AES-GCM using key.
+func (tc *Conn) Upgrade(key []byte) error {
+	aead, err := aesgcm.New(key)
+	if err != nil {
+		return err
 	}
+	c := &aesCipher{aead: aead}
+
+	tc.mu.Lock()
+	tc.dec = tc.newDecoder(c)
+	tc.mu.Unlock()
+	return tc.do(func() error {
+		tc.cipher = c
+		var err error
+		tc.enc, err = tc.newEncoder()
+		return err
+	})
+}
+
+// SetCompression makes frames sent after the call compress payloads of at
+// least threshold bytes with c. Received frames are decompressed with the
+// compression flagged in their header, regardless of c.
+func (tc *Conn) SetCompression(c frame.Compression, threshold int) error {
+	return tc.do(func() error {
+		if err := tc.enc.SetCompression(c, threshold); err != nil {
+			return err
+		}
+		tc.compression = c
+		tc.threshold = threshold
+		return nil
+	})
 }
 
-// Upgrade enabling encryption on communication
-func (tc *Conn) Upgrade(key []byte) {
-	b, err := aes.NewCipher(key)
+// SetPeerMaxFrameSize makes frames sent after the call split payloads larger
+// than n bytes, the maximum frame size of the peer, into chunks.
+func (tc *Conn) SetPeerMaxFrameSize(n int) {
+	tc.do(func() error {
+		tc.enc.SetMaxFrameSize(n)
+		tc.peerMaxFrame = n
+		return nil
+	})
 }
 
+// Close closes the connection, stopping the reader and writer goroutines.
+// Frames waiting to be sent fail with net.ErrClosed.
 func (tc *Conn) Close() error {
-	if err := tc.NetConn.Close(); err != nil {
-		return err
+	done := tc.done()
+	tc.mu.Lock()
+	select {
+	case <-done:
+	default:
+		close(done)
+	}
+	tc.mu.Unlock()
+	return tc.NetConn.Close()
+}
+
+// done returns the channel closed when the connection is closed.
+func (tc *Conn) done() chan struct{} {
+	tc.closeInit.Do(func() { tc.closed = make(chan struct{}) })
+	return tc.closed
+}
+
+// closeErr returns the error that made the writer close the connection, or
+// net.ErrClosed.
+func (tc *Conn) closeErr() error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if tc.werr != nil {
+		return fmt.Errorf("%w: %v", net.ErrClosed, tc.werr)
 	}
-	return nil
+	return net.ErrClosed
 }
 
 func (tc *Conn) decoder() *frame.FrameDecoder {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
 	if tc.dec == nil {
-		tc.dec = frame.NewDecoder(tc.NetConn)
+		tc.dec = tc.newDecoder(nil)
 	}
 	return tc.dec
 }
 
-func (tc *Conn) encoder() *frame.FrameEncoder {
-	if tc.enc == nil {
-		tc.enc = frame.NewEncoder(tc.NetConn)
+// newDecoder returns a decoder of frames encrypted with c, if not nil. mu must
+// be held.
+func (tc *Conn) newDecoder(c *aesCipher) *frame.FrameDecoder {
+	// A nil *aesCipher would be a non-nil decryptor
+	var dec *frame.FrameDecoder
+	if c != nil {
+		dec = frame.NewDecoder(tc.NetConn, c)
+	} else {
+		dec = frame.NewDecoder(tc.NetConn, nil)
 	}
-	return tc.enc
+	if tc.MaxFrameSize > 0 {
+		dec.SetMaxFrameSize(tc.MaxFrameSize)
+	}
+	if tc.MaxPayloadSize > 0 {
+		dec.SetMaxPayloadSize(tc.MaxPayloadSize)
+	}
+	return dec
+}
+
+// newEncoder returns an encoder using the cipher and settings of the
+// connection. It must be called on the writer goroutine.
+func (tc *Conn) newEncoder() (*frame.FrameEncoder, error) {
+	// A nil *aesCipher would be a non-nil encryptor
+	var enc *frame.FrameEncoder
+	if tc.cipher != nil {
+		enc = frame.NewEncoder(tc.bw, tc.cipher)
+	} else {
+		enc = frame.NewEncoder(tc.bw, nil)
+	}
+	if tc.peerMaxFrame > 0 {
+		enc.SetMaxFrameSize(tc.peerMaxFrame)
+	}
+	return enc, enc.SetCompression(tc.compression, tc.threshold)
+}
+
+// aesCipher encrypts and decrypts frame payloads.
+type aesCipher struct {
+	aead cipher.AEAD
+}
+
+func (c *aesCipher) Seal(dst, plain []byte) ([]byte, error) {
+	return aesgcm.AppendSeal(dst, c.aead, plain)
+}
+
+func (c *aesCipher) Open(sealed []byte) ([]byte, error) {
+	return aesgcm.OpenInPlace(c.aead, sealed)
 }
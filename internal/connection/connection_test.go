@@ -0,0 +1,363 @@
+package connection
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/nohns/eventale/internal/frame"
+)
+
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// pipe returns both ends of an in-memory connection.
+func pipe(t *testing.T, queueSize int) (*Conn, *Conn) {
+	t.Helper()
+	c1, c2 := net.Pipe()
+	a := &Conn{ID: 1, NetConn: c1, Logger: discardLogger, QueueSize: queueSize}
+	b := &Conn{ID: 2, NetConn: c2, Logger: discardLogger, QueueSize: queueSize}
+	t.Cleanup(func() { a.Close(); b.Close() })
+	return a, b
+}
+
+func TestConcurrentSend(t *testing.T) {
+	a, b := pipe(t, 4)
+	// Payloads are split into chunks, so interleaved writes would corrupt
+	// the frames received
+	a.SetPeerMaxFrameSize(frame.MinMaxFrameSize)
+	const senders, frames = 8, 20
+
+	var wg sync.WaitGroup
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < frames; j++ {
+				frm := &frame.Frame{Kind: frame.FrameKindAppend, Payload: bytes.Repeat([]byte{byte(i)}, 3*frame.MinMaxFrameSize+j)}
+				if err := a.Send(context.Background(), frm); err != nil {
+					t.Errorf("send: %v", err)
+					return
+				}
+			}
+		}(i)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for n := 0; n < senders*frames; n++ {
+		frm, err := b.Recv(ctx)
+		if err != nil {
+			t.Fatalf("recv: %v", err)
+		}
+		if len(frm.Payload) < 3*frame.MinMaxFrameSize || !bytes.Equal(frm.Payload, bytes.Repeat(frm.Payload[:1], len(frm.Payload))) {
+			t.Fatalf("frame %d received corrupted", n)
+		}
+	}
+	wg.Wait()
+}
+
+func TestRecvCanceled(t *testing.T) {
+	before := runtime.NumGoroutine()
+	a, b := pipe(t, 0)
+
+	// A frame arriving after Recv gave up is kept for the next call
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := a.Recv(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if err := b.Send(context.Background(), &frame.Frame{Kind: frame.FrameKindHeartbeat, Payload: []byte("late")}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	frm, err := a.Recv(context.Background())
+	if err != nil || string(frm.Payload) != "late" {
+		t.Fatalf("expected late frame, got %+v (err %v)", frm, err)
+	}
+
+	// Closing stops the reader and writer goroutines
+	a.Close()
+	b.Close()
+	if _, err := a.Recv(context.Background()); !errors.Is(err, net.ErrClosed) && !errors.Is(err, io.EOF) {
+		t.Fatalf("expected closed connection, got %v", err)
+	}
+	for deadline := time.Now().Add(time.Second); runtime.NumGoroutine() > before; {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d goroutines, got %d", before, runtime.NumGoroutine())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSendBackpressure(t *testing.T) {
+	a, _ := pipe(t, 1)
+
+	// The peer never reads, so the first frame blocks the writer, and the
+	// second fills the queue
+	errc := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			errc <- a.Send(context.Background(), &frame.Frame{Kind: frame.FrameKindHeartbeat})
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := a.Send(ctx, &frame.Frame{Kind: frame.FrameKindHeartbeat}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected send to block on a full queue, got %v", err)
+	}
+
+	a.Close()
+	for i := 0; i < 2; i++ {
+		if err := <-errc; !errors.Is(err, net.ErrClosed) && !errors.Is(err, io.ErrClosedPipe) {
+			t.Fatalf("expected closed connection, got %v", err)
+		}
+	}
+}
+
+func TestSendWriteDeadline(t *testing.T) {
+	a, _ := pipe(t, 0)
+
+	// A write timing out midway leaves a partial frame, closing the
+	// connection
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := a.Send(ctx, &frame.Frame{Kind: frame.FrameKindHeartbeat}); !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	err := a.Send(context.Background(), &frame.Frame{Kind: frame.FrameKindHeartbeat})
+	if !errors.Is(err, net.ErrClosed) {
+		t.Fatalf("expected closed connection, got %v", err)
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	a, b := pipe(t, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	key := bytes.Repeat([]byte{1}, 32)
+
+	// Like the handshake, each side upgrades right after the hello, while
+	// the other may already be sending encrypted frames. Pipes do not
+	// buffer, so frames are sent while the peer receives.
+	exchange := func(from, to *Conn, frm *frame.Frame) {
+		t.Helper()
+		errc := make(chan error, 1)
+		go func() { errc <- from.Send(ctx, frm) }()
+		got, err := to.Recv(ctx)
+		if err != nil || got.Kind != frm.Kind || !bytes.Equal(got.Payload, frm.Payload) {
+			t.Fatalf("expected frame %+v, got %+v (err %v)", frm, got, err)
+		}
+		if err := <-errc; err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+	exchange(a, b, &frame.Frame{Kind: frame.FrameKindClientHello})
+	exchange(b, a, &frame.Frame{Kind: frame.FrameKindServerHello})
+	if err := b.Upgrade(key); err != nil {
+		t.Fatalf("upgrade: %v", err)
+	}
+	if err := a.Upgrade(key); err != nil {
+		t.Fatalf("upgrade: %v", err)
+	}
+	exchange(a, b, &frame.Frame{Kind: frame.FrameKindAppend, Payload: []byte("secret")})
+	exchange(b, a, &frame.Frame{Kind: frame.FrameKindAppendResult, Payload: []byte("secret")})
+}
+
+// countingConn counts the writes to a connection.
+type countingConn struct {
+	net.Conn
+	writes atomic.Int64
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	c.writes.Add(1)
+	return c.Conn.Write(b)
+}
+
+// tcpPipe returns both ends of a local TCP connection, configured by
+// configure before use.
+func tcpPipe(tb testing.TB, configure func(a, b *Conn)) (*Conn, *Conn, *countingConn) {
+	tb.Helper()
+	lnr, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatalf("listen: %v", err)
+	}
+	defer lnr.Close()
+	c1, err := net.Dial("tcp", lnr.Addr().String())
+	if err != nil {
+		tb.Fatalf("dial: %v", err)
+	}
+	c2, err := lnr.Accept()
+	if err != nil {
+		tb.Fatalf("accept: %v", err)
+	}
+	counting := &countingConn{Conn: c1}
+	a := &Conn{ID: 1, NetConn: counting, Logger: discardLogger}
+	b := &Conn{ID: 2, NetConn: c2, Logger: discardLogger}
+	configure(a, b)
+	tb.Cleanup(func() { a.Close(); b.Close() })
+	return a, b, counting
+}
+
+func TestFlushInterval(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		interval  time.Duration
+		coalesced bool
+	}{
+		{name: "every frame", interval: -1},
+		{name: "interval", interval: 50 * time.Millisecond, coalesced: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			a, b, counting := tcpPipe(t, func(a, _ *Conn) { a.FlushInterval = tc.interval })
+			const frames = 20
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			// Frames sent within the interval are written together
+			start := time.Now()
+			var wg sync.WaitGroup
+			for i := 0; i < frames; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if err := a.Send(ctx, &frame.Frame{Kind: frame.FrameKindHeartbeat}); err != nil {
+						t.Errorf("send: %v", err)
+					}
+				}()
+			}
+			for i := 0; i < frames; i++ {
+				if _, err := b.Recv(ctx); err != nil {
+					t.Fatalf("recv: %v", err)
+				}
+			}
+			wg.Wait()
+			writes := counting.writes.Load()
+			if tc.coalesced && (writes >= frames || time.Since(start) < tc.interval) {
+				t.Fatalf("expected frames coalesced after %v, got %d writes in %v", tc.interval, writes, time.Since(start))
+			}
+			if !tc.coalesced && writes != frames {
+				t.Fatalf("expected a write per frame, got %d writes", writes)
+			}
+		})
+	}
+}
+
+func BenchmarkSend(b *testing.B) {
+	payload := bytes.Repeat([]byte("x"), 200)
+	for _, bench := range []struct {
+		name     string
+		interval time.Duration
+		queue    bool
+	}{
+		{name: "flush every frame", interval: -1},
+		{name: "coalesced", interval: 0},
+		{name: "coalesced queued", interval: 0, queue: true},
+		{name: "interval 1ms queued", interval: time.Millisecond, queue: true},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			a, peer, counting := tcpPipe(b, func(a, _ *Conn) { a.FlushInterval = bench.interval })
+			ctx := context.Background()
+			done := make(chan error, 1)
+			go func() {
+				for i := 0; i < b.N; i++ {
+					if _, err := peer.Recv(ctx); err != nil {
+						done <- err
+						return
+					}
+				}
+				done <- nil
+			}()
+
+			b.SetBytes(int64(len(payload)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			frm := &frame.Frame{Kind: frame.FrameKindSubscriptionEvent, Payload: payload}
+			for i := 0; i < b.N; i++ {
+				send := a.Send
+				if bench.queue {
+					send = a.Queue
+				}
+				if err := send(ctx, frm); err != nil {
+					b.Fatalf("send: %v", err)
+				}
+			}
+			if err := <-done; err != nil {
+				b.Fatalf("recv: %v", err)
+			}
+			b.ReportMetric(float64(counting.writes.Load())/float64(b.N), "writes/op")
+		})
+	}
+}
+
+func TestWebSocketFramePerMessage(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	msgs := make(chan []byte, 16)
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.CloseNow()
+		for {
+			_, b, err := ws.Read(ctx)
+			if err != nil {
+				close(msgs)
+				return
+			}
+			msgs <- b
+		}
+	}))
+	defer hs.Close()
+	ws, _, err := websocket.Dial(ctx, hs.URL, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	conn := WebSocket(ws)
+	defer conn.Close()
+
+	var frames [][]byte
+	for _, size := range []int{0, 100, 5000} {
+		var buf bytes.Buffer
+		if err := frame.NewEncoder(&buf, nil).Encode(&frame.Frame{Kind: frame.FrameKindAppend, Payload: make([]byte, size)}); err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+		frames = append(frames, buf.Bytes())
+	}
+	all := bytes.Join(frames, nil)
+
+	// Frames written in pieces, and several frames written at once, are
+	// sent in a message per frame
+	for i := 0; i < len(all); i += 7 {
+		if _, err := conn.Write(all[i:min(i+7, len(all))]); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if n, err := conn.Write(all); err != nil || n != len(all) {
+		t.Fatalf("write: wrote %d bytes (err %v)", n, err)
+	}
+	for i := 0; i < 2*len(frames); i++ {
+		want := frames[i%len(frames)]
+		select {
+		case msg := <-msgs:
+			if !bytes.Equal(msg, want) {
+				t.Fatalf("message %d: expected %d byte frame, got %d bytes", i, len(want), len(msg))
+			}
+		case <-ctx.Done():
+			t.Fatalf("message %d not received", i)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package connection
+
+import (
+	"context"
+	"net"
+
+	"github.com/coder/websocket"
+	"github.com/nohns/eventale/internal/frame"
+)
+
+// WebSocket adapts ws to a net.Conn for a Conn, sending each frame in a
+// binary message of its own. Received messages are read as one stream of
+// frames. Closing the net.Conn closes ws with a normal closure.
+func WebSocket(ws *websocket.Conn) net.Conn {
+	return &wsConn{Conn: websocket.NetConn(context.Background(), ws, websocket.MessageBinary)}
+}
+
+// _maxPartial is the largest buffer kept for frames written in pieces between
+// frames, so a single large frame does not hold on to its memory.
+const _maxPartial = 64 << 10
+
+// wsConn splits the bytes written to it into frames, as a Conn writes a frame
+// in several writes, or several frames in one.
+type wsConn struct {
+	net.Conn
+	// partial holds the start of a frame not completely written yet.
+	partial []byte
+}
+
+func (c *wsConn) Write(p []byte) (int, error) {
+	var n int
+	for len(p) > 0 {
+		if len(c.partial) == 0 && len(p) >= frame.LenSize && len(p) >= frame.Size(p) {
+			// A whole frame is written as is, without copying it
+			size := frame.Size(p)
+			if _, err := c.Conn.Write(p[:size]); err != nil {
+				return n, err
+			}
+			n += size
+			p = p[size:]
+			continue
+		}
+
+		want := frame.LenSize
+		if len(c.partial) >= frame.LenSize {
+			want = frame.Size(c.partial)
+		}
+		take := min(want-len(c.partial), len(p))
+		c.partial = append(c.partial, p[:take]...)
+		n += take
+		p = p[take:]
+		if len(c.partial) < frame.LenSize || len(c.partial) < frame.Size(c.partial) {
+			continue
+		}
+		_, err := c.Conn.Write(c.partial)
+		c.partial = c.partial[:0]
+		if cap(c.partial) > _maxPartial {
+			c.partial = nil
+		}
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
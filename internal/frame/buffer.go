@@ -0,0 +1,69 @@
+package frame
+
+import (
+	"io"
+	"slices"
+	"sync"
+)
+
+// _maxPooledBuf is the capacity above which buffers are not returned to the
+// pool, so a few large frames do not keep memory alive.
+const _maxPooledBuf = DefaultMaxFrameSize + _frameOverhead
+
+// bufPool holds buffers for payloads being compressed, encrypted or read from
+// the wire. Pointers are pooled, so putting a buffer back does not allocate.
+var bufPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 4<<10)
+		return &b
+	},
+}
+
+func getBuf() *[]byte {
+	return bufPool.Get().(*[]byte)
+}
+
+func putBuf(b *[]byte) {
+	if cap(*b) > _maxPooledBuf {
+		return
+	}
+	*b = (*b)[:0]
+	bufPool.Put(b)
+}
+
+// appendWriter is an io.Writer appending to a byte slice.
+type appendWriter struct {
+	b []byte
+}
+
+func (w *appendWriter) Write(p []byte) (int, error) {
+	w.b = append(w.b, p...)
+	return len(p), nil
+}
+
+// readAppend reads r until EOF, appending to dst. At most limit bytes are
+// read, and errTooLarge is returned if r holds more.
+func readAppend(dst []byte, r io.Reader, limit int, errTooLarge func() error) ([]byte, error) {
+	start := len(dst)
+	for {
+		read := len(dst) - start
+		if read > limit {
+			return nil, errTooLarge()
+		}
+		if len(dst) == cap(dst) {
+			// Grow geometrically, but never past the limit
+			dst = slices.Grow(dst, min(max(read, 512), limit+1-read))
+		}
+		n, err := r.Read(dst[len(dst):cap(dst)])
+		dst = dst[:len(dst)+n]
+		if err == io.EOF {
+			if len(dst)-start > limit {
+				return nil, errTooLarge()
+			}
+			return dst, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package frame
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression identifies the algorithm a frame payload is compressed with.
+// It is carried in the flags of the frame header, so frames can be decoded
+// without knowing what the peer negotiated.
+type Compression uint8
+
+const (
+	CompressionNone Compression = iota
+	CompressionGzip
+	CompressionZstd
+	CompressionSnappy
+	_CompressionLast
+)
+
+// DefaultCompressionThreshold is the payload size in bytes below which
+// payloads are sent uncompressed, as compressing them saves little.
+const DefaultCompressionThreshold = 512
+
+func (c Compression) String() string {
+	switch c {
+	case CompressionNone:
+		return "none"
+	case CompressionGzip:
+		return "gzip"
+	case CompressionZstd:
+		return "zstd"
+	case CompressionSnappy:
+		return "snappy"
+	}
+	return fmt.Sprintf("compression(%d)", uint8(c))
+}
+
+func (c Compression) validate() error {
+	if c >= _CompressionLast {
+		return fmt.Errorf("invalid frame compression %d", c)
+	}
+	return nil
+}
+
+// zstd encoders are expensive to create, but safe for concurrent use of
+// EncodeAll, so one is shared by all frames. Decoders are limited to the
+// maximum frame size of their FrameDecoder, and are created per decoder.
+var zstdEncoder = sync.OnceValues(func() (*zstd.Encoder, error) {
+	return zstd.NewWriter(nil)
+})
+
+// compress appends payload compressed with c to dst. The gzip writer of the
+// encoder is reset and reused between frames.
+func (e *FrameEncoder) compress(c Compression, dst, payload []byte) ([]byte, error) {
+	switch c {
+	case CompressionGzip:
+		e.gzw.b = dst
+		if e.gz == nil {
+			e.gz = gzip.NewWriter(&e.gzw)
+		} else {
+			e.gz.Reset(&e.gzw)
+		}
+		if _, err := e.gz.Write(payload); err != nil {
+			return nil, err
+		}
+		if err := e.gz.Close(); err != nil {
+			return nil, err
+		}
+		dst, e.gzw.b = e.gzw.b, nil
+		return dst, nil
+	case CompressionZstd:
+		enc, err := zstdEncoder()
+		if err != nil {
+			return nil, err
+		}
+		return enc.EncodeAll(payload, dst), nil
+	case CompressionSnappy:
+		// snappy only encodes into dst when it has room for the worst case
+		n := len(dst)
+		dst = slices.Grow(dst, snappy.MaxEncodedLen(len(payload)))
+		compressed := snappy.Encode(dst[n:cap(dst)], payload)
+		return dst[:n+len(compressed)], nil
+	}
+	return append(dst, payload...), nil
+}
+
+// decompress appends payload decompressed with c to dst, failing with
+// ErrFrameTooLarge rather than decompressing more than the maximum frame size.
+func (f *FrameDecoder) decompress(c Compression, dst, payload []byte) ([]byte, error) {
+	max := f.maxFrameSize
+	tooLarge := func() error {
+		return fmt.Errorf("decompressed payload exceeds %d bytes: %w", max, ErrFrameTooLarge)
+	}
+	switch c {
+	case CompressionGzip:
+		f.br.Reset(payload)
+		if f.gz == nil {
+			r, err := gzip.NewReader(&f.br)
+			if err != nil {
+				return nil, err
+			}
+			f.gz = r
+		} else if err := f.gz.Reset(&f.br); err != nil {
+			return nil, err
+		}
+		return readAppend(dst, f.gz, max, tooLarge)
+	case CompressionZstd:
+		if f.zstd == nil {
+			dec, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(uint64(max)))
+			if err != nil {
+				return nil, err
+			}
+			f.zstd = dec
+		}
+		plain, err := f.zstd.DecodeAll(payload, dst)
+		if errors.Is(err, zstd.ErrDecoderSizeExceeded) {
+			return nil, tooLarge()
+		}
+		return plain, err
+	case CompressionSnappy:
+		n, err := snappy.DecodedLen(payload)
+		if err != nil {
+			return nil, err
+		}
+		if n > max {
+			return nil, tooLarge()
+		}
+		start := len(dst)
+		dst = slices.Grow(dst, n)
+		if _, err := snappy.Decode(dst[start:start+n], payload); err != nil {
+			return nil, err
+		}
+		return dst[:start+n], nil
+	}
+	return append(dst, payload...), nil
+}
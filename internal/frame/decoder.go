@@ -2,76 +2,165 @@ package frame
 
 import (
 	"bytes"
+	"compress/gzip"
+	"encoding/binary"
 	"fmt"
 	"io"
+	"slices"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/nohns/eventale/internal"
+	"github.com/nohns/eventale/internal/uuid"
 )
 
 type decryptor interface {
-	Decrypt(in io.Reader, out io.Writer) (n int64, err error)
+	// Open decrypts sealed in place, returning the plain text in the memory
+	// of sealed.
+	Open(sealed []byte) ([]byte, error)
 }
 
+// FrameDecoder reads frames from r. It is not safe for concurrent use.
 type FrameDecoder struct {
 	r   io.Reader
 	dec decryptor
+
+	maxFrameSize   int
+	maxPayloadSize int
+	zstd           *zstd.Decoder
+	gz             *gzip.Reader
+	br             bytes.Reader
+	hdr            [_headerLen]byte
 }
 
 func NewDecoder(r io.Reader, dec decryptor) *FrameDecoder {
-	return &FrameDecoder{r: r, dec: dec}
+	return &FrameDecoder{r: r, dec: dec, maxFrameSize: DefaultMaxFrameSize, maxPayloadSize: DefaultMaxPayloadSize}
+}
+
+// SetMaxFrameSize sets the maximum size of the payload of a single frame,
+// both as received and decompressed. Larger frames are rejected with a
+// *TooLargeError.
+func (f *FrameDecoder) SetMaxFrameSize(n int) {
+	f.maxFrameSize = max(n, MinMaxFrameSize)
+	f.zstd = nil
+}
+
+// SetMaxPayloadSize sets the maximum size of a payload reassembled from
+// chunks, which is held in memory as a whole. Larger payloads are rejected
+// with a *TooLargeError. It is never less than the maximum frame size.
+func (f *FrameDecoder) SetMaxPayloadSize(n int) {
+	f.maxPayloadSize = n
 }
 
+// Decode reads the next frame. The chunks of frames split by the encoder are
+// reassembled into a single frame, so the decoder may hold up to the maximum
+// payload size in memory.
 func (f *FrameDecoder) Decode() (*Frame, error) {
-	// First 4-bytes is the payload len
-	payloadlen, err := f.readUInt32()
-	if err != nil {
-		return nil, fmt.Errorf("read frame len: %w", err)
+	frm := new(Frame)
+	if err := f.DecodeInto(frm); err != nil {
+		return nil, err
+	}
+	return frm, nil
+}
+
+// DecodeInto is like Decode, but decodes the next frame into frm, reusing
+// the capacity of its payload. The IDs of the frame are stored in frm, so
+// decoding a frame into a frame with room for its payload does not allocate.
+// The IDs and payload are only valid until frm is decoded into again.
+func (f *FrameDecoder) DecodeInto(frm *Frame) error {
+	frm.Payload = frm.Payload[:0]
+	more, err := f.decodeChunk(frm, true)
+	// Chunks of a frame are sent back to back, so the following frames
+	// continue the payload until one without the more flag
+	for err == nil && more {
+		more, err = f.decodeChunk(frm, false)
 	}
+	return err
+}
 
-	// Next 4-bytes is the kind of frame
-	frmkindNum, err := f.readUInt32()
-	if err != nil {
-		return nil, fmt.Errorf("read frame kind: %w", err)
+// decodeChunk reads a single frame from the wire, appending its payload to
+// the payload of frm. The header of the first chunk is decoded into frm,
+// while the header of following chunks must match it. more is set when the
+// payload continues in the next frame.
+func (f *FrameDecoder) decodeChunk(frm *Frame, first bool) (more bool, err error) {
+	if _, err := io.ReadFull(f.r, f.hdr[:]); err != nil {
+		return false, fmt.Errorf("read frame header: %w", err)
 	}
-	frmkind := FrameKind(frmkindNum)
+
+	// First 4-bytes is the payload len, next 4-bytes the kind of frame, with
+	// the flags in its top byte
+	payloadlen := binary.BigEndian.Uint32(f.hdr[0:4])
+	frmkindNum := binary.BigEndian.Uint32(f.hdr[4:8])
+	frmkind := FrameKind(frmkindNum & _kindMask)
 	if err := frmkind.validate(); err != nil {
-		return nil, err
+		return false, err
+	}
+	flags := frmkindNum >> _flagsShift
+	more = flags&_flagMore != 0
+	compression := Compression(flags & _compressionMask)
+	if err := compression.validate(); err != nil {
+		return false, err
+	}
+
+	// Then the ID of the frame and the ID of the frame it responds to
+	if first {
+		frm.Kind = frmkind
+		frm.ID = decodeID(&frm.id, f.hdr[8:24])
+		frm.RespondsTo = decodeID(&frm.respondsTo, f.hdr[24:40])
+	} else if frmkind != frm.Kind || !bytes.Equal(f.hdr[8:24], idBytes(frm.ID)) {
+		return false, fmt.Errorf("frame %d continued by frame %d: %w", frm.Kind, frmkind, ErrProtocol)
 	}
 
 	// Early exit when payload is zero
 	if payloadlen == 0 {
-		return &Frame{Kind: frmkind}, nil
+		return more, nil
+	}
+	// The length is checked before reading, so a malicious header can not
+	// make the decoder allocate more than the maximum frame size
+	if int64(payloadlen) > int64(f.maxFrameSize)+_frameOverhead {
+		return false, &TooLargeError{Frame: header(frm), Size: int(payloadlen), Max: f.maxFrameSize}
 	}
 
-	// Finally, read the payload of the frame
-	var payload bytes.Buffer
+	// Finally, read the payload of the frame into a pooled buffer, and
+	// decrypt and decompress it onto the payload of frm
+	buf := getBuf()
+	defer putBuf(buf)
+	*buf = slices.Grow((*buf)[:0], int(payloadlen))[:payloadlen]
+	if _, err := io.ReadFull(f.r, *buf); err != nil {
+		return false, err
+	}
+	plain := *buf
 	if f.dec != nil {
-		if _, err := f.dec.Decrypt(io.LimitReader(f.r, int64(payloadlen)), &payload); err != nil {
-			return nil, err
-		}
-	} else {
-		if _, err := io.CopyN(&payload, f.r, int64(payloadlen)); err != nil {
-			return nil, err
+		if plain, err = f.dec.Open(plain); err != nil {
+			return false, err
 		}
 	}
-	return &Frame{
-		Kind:    frmkind,
-		Payload: payload.Bytes(),
-	}, nil
+	size := len(frm.Payload)
+	if frm.Payload, err = f.decompress(compression, frm.Payload, plain); err != nil {
+		return false, fmt.Errorf("decompress %s payload: %w", compression, err)
+	}
+	if limit := max(f.maxPayloadSize, f.maxFrameSize); len(frm.Payload) > limit {
+		frm.Payload = frm.Payload[:size]
+		return false, &TooLargeError{Frame: header(frm), Size: len(frm.Payload), Max: limit}
+	}
+	return more, nil
 }
 
-func (f *FrameDecoder) readUInt32() (uint32, error) {
-	var (
-		val uint32
-		buf = make([]byte, _uint32Len)
-	)
-	if _, err := io.ReadFull(f.r, buf); err != nil {
-		return 0, err
+var _zeroID [_idLen]byte
+
+// decodeID copies a 16 byte frame ID into dst. An ID of all zeroes means no
+// ID was set, in which case nil is returned.
+func decodeID(dst *uuid.UUID, b []byte) internal.ID {
+	if bytes.Equal(b, _zeroID[:]) {
+		return nil
 	}
+	copy(dst[:], b)
+	return dst
+}
 
-	// For each byte we recv, bitwise OR it into the zero-valued frmlen,
-	// resulting in converting a byte buffer of len "_uint32Len" into a
-	// scalar value "val". Reading happens in big-endian.
-	for i := 0; i < len(buf); i++ {
-		val |= uint32(buf[i]) << (8 * uint32(_uint32Len-i-1))
+// idBytes returns the 16 byte representation of id, or zeroes when id is nil.
+func idBytes(id internal.ID) []byte {
+	if id == nil {
+		return _zeroID[:]
 	}
-	return val, nil
+	return id.Bytes()
 }
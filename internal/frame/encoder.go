@@ -1,85 +1,146 @@
 package frame
 
 import (
-	"bytes"
+	"compress/gzip"
+	"encoding/binary"
 	"fmt"
 	"io"
+	"net"
+
+	"github.com/nohns/eventale/internal"
 )
 
 type encryptor interface {
-	Encrypt(in io.Reader, out io.Writer) (n int, err error)
+	// Seal appends plain encrypted to dst.
+	Seal(dst, plain []byte) ([]byte, error)
 }
 
+// FrameEncoder writes frames to w. It is not safe for concurrent use.
+//
+// The header and payload of a frame are written with a single vectored write
+// when w is a net.Conn, and buffers for compressing and encrypting payloads
+// are pooled, so encoding does not allocate in the steady state.
 type FrameEncoder struct {
-	buf bytes.Buffer
 	w   io.Writer
 	enc encryptor
+
+	compression  Compression
+	threshold    int
+	maxFrameSize int
+
+	hdr [_headerLen]byte
+	iov [2][]byte
+	vec net.Buffers
+	gz  *gzip.Writer
+	gzw appendWriter
 }
 
 func NewEncoder(w io.Writer, enc encryptor) *FrameEncoder {
-	return &FrameEncoder{w: w, enc: enc}
+	return &FrameEncoder{w: w, enc: enc, maxFrameSize: DefaultMaxFrameSize}
 }
 
-func (e *FrameEncoder) Encode(frm *Frame) error {
-	// Encrypt payload, so payload size is known
-	var payload bytes.Buffer
-	if _, err := e.enc.Encrypt(bytes.NewReader(frm.Payload), &payload); err != nil {
-		return err
-	}
-
-	// Build up buffer for the entire frame
-	if err := e.writeUInt32(uint32(payload.Len())); err != nil {
-		return err
-	}
-	if err := e.writeUInt32(uint32(frm.Kind)); err != nil {
-		return err
-	}
-	if err := e.write(payload.Bytes()); err != nil {
-		return err
-	}
+// SetMaxFrameSize sets the maximum frame size accepted by the peer. Larger
+// payloads are split into chunks sent as consecutive frames.
+func (e *FrameEncoder) SetMaxFrameSize(n int) {
+	e.maxFrameSize = max(n, MinMaxFrameSize)
+}
 
-	// Flush entire frame buffer to target
-	if _, err := io.Copy(e.w, &e.buf); err != nil {
+// SetCompression makes the encoder compress payloads of at least threshold
+// bytes with c. Payloads which do not shrink are sent uncompressed.
+func (e *FrameEncoder) SetCompression(c Compression, threshold int) error {
+	if err := c.validate(); err != nil {
 		return err
 	}
+	e.compression = c
+	e.threshold = threshold
 	return nil
 }
 
-func (e *FrameEncoder) write(b []byte) error {
-	var written int
-	for written != len(b) {
-		n, err := e.buf.Write(b[written:])
-		if err != nil {
+// Encode writes frm, split into chunks if its payload exceeds the maximum
+// frame size. Chunks are encoded one at a time, so a large payload is not
+// copied into a single buffer, but the decoder of the peer reassembles it in
+// memory, up to its maximum payload size. The payload of frm is not retained.
+func (e *FrameEncoder) Encode(frm *Frame) error {
+	payload := frm.Payload
+	for {
+		chunk, more := payload, false
+		if len(chunk) > e.maxFrameSize {
+			chunk, more = payload[:e.maxFrameSize], true
+		}
+		if err := e.encodeChunk(frm, chunk, more); err != nil {
 			return err
 		}
-		written += n
+		if !more {
+			return nil
+		}
+		payload = payload[len(chunk):]
 	}
-	// fmt.Printf("encode buf: ")
-	// printBuf(b)
-	return nil
 }
 
-func printBuf(buf []byte) {
-	fmt.Print("[")
-	for i, b := range buf {
-		fmt.Printf("0b%b", b)
-		if i < len(buf)-1 {
-			fmt.Print(", ")
+// encodeChunk writes a single frame with the header of frm and chunk as
+// payload. more is set when the payload continues in the next frame.
+func (e *FrameEncoder) encodeChunk(frm *Frame, chunk []byte, more bool) error {
+	// Compress before encrypting, as encrypted data does not compress
+	payload, compression := chunk, CompressionNone
+	if e.compression != CompressionNone && len(chunk) >= e.threshold {
+		buf := getBuf()
+		defer putBuf(buf)
+		compressed, err := e.compress(e.compression, (*buf)[:0], chunk)
+		if err != nil {
+			return fmt.Errorf("compress payload: %v", err)
+		}
+		*buf = compressed
+		if len(compressed) < len(chunk) {
+			payload, compression = compressed, e.compression
 		}
 	}
-	fmt.Println("]")
+	if e.enc != nil {
+		buf := getBuf()
+		defer putBuf(buf)
+		sealed, err := e.enc.Seal((*buf)[:0], payload)
+		if err != nil {
+			return err
+		}
+		*buf, payload = sealed, sealed
+	}
+
+	flags := uint32(compression)
+	if more {
+		flags |= _flagMore
+	}
+	binary.BigEndian.PutUint32(e.hdr[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(e.hdr[4:8], uint32(frm.Kind)|flags<<_flagsShift)
+	if err := putID(e.hdr[8:24], frm.ID); err != nil {
+		return err
+	}
+	if err := putID(e.hdr[24:40], frm.RespondsTo); err != nil {
+		return err
+	}
+
+	// Write header and payload without copying them into one buffer. The
+	// write consumes vec, so it is rebuilt from iov for every frame. Empty
+	// payloads are left out, as some writers block on empty writes.
+	e.iov = [2][]byte{e.hdr[:], payload}
+	e.vec = e.iov[:1]
+	if len(payload) > 0 {
+		e.vec = e.iov[:]
+	}
+	_, err := e.vec.WriteTo(e.w)
+	e.iov = [2][]byte{}
+	return err
 }
 
-func (e *FrameEncoder) writeUInt32(val uint32) error {
-	b := make([]byte, 4)
-	bitmask := uint32(0xFF) // Mask for first 8 bits of uint32
-	for i := range b {
-		// Start with biggest most significant byte (big-endian). Shift the
-		// currently selected byte into the least significant 8 bits of val,
-		// and zero out all other bits with mask.
-		bindex := len(b) - i - 1
-		shifted := val >> (8 * bindex)
-		b[i] = byte(shifted & bitmask)
+// putID writes the 16 byte representation of id into b, or zeroes when id is
+// nil.
+func putID(b []byte, id internal.ID) error {
+	if id == nil {
+		clear(b)
+		return nil
 	}
-	return e.write(b)
+	idb := id.Bytes()
+	if len(idb) != _idLen {
+		return fmt.Errorf("invalid frame id length %d", len(idb))
+	}
+	copy(b, idb)
+	return nil
 }
@@ -1,16 +1,84 @@
 package frame
 
 import (
+	"encoding/binary"
+	"errors"
 	"fmt"
 
 	"github.com/nohns/eventale/internal"
+	"github.com/nohns/eventale/internal/uuid"
 	"google.golang.org/protobuf/proto"
 )
 
 const (
 	_uint32Len = 4
+	_idLen     = 16
+	// _headerLen is the size of the frame header: the payload length, kind,
+	// ID and the ID responded to.
+	_headerLen = 2*_uint32Len + 2*_idLen
 )
 
+// LenSize is the number of bytes at the start of a frame holding the length
+// of its payload.
+const LenSize = _uint32Len
+
+// Size returns the size in bytes of the frame on the wire starting with b,
+// which must hold at least LenSize bytes.
+func Size(b []byte) int {
+	return _headerLen + int(binary.BigEndian.Uint32(b))
+}
+
+// The most significant byte of the frame kind field in the header holds flags
+// describing the payload. The lowest bits are its Compression, followed by a
+// flag set on all but the last chunk of a payload split into several frames.
+const (
+	_flagsShift      = 24
+	_kindMask        = 1<<_flagsShift - 1
+	_compressionMask = 0x07
+	_flagMore        = 0x08
+)
+
+const (
+	// DefaultMaxFrameSize is the maximum size of a frame payload in bytes,
+	// unless configured otherwise. Larger payloads are split into chunks.
+	DefaultMaxFrameSize = 1 << 20
+	// DefaultMaxPayloadSize is the maximum size in bytes of a payload
+	// reassembled from chunks, unless configured otherwise.
+	DefaultMaxPayloadSize = 64 << 20
+	// MinMaxFrameSize is the smallest maximum frame size that can be set.
+	MinMaxFrameSize = 4 << 10
+	// _frameOverhead is the number of bytes encryption may add to a payload
+	// on the wire.
+	_frameOverhead = 64
+)
+
+var (
+	// ErrFrameTooLarge is returned when a frame exceeds the maximum frame
+	// size.
+	ErrFrameTooLarge = errors.New("frame too large")
+	// ErrProtocol is returned when the peer sends frames violating the
+	// protocol.
+	ErrProtocol = errors.New("protocol error")
+)
+
+// TooLargeError is returned when decoding a frame larger than the maximum
+// frame size. The payload of the frame is not read, leaving the connection
+// unusable, but the header is kept to respond to the frame.
+type TooLargeError struct {
+	// Frame holds the header of the frame, without payload.
+	Frame *Frame
+	Size  int
+	Max   int
+}
+
+func (e *TooLargeError) Error() string {
+	return fmt.Sprintf("frame of %d bytes exceeds maximum of %d bytes: %v", e.Size, e.Max, ErrFrameTooLarge)
+}
+
+func (e *TooLargeError) Unwrap() error {
+	return ErrFrameTooLarge
+}
+
 type FrameKind uint32
 
 const (
@@ -18,6 +86,45 @@ const (
 	FrameKindSecretPublish
 	FrameKindClientHello
 	FrameKindServerHello
+	FrameKindError
+	FrameKindAppend
+	FrameKindAppendResult
+	FrameKindReadStream
+	FrameKindReadStreamResult
+	FrameKindSubscribe
+	FrameKindSubscribed
+	FrameKindSubscriptionEvent
+	FrameKindUnsubscribe
+	FrameKindWriteSnapshot
+	FrameKindSnapshotWritten
+	FrameKindReadSnapshot
+	FrameKindReadSnapshotResult
+	FrameKindWriteCheckpoint
+	FrameKindCheckpointWritten
+	FrameKindReadCheckpoint
+	FrameKindReadCheckpointResult
+	FrameKindAcquireCheckpointLease
+	FrameKindCheckpointLease
+	FrameKindWriteStreamMetadata
+	FrameKindStreamMetadataWritten
+	FrameKindReadStreamMetadata
+	FrameKindReadStreamMetadataResult
+	FrameKindDeleteStream
+	FrameKindStreamDeleted
+	FrameKindForgetSubject
+	FrameKindSubjectForgotten
+	FrameKindBeginTransaction
+	FrameKindTransactionBegun
+	FrameKindTransactionAppend
+	FrameKindTransactionAppended
+	FrameKindCommitTransaction
+	FrameKindTransactionCommitted
+	FrameKindRollbackTransaction
+	FrameKindTransactionRolledBack
+	FrameKindReadAll
+	FrameKindReadAllResult
+	FrameKindSubscriptionEvents
+	FrameKindSubscriptionCredit
 	_FrameKindLast
 )
 
@@ -33,6 +140,25 @@ type Frame struct {
 	ID         internal.ID
 	RespondsTo internal.ID
 	Payload    []byte
+
+	// id and respondsTo hold the IDs of decoded frames, so decoding into a
+	// frame does not allocate them.
+	id, respondsTo uuid.UUID
+}
+
+// header returns a copy of frm without payload. The IDs are copied, as frm
+// may be decoded into again.
+func header(frm *Frame) *Frame {
+	h := &Frame{Kind: frm.Kind}
+	if frm.ID != nil {
+		copy(h.id[:], frm.ID.Bytes())
+		h.ID = &h.id
+	}
+	if frm.RespondsTo != nil {
+		copy(h.respondsTo[:], frm.RespondsTo.Bytes())
+		h.RespondsTo = &h.respondsTo
+	}
+	return h
 }
 
 type makeOpt func(*Frame) error
@@ -0,0 +1,280 @@
+package frame
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/nohns/eventale/internal/aesgcm"
+	"github.com/nohns/eventale/internal/uuid"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	large := []byte(strings.Repeat(`{"sku":"abc","qty":1},`, 200))
+	for _, c := range []Compression{CompressionNone, CompressionGzip, CompressionZstd, CompressionSnappy} {
+		for _, payload := range [][]byte{nil, []byte("small"), large} {
+			var buf bytes.Buffer
+			enc := NewEncoder(&buf, nil)
+			if err := enc.SetCompression(c, DefaultCompressionThreshold); err != nil {
+				t.Fatalf("set compression: %v", err)
+			}
+			frm, err := Make(FrameKindAppend, WithID(uuid.IDer))
+			if err != nil {
+				t.Fatalf("make: %v", err)
+			}
+			frm.Payload = payload
+			if err := enc.Encode(frm); err != nil {
+				t.Fatalf("%s: encode: %v", c, err)
+			}
+			sent := buf.Len()
+
+			got, err := NewDecoder(&buf, nil).Decode()
+			if err != nil {
+				t.Fatalf("%s: decode: %v", c, err)
+			}
+			if got.Kind != frm.Kind || got.ID.String() != frm.ID.String() || !bytes.Equal(got.Payload, payload) {
+				t.Fatalf("%s: decoded frame %+v differs from %+v", c, got, frm)
+			}
+			// Only payloads above the threshold are compressed
+			compressed := sent < len(payload)+40
+			if want := c != CompressionNone && len(payload) >= DefaultCompressionThreshold; compressed != want {
+				t.Fatalf("%s: %d byte payload sent in %d bytes", c, len(payload), sent)
+			}
+		}
+	}
+}
+
+func TestDecodeInvalidCompression(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf, nil).Encode(&Frame{Kind: FrameKindHeartbeat}); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	b := buf.Bytes()
+	b[4] = 0x07 // Flags are the first byte of the kind
+	if _, err := NewDecoder(bytes.NewReader(b), nil).Decode(); err == nil {
+		t.Fatalf("expected error decoding unknown compression")
+	}
+}
+
+func TestEncodeDecodeChunked(t *testing.T) {
+	payload := make([]byte, 5*MinMaxFrameSize+100)
+	for i := range payload {
+		payload[i] = byte(i)
+	}
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf, nil)
+	enc.SetMaxFrameSize(MinMaxFrameSize)
+	frm, err := Make(FrameKindAppend, WithID(uuid.IDer))
+	if err != nil {
+		t.Fatalf("make: %v", err)
+	}
+	frm.Payload = payload
+	if err := enc.Encode(frm); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if want := len(payload) + 6*40; buf.Len() != want {
+		t.Fatalf("expected 6 chunks in %d bytes, got %d bytes", want, buf.Len())
+	}
+
+	dec := NewDecoder(&buf, nil)
+	dec.SetMaxFrameSize(MinMaxFrameSize)
+	got, err := dec.Decode()
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.ID.String() != frm.ID.String() || !bytes.Equal(got.Payload, payload) {
+		t.Fatalf("reassembled frame differs")
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected all chunks read, %d bytes left", buf.Len())
+	}
+
+	// Reassembled payloads are limited in total
+	if err := enc.Encode(frm); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	dec.SetMaxPayloadSize(4 * MinMaxFrameSize)
+	_, err = dec.Decode()
+	var tooLarge *TooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Max != 4*MinMaxFrameSize {
+		t.Fatalf("expected too large error, got %v", err)
+	}
+}
+
+func TestDecodeTooLarge(t *testing.T) {
+	frm, err := Make(FrameKindAppend, WithID(uuid.IDer))
+	if err != nil {
+		t.Fatalf("make: %v", err)
+	}
+
+	// A header claiming a 4 GiB payload is rejected before reading it
+	var buf bytes.Buffer
+	frm.Payload = []byte("x")
+	if err := NewEncoder(&buf, nil).Encode(frm); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	b := buf.Bytes()
+	copy(b, []byte{0xff, 0xff, 0xff, 0xff})
+	_, err = NewDecoder(bytes.NewReader(b), nil).Decode()
+	var tooLarge *TooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Frame.ID.String() != frm.ID.String() {
+		t.Fatalf("expected too large error for frame, got %v", err)
+	}
+
+	// Payloads decompressing beyond the maximum are rejected as well
+	for _, c := range []Compression{CompressionGzip, CompressionZstd, CompressionSnappy} {
+		buf.Reset()
+		enc := NewEncoder(&buf, nil)
+		enc.SetMaxFrameSize(1 << 20)
+		enc.SetCompression(c, 0)
+		frm.Payload = make([]byte, 1<<20)
+		if err := enc.Encode(frm); err != nil {
+			t.Fatalf("%s: encode: %v", c, err)
+		}
+		dec := NewDecoder(&buf, nil)
+		dec.SetMaxFrameSize(MinMaxFrameSize)
+		if _, err := dec.Decode(); !errors.Is(err, ErrFrameTooLarge) {
+			t.Fatalf("%s: expected ErrFrameTooLarge, got %v", c, err)
+		}
+	}
+}
+
+// testCipher seals payloads with AES-GCM, like connections do once upgraded.
+type testCipher struct {
+	aead cipher.AEAD
+}
+
+func newTestCipher(tb testing.TB) *testCipher {
+	aead, err := aesgcm.New(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		tb.Fatalf("cipher: %v", err)
+	}
+	return &testCipher{aead: aead}
+}
+
+func (c *testCipher) Seal(dst, plain []byte) ([]byte, error) {
+	return aesgcm.AppendSeal(dst, c.aead, plain)
+}
+
+func (c *testCipher) Open(sealed []byte) ([]byte, error) {
+	return aesgcm.OpenInPlace(c.aead, sealed)
+}
+
+func TestDecodeInto(t *testing.T) {
+	c := newTestCipher(t)
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf, c)
+	enc.SetMaxFrameSize(MinMaxFrameSize)
+	if err := enc.SetCompression(CompressionSnappy, DefaultCompressionThreshold); err != nil {
+		t.Fatalf("set compression: %v", err)
+	}
+	dec := NewDecoder(&buf, c)
+	dec.SetMaxFrameSize(MinMaxFrameSize)
+
+	// The same frame is decoded into again and again, with payloads that
+	// are chunked, compressed, small and empty
+	var got Frame
+	for _, payload := range [][]byte{
+		[]byte(strings.Repeat(`{"sku":"abc","qty":1},`, 1000)),
+		[]byte("small"),
+		nil,
+	} {
+		frm, err := Make(FrameKindAppend, WithID(uuid.IDer))
+		if err != nil {
+			t.Fatalf("make: %v", err)
+		}
+		frm.Payload = payload
+		if err := enc.Encode(frm); err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+		if err := dec.DecodeInto(&got); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if got.Kind != frm.Kind || got.ID.String() != frm.ID.String() || got.RespondsTo != nil || !bytes.Equal(got.Payload, payload) {
+			t.Fatalf("decoded frame %+v differs from %+v", got, frm)
+		}
+	}
+}
+
+func BenchmarkEncode(b *testing.B) {
+	benchmarkFrames(b, func(b *testing.B, enc *FrameEncoder, _ *FrameDecoder, frm *Frame, _ *bytes.Reader) {
+		for i := 0; i < b.N; i++ {
+			if err := enc.Encode(frm); err != nil {
+				b.Fatalf("encode: %v", err)
+			}
+		}
+	})
+}
+
+func BenchmarkDecodeInto(b *testing.B) {
+	benchmarkFrames(b, func(b *testing.B, _ *FrameEncoder, dec *FrameDecoder, _ *Frame, r *bytes.Reader) {
+		var frm Frame
+		for i := 0; i < b.N; i++ {
+			r.Seek(0, io.SeekStart)
+			if err := dec.DecodeInto(&frm); err != nil {
+				b.Fatalf("decode: %v", err)
+			}
+		}
+	})
+}
+
+// benchmarkFrames runs bench for a typical append payload, plain, encrypted
+// and compressed. The decoder reads from r, which holds one encoded frame.
+func benchmarkFrames(b *testing.B, bench func(b *testing.B, enc *FrameEncoder, dec *FrameDecoder, frm *Frame, r *bytes.Reader)) {
+	payload := []byte(strings.Repeat(`{"sku":"abc","qty":1},`, 50))
+	frm, err := Make(FrameKindAppend, WithID(uuid.IDer))
+	if err != nil {
+		b.Fatalf("make: %v", err)
+	}
+	frm.Payload = payload
+
+	for _, tc := range []struct {
+		name        string
+		encrypted   bool
+		compression Compression
+	}{
+		{name: "plain"},
+		{name: "encrypted", encrypted: true},
+		{name: "snappy", compression: CompressionSnappy},
+		{name: "zstd", compression: CompressionZstd},
+		{name: "gzip", compression: CompressionGzip},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			var c *testCipher
+			if tc.encrypted {
+				c = newTestCipher(b)
+			}
+			newEncoder := func(w io.Writer) *FrameEncoder {
+				if c == nil {
+					return NewEncoder(w, nil)
+				}
+				return NewEncoder(w, c)
+			}
+			var wire bytes.Buffer
+			enc := newEncoder(&wire)
+			if err := enc.SetCompression(tc.compression, DefaultCompressionThreshold); err != nil {
+				b.Fatalf("set compression: %v", err)
+			}
+			if err := enc.Encode(frm); err != nil {
+				b.Fatalf("encode: %v", err)
+			}
+			r := bytes.NewReader(wire.Bytes())
+			var dec *FrameDecoder
+			if c == nil {
+				dec = NewDecoder(r, nil)
+			} else {
+				dec = NewDecoder(r, c)
+			}
+
+			enc = newEncoder(io.Discard)
+			enc.SetCompression(tc.compression, DefaultCompressionThreshold)
+			b.SetBytes(int64(len(payload)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			bench(b, enc, dec, frm, r)
+		})
+	}
+}
@@ -0,0 +1,831 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/nohns/eventale/internal/aesgcm"
+)
+
+// migrations are applied in order to bring the database schema up to date.
+// The index of the last applied migration is tracked using the user_version
+// pragma, so migrations must only ever be appended.
+var migrations = []string{
+	`CREATE TABLE events (
+		position     INTEGER PRIMARY KEY AUTOINCREMENT,
+		stream       TEXT NOT NULL,
+		version      INTEGER NOT NULL,
+		type         TEXT NOT NULL,
+		content_type TEXT NOT NULL,
+		data         BLOB,
+		UNIQUE (stream, version)
+	)`,
+	`CREATE TABLE snapshots (
+		stream       TEXT PRIMARY KEY,
+		version      INTEGER NOT NULL,
+		type         TEXT NOT NULL,
+		content_type TEXT NOT NULL,
+		data         BLOB
+	)`,
+	`CREATE TABLE checkpoints (
+		name     TEXT PRIMARY KEY,
+		position INTEGER NOT NULL
+	)`,
+	`CREATE TABLE links (
+		stream   TEXT NOT NULL,
+		version  INTEGER NOT NULL,
+		position INTEGER NOT NULL REFERENCES events (position),
+		PRIMARY KEY (stream, version)
+	)`,
+	`CREATE TABLE streams (
+		stream  TEXT PRIMARY KEY,
+		version INTEGER NOT NULL
+	);
+	INSERT INTO streams (stream, version) SELECT stream, MAX(version) FROM events GROUP BY stream`,
+	`ALTER TABLE events ADD COLUMN created INTEGER NOT NULL DEFAULT 0`,
+	`CREATE TABLE stream_metadata (
+		stream          TEXT PRIMARY KEY,
+		max_age         INTEGER NOT NULL,
+		max_count       INTEGER NOT NULL,
+		truncate_before INTEGER NOT NULL,
+		custom          TEXT
+	)`,
+	`CREATE TABLE derived_streams (
+		stream  TEXT PRIMARY KEY,
+		version INTEGER NOT NULL
+	);
+	INSERT INTO derived_streams (stream, version) SELECT stream, MAX(version) FROM links GROUP BY stream`,
+	`ALTER TABLE streams ADD COLUMN tombstoned INTEGER NOT NULL DEFAULT 0`,
+	`CREATE TABLE subject_keys (
+		id      INTEGER PRIMARY KEY AUTOINCREMENT,
+		subject TEXT NOT NULL UNIQUE,
+		key     BLOB NOT NULL
+	);
+	ALTER TABLE events ADD COLUMN subject TEXT NOT NULL DEFAULT '';
+	ALTER TABLE events ADD COLUMN subject_key INTEGER`,
+	`ALTER TABLE events ADD COLUMN event_id BLOB;
+	CREATE UNIQUE INDEX events_event_id ON events (event_id)`,
+	`ALTER TABLE events ADD COLUMN correlation_id TEXT NOT NULL DEFAULT '';
+	ALTER TABLE events ADD COLUMN causation_id TEXT NOT NULL DEFAULT '';
+	ALTER TABLE events ADD COLUMN principal TEXT NOT NULL DEFAULT '';
+	ALTER TABLE events ADD COLUMN headers TEXT;
+	CREATE INDEX events_correlation_id ON events (correlation_id) WHERE correlation_id != ''`,
+	`CREATE INDEX events_created ON events (created)`,
+}
+
+// _eventColumns are the columns scanned by scanEvent, selected from events
+// aliased e joined with _subjectKeyJoin.
+const (
+	_eventColumns = "e.position, e.stream, e.version, e.type, e.content_type, e.data, e.subject, e.subject_key, k.key, e.event_id, " +
+		"e.correlation_id, e.causation_id, e.principal, e.headers, e.created"
+	_subjectKeyJoin = "LEFT JOIN subject_keys k ON k.id = e.subject_key"
+)
+
+// _expired matches events of alias e which are hidden by the metadata of
+// their stream, or because the stream was hard deleted. It takes the current
+// time in Unix nanoseconds as parameter.
+const _expired = `EXISTS (
+	SELECT 1 FROM streams s LEFT JOIN stream_metadata m ON m.stream = s.stream
+	WHERE s.stream = e.stream AND (
+		s.tombstoned
+		OR e.version < m.truncate_before
+		OR (m.max_count > 0 AND e.version + m.max_count <= s.version)
+		OR (m.max_age > 0 AND e.created < ? - m.max_age)
+	)
+)`
+
+// SQLiteStore is a Store persisting events in a SQLite database.
+type SQLiteStore struct {
+	db *sql.DB
+	// now returns the current time, used to timestamp appended events and
+	// to expire events by age.
+	now func() time.Time
+}
+
+var _ Store = (*SQLiteStore)(nil)
+
+// OpenSQLite opens the SQLite database at path, creating and migrating it if
+// needed. An empty path opens a private in-memory database.
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	// Secure delete overwrites deleted content, so forgotten subject keys
+	// cannot be recovered from free pages.
+	dsn := "file::memory:?_secure_delete=true"
+	if path != "" {
+		dsn = "file:" + path + "?_journal_mode=WAL&_busy_timeout=5000&_secure_delete=true"
+	}
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite: %v", err)
+	}
+	// SQLite only allows a single writer at a time, and every connection to an
+	// in-memory database gets a database of its own. Sticking to a single
+	// connection sidesteps both.
+	db.SetMaxOpenConns(1)
+
+	s := &SQLiteStore{db: db, now: time.Now}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SQLiteStore) migrate() error {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("read schema version: %v", err)
+	}
+	for i := version; i < len(migrations); i++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %v", i+1, err)
+		}
+		// Pragmas do not support placeholders
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %v", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migration %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Append(ctx context.Context, stream string, expectedVersion int64, events []EventData) (AppendResult, error) {
+	res, err := s.AppendMulti(ctx, []StreamAppend{{Stream: stream, ExpectedVersion: expectedVersion, Events: events}})
+	if err != nil {
+		return AppendResult{}, err
+	}
+	return res[0], nil
+}
+
+func (s *SQLiteStore) AppendMulti(ctx context.Context, appends []StreamAppend) ([]AppendResult, error) {
+	for _, a := range appends {
+		if err := validateStream(a.Stream); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	created, err := s.commitTime(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	w := &appendWriter{
+		tx:           tx,
+		linkVersions: make(map[string]uint64),
+		keys:         make(map[string]subjectKey),
+		created:      created,
+	}
+	results := make([]AppendResult, len(appends))
+	for i, a := range appends {
+		if results[i], err = s.appendStream(ctx, w, a); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// commitTime returns the timestamp of the events committed by tx. Timestamps
+// never decrease, even if the clock goes back, so they are ordered like
+// positions.
+func (s *SQLiteStore) commitTime(ctx context.Context, tx *sql.Tx) (int64, error) {
+	var last int64
+	if err := tx.QueryRowContext(ctx, "SELECT COALESCE(MAX(created), 0) FROM events").Scan(&last); err != nil {
+		return 0, fmt.Errorf("read last commit time: %v", err)
+	}
+	return max(s.now().UnixNano(), last), nil
+}
+
+// appendWriter holds the state shared by the appends of a transaction.
+type appendWriter struct {
+	tx *sql.Tx
+	// linkVersions caches the versions of derived streams linked to.
+	linkVersions map[string]uint64
+	// keys caches the keys of subjects.
+	keys map[string]subjectKey
+	// created is the timestamp of all appended events.
+	created int64
+}
+
+func (s *SQLiteStore) appendStream(ctx context.Context, w *appendWriter, a StreamAppend) (AppendResult, error) {
+	tx, stream, events := w.tx, a.Stream, a.Events
+	version, tombstoned, err := streamVersion(ctx, tx, stream)
+	if err != nil {
+		return AppendResult{}, err
+	}
+	if tombstoned {
+		return AppendResult{}, fmt.Errorf("%q: %w", stream, ErrStreamDeleted)
+	}
+	// Retried appends return the result of the original append, regardless
+	// of the expected version.
+	if orig, ok, err := appended(ctx, tx, stream, events); err != nil || ok {
+		return orig, err
+	}
+	if err := checkExpectedVersion(stream, a.ExpectedVersion, version); err != nil {
+		return AppendResult{}, err
+	}
+
+	res := AppendResult{Version: version, Events: make([]Event, 0, len(events))}
+	for _, ev := range events {
+		res.Version++
+		data, keyID := ev.Data, sql.NullInt64{}
+		if ev.Subject != "" {
+			key, err := s.subjectKey(ctx, tx, w.keys, ev.Subject)
+			if err != nil {
+				return AppendResult{}, err
+			}
+			if data, err = aesgcm.Seal(key.aead, ev.Data); err != nil {
+				return AppendResult{}, fmt.Errorf("encrypt event: %v", err)
+			}
+			keyID = sql.NullInt64{Int64: key.id, Valid: true}
+		}
+		var headers []byte
+		if len(ev.Metadata.Headers) > 0 {
+			if headers, err = json.Marshal(ev.Metadata.Headers); err != nil {
+				return AppendResult{}, fmt.Errorf("encode headers: %v", err)
+			}
+		}
+		r, err := tx.ExecContext(ctx,
+			`INSERT INTO events (stream, version, type, content_type, data, created, subject, subject_key, event_id,
+				correlation_id, causation_id, principal, headers)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			stream, res.Version, ev.Type, ev.ContentType, data, w.created, ev.Subject, keyID, ev.ID,
+			ev.Metadata.CorrelationID, ev.Metadata.CausationID, ev.Metadata.Principal, headers,
+		)
+		var sqlErr sqlite3.Error
+		if errors.As(err, &sqlErr) && sqlErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+			return AppendResult{}, fmt.Errorf("event %x: %w", ev.ID, ErrEventIDConflict)
+		}
+		if err != nil {
+			return AppendResult{}, fmt.Errorf("insert event: %v", err)
+		}
+		pos, err := r.LastInsertId()
+		if err != nil {
+			return AppendResult{}, err
+		}
+		res.Position = uint64(pos)
+		persisted := Event{
+			Stream:      stream,
+			Version:     res.Version,
+			Position:    res.Position,
+			Type:        ev.Type,
+			ContentType: ev.ContentType,
+			Data:        ev.Data,
+			Subject:     ev.Subject,
+			ID:          ev.ID,
+			Metadata:    ev.Metadata,
+			Committed:   time.Unix(0, w.created),
+		}
+		res.Events = append(res.Events, persisted)
+
+		for _, link := range ev.Links {
+			lv, err := s.appendLink(ctx, tx, w.linkVersions, link, persisted.Position)
+			if err != nil {
+				return AppendResult{}, err
+			}
+			linked := persisted
+			linked.Link = &Link{Stream: link, Version: lv}
+			res.Links = append(res.Links, linked)
+		}
+	}
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO streams (stream, version) VALUES (?, ?) ON CONFLICT (stream) DO UPDATE SET version = excluded.version",
+		stream, res.Version,
+	)
+	if err != nil {
+		return AppendResult{}, fmt.Errorf("update stream version: %v", err)
+	}
+	return res, nil
+}
+
+// appended looks up whether events were already appended to stream, by the
+// ID of the first event. If so, ok is set and the result of appending them is
+// returned. Events whose IDs do not match consecutive events of the stream,
+// in order, fail with ErrEventIDConflict.
+func appended(ctx context.Context, tx *sql.Tx, stream string, events []EventData) (res AppendResult, ok bool, err error) {
+	if len(events) == 0 || events[0].ID == nil {
+		return AppendResult{}, false, nil
+	}
+	var (
+		origStream string
+		version    uint64
+	)
+	err = tx.QueryRowContext(ctx, "SELECT stream, version FROM events WHERE event_id = ?", events[0].ID).Scan(&origStream, &version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return AppendResult{}, false, nil
+	}
+	if err != nil {
+		return AppendResult{}, false, fmt.Errorf("read event id: %v", err)
+	}
+	if origStream != stream {
+		return AppendResult{}, false, fmt.Errorf("event %x appended to %q: %w", events[0].ID, origStream, ErrEventIDConflict)
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		"SELECT event_id, version, position FROM events WHERE stream = ? AND version >= ? ORDER BY version LIMIT ?",
+		stream, version, len(events),
+	)
+	if err != nil {
+		return AppendResult{}, false, fmt.Errorf("read appended events: %v", err)
+	}
+	defer rows.Close()
+	var n int
+	for ; rows.Next(); n++ {
+		var id []byte
+		if err := rows.Scan(&id, &res.Version, &res.Position); err != nil {
+			return AppendResult{}, false, err
+		}
+		if !bytes.Equal(id, events[n].ID) {
+			return AppendResult{}, false, fmt.Errorf("event %x: batch differs from original append: %w", events[n].ID, ErrEventIDConflict)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return AppendResult{}, false, err
+	}
+	if n != len(events) {
+		return AppendResult{}, false, fmt.Errorf("batch differs from original append: %w", ErrEventIDConflict)
+	}
+	return res, true, nil
+}
+
+// subjectKey is the data key of a subject, which its events are encrypted
+// with.
+type subjectKey struct {
+	id   int64
+	aead cipher.AEAD
+}
+
+// subjectKey returns the data key of subject, generating it on first use.
+// keys caches the keys used earlier in the transaction.
+func (s *SQLiteStore) subjectKey(ctx context.Context, tx *sql.Tx, keys map[string]subjectKey, subject string) (subjectKey, error) {
+	if key, ok := keys[subject]; ok {
+		return key, nil
+	}
+	var (
+		id  int64
+		raw []byte
+	)
+	err := tx.QueryRowContext(ctx, "SELECT id, key FROM subject_keys WHERE subject = ?", subject).Scan(&id, &raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		raw = make([]byte, 32)
+		if _, err := rand.Read(raw); err != nil {
+			return subjectKey{}, fmt.Errorf("generate subject key: %v", err)
+		}
+		r, err := tx.ExecContext(ctx, "INSERT INTO subject_keys (subject, key) VALUES (?, ?)", subject, raw)
+		if err != nil {
+			return subjectKey{}, fmt.Errorf("insert subject key: %v", err)
+		}
+		if id, err = r.LastInsertId(); err != nil {
+			return subjectKey{}, err
+		}
+	} else if err != nil {
+		return subjectKey{}, fmt.Errorf("read subject key: %v", err)
+	}
+	aead, err := aesgcm.New(raw)
+	if err != nil {
+		return subjectKey{}, err
+	}
+	key := subjectKey{id: id, aead: aead}
+	keys[subject] = key
+	return key, nil
+}
+
+// appendLink links the event at position into the derived stream, returning
+// the version of the link. versions caches the versions of derived streams
+// linked to earlier in the transaction.
+func (s *SQLiteStore) appendLink(ctx context.Context, tx *sql.Tx, versions map[string]uint64, stream string, position uint64) (uint64, error) {
+	if !IsDerived(stream) {
+		return 0, fmt.Errorf("link to %q: %w", stream, ErrInvalidStream)
+	}
+	version, ok := versions[stream]
+	if !ok {
+		// Links are deleted with the events they point to, so the version is
+		// kept apart from them to not be reused after scavenging.
+		row := tx.QueryRowContext(ctx, "SELECT version FROM derived_streams WHERE stream = ?", stream)
+		if err := row.Scan(&version); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("read derived stream version: %v", err)
+		}
+	}
+	version++
+	if _, err := tx.ExecContext(ctx, "INSERT INTO links (stream, version, position) VALUES (?, ?, ?)", stream, version, position); err != nil {
+		return 0, fmt.Errorf("insert link: %v", err)
+	}
+	_, err := tx.ExecContext(ctx,
+		"INSERT INTO derived_streams (stream, version) VALUES (?, ?) ON CONFLICT (stream) DO UPDATE SET version = excluded.version",
+		stream, version,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("update derived stream version: %v", err)
+	}
+	versions[stream] = version
+	return version, nil
+}
+
+func (s *SQLiteStore) ReadStream(ctx context.Context, stream string, from uint64, max int) ([]Event, error) {
+	if IsDerived(stream) {
+		return s.readDerivedStream(ctx, stream, from, max)
+	}
+	var tombstoned bool
+	err := s.db.QueryRowContext(ctx, "SELECT tombstoned FROM streams WHERE stream = ?", stream).Scan(&tombstoned)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("read stream: %v", err)
+	}
+	if tombstoned {
+		return nil, fmt.Errorf("%q: %w", stream, ErrStreamDeleted)
+	}
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+_eventColumns+` FROM events e `+_subjectKeyJoin+`
+		WHERE e.stream = ? AND e.version > ? AND NOT `+_expired+` ORDER BY e.version LIMIT ?`,
+		stream, from, s.now().UnixNano(), max,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("read stream: %v", err)
+	}
+	return scanEvents(rows)
+}
+
+func (s *SQLiteStore) readDerivedStream(ctx context.Context, stream string, from uint64, max int) ([]Event, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+_eventColumns+`, l.version
+		FROM links l JOIN events e ON e.position = l.position `+_subjectKeyJoin+`
+		WHERE l.stream = ? AND l.version > ? AND NOT `+_expired+` ORDER BY l.version LIMIT ?`,
+		stream, from, s.now().UnixNano(), max,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("read derived stream: %v", err)
+	}
+	defer rows.Close()
+	var events []Event
+	for rows.Next() {
+		ev := Event{Link: &Link{Stream: stream}}
+		if err := scanEvent(rows, &ev, &ev.Link.Version); err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+	return events, rows.Err()
+}
+
+func (s *SQLiteStore) ReadAll(ctx context.Context, from uint64, max int) ([]Event, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+_eventColumns+` FROM events e `+_subjectKeyJoin+`
+		WHERE e.position > ? AND NOT `+_expired+` ORDER BY e.position LIMIT ?`,
+		from, s.now().UnixNano(), max,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("read all: %v", err)
+	}
+	return scanEvents(rows)
+}
+
+func (s *SQLiteStore) ReadAllBackwards(ctx context.Context, before uint64, max int) ([]Event, error) {
+	if before == 0 {
+		before = math.MaxInt64
+	}
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+_eventColumns+` FROM events e `+_subjectKeyJoin+`
+		WHERE e.position < ? AND NOT `+_expired+` ORDER BY e.position DESC LIMIT ?`,
+		before, s.now().UnixNano(), max,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("read all backwards: %v", err)
+	}
+	return scanEvents(rows)
+}
+
+func (s *SQLiteStore) PositionAt(ctx context.Context, t time.Time) (uint64, error) {
+	var pos uint64
+	err := s.db.QueryRowContext(ctx,
+		"SELECT position FROM events WHERE created < ? ORDER BY created DESC, position DESC LIMIT 1",
+		t.UnixNano(),
+	).Scan(&pos)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("position at: %v", err)
+	}
+	return pos, nil
+}
+
+func (s *SQLiteStore) WriteSnapshot(ctx context.Context, snap Snapshot) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	version, tombstoned, err := streamVersion(ctx, tx, snap.Stream)
+	if err != nil {
+		return err
+	}
+	if tombstoned {
+		return fmt.Errorf("%q: %w", snap.Stream, ErrStreamDeleted)
+	}
+	if snap.Version == 0 || snap.Version > version {
+		return fmt.Errorf("snapshot at version %d of stream %q at version %d: %w", snap.Version, snap.Stream, version, ErrInvalidSnapshot)
+	}
+
+	// Only replace the stored snapshot, if the new one is more recent
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO snapshots (stream, version, type, content_type, data) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (stream) DO UPDATE SET
+			version = excluded.version,
+			type = excluded.type,
+			content_type = excluded.content_type,
+			data = excluded.data
+		WHERE excluded.version >= snapshots.version`,
+		snap.Stream, snap.Version, snap.Type, snap.ContentType, snap.Data,
+	)
+	if err != nil {
+		return fmt.Errorf("write snapshot: %v", err)
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) ReadSnapshot(ctx context.Context, stream string) (Snapshot, bool, error) {
+	snap := Snapshot{Stream: stream}
+	row := s.db.QueryRowContext(ctx, "SELECT version, type, content_type, data FROM snapshots WHERE stream = ?", stream)
+	err := row.Scan(&snap.Version, &snap.Type, &snap.ContentType, &snap.Data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Snapshot{}, false, nil
+	}
+	if err != nil {
+		return Snapshot{}, false, fmt.Errorf("read snapshot: %v", err)
+	}
+	return snap, true, nil
+}
+
+func (s *SQLiteStore) WriteCheckpoint(ctx context.Context, name string, position uint64) error {
+	_, err := s.db.ExecContext(ctx,
+		"INSERT INTO checkpoints (name, position) VALUES (?, ?) ON CONFLICT (name) DO UPDATE SET position = excluded.position",
+		name, position,
+	)
+	if err != nil {
+		return fmt.Errorf("write checkpoint: %v", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) ReadCheckpoint(ctx context.Context, name string) (uint64, error) {
+	var position uint64
+	err := s.db.QueryRowContext(ctx, "SELECT position FROM checkpoints WHERE name = ?", name).Scan(&position)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("read checkpoint: %v", err)
+	}
+	return position, nil
+}
+
+func (s *SQLiteStore) DeleteStream(ctx context.Context, stream string, expectedVersion int64, hard bool) (uint64, error) {
+	if err := validateStream(stream); err != nil {
+		return 0, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	version, tombstoned, err := streamVersion(ctx, tx, stream)
+	if err != nil {
+		return 0, err
+	}
+	if tombstoned {
+		return 0, fmt.Errorf("%q: %w", stream, ErrStreamDeleted)
+	}
+	if err := checkExpectedVersion(stream, expectedVersion, version); err != nil {
+		return 0, err
+	}
+
+	if hard {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO streams (stream, version, tombstoned) VALUES (?, ?, 1) ON CONFLICT (stream) DO UPDATE SET tombstoned = 1",
+			stream, version,
+		)
+	} else {
+		// A soft delete truncates everything up to the current version, so
+		// appending again continues where the stream left off.
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO stream_metadata (stream, max_age, max_count, truncate_before) VALUES (?, 0, 0, ?)
+			ON CONFLICT (stream) DO UPDATE SET truncate_before = excluded.truncate_before`,
+			stream, version+1,
+		)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("delete stream: %v", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM snapshots WHERE stream = ?", stream); err != nil {
+		return 0, fmt.Errorf("delete snapshot: %v", err)
+	}
+	return version, tx.Commit()
+}
+
+func (s *SQLiteStore) ForgetSubject(ctx context.Context, subject string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM subject_keys WHERE subject = ?", subject); err != nil {
+		return fmt.Errorf("forget subject: %v", err)
+	}
+	// Snapshots are not encrypted and may hold state derived from the
+	// subject's events, so they are rebuilt from the shredded events instead.
+	_, err = tx.ExecContext(ctx,
+		"DELETE FROM snapshots WHERE stream IN (SELECT stream FROM events WHERE subject = ?)",
+		subject,
+	)
+	if err != nil {
+		return fmt.Errorf("delete snapshots: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	// Copy the WAL, which may still hold the key, into the database and
+	// truncate it. This is a no-op for databases not in WAL mode.
+	if _, err := s.db.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return fmt.Errorf("checkpoint wal: %v", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) WriteStreamMetadata(ctx context.Context, stream string, meta StreamMetadata) error {
+	if err := validateStream(stream); err != nil {
+		return err
+	}
+	var custom []byte
+	if len(meta.Custom) > 0 {
+		var err error
+		if custom, err = json.Marshal(meta.Custom); err != nil {
+			return fmt.Errorf("encode custom metadata: %v", err)
+		}
+	}
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO stream_metadata (stream, max_age, max_count, truncate_before, custom) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (stream) DO UPDATE SET
+			max_age = excluded.max_age,
+			max_count = excluded.max_count,
+			truncate_before = MAX(truncate_before, excluded.truncate_before),
+			custom = excluded.custom`,
+		stream, int64(meta.MaxAge), meta.MaxCount, meta.TruncateBefore, custom,
+	)
+	if err != nil {
+		return fmt.Errorf("write stream metadata: %v", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) ReadStreamMetadata(ctx context.Context, stream string) (StreamMetadata, error) {
+	var (
+		meta   StreamMetadata
+		maxAge int64
+		custom []byte
+	)
+	row := s.db.QueryRowContext(ctx, "SELECT max_age, max_count, truncate_before, custom FROM stream_metadata WHERE stream = ?", stream)
+	err := row.Scan(&maxAge, &meta.MaxCount, &meta.TruncateBefore, &custom)
+	if errors.Is(err, sql.ErrNoRows) {
+		return StreamMetadata{}, nil
+	}
+	if err != nil {
+		return StreamMetadata{}, fmt.Errorf("read stream metadata: %v", err)
+	}
+	meta.MaxAge = time.Duration(maxAge)
+	if len(custom) > 0 {
+		if err := json.Unmarshal(custom, &meta.Custom); err != nil {
+			return StreamMetadata{}, fmt.Errorf("decode custom metadata: %v", err)
+		}
+	}
+	return meta, nil
+}
+
+func (s *SQLiteStore) Scavenge(ctx context.Context) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	now := s.now().UnixNano()
+	_, err = tx.ExecContext(ctx,
+		"DELETE FROM links WHERE position IN (SELECT position FROM events e WHERE "+_expired+")",
+		now,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("scavenge links: %v", err)
+	}
+	r, err := tx.ExecContext(ctx, "DELETE FROM events AS e WHERE "+_expired, now)
+	if err != nil {
+		return 0, fmt.Errorf("scavenge events: %v", err)
+	}
+	n, err := r.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func scanEvents(rows *sql.Rows) ([]Event, error) {
+	defer rows.Close()
+	var events []Event
+	for rows.Next() {
+		var ev Event
+		if err := scanEvent(rows, &ev); err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+	return events, rows.Err()
+}
+
+// scanEvent scans the _eventColumns of rows into ev, followed by extra
+// columns into dest. Events of a subject are decrypted with the subject key,
+// and marked as shredded if the subject was forgotten.
+func scanEvent(rows *sql.Rows, ev *Event, dest ...any) error {
+	var (
+		keyID     sql.NullInt64
+		key       []byte
+		headers   []byte
+		committed int64
+	)
+	cols := append([]any{
+		&ev.Position, &ev.Stream, &ev.Version, &ev.Type, &ev.ContentType, &ev.Data, &ev.Subject, &keyID, &key, &ev.ID,
+		&ev.Metadata.CorrelationID, &ev.Metadata.CausationID, &ev.Metadata.Principal, &headers, &committed,
+	}, dest...)
+	if err := rows.Scan(cols...); err != nil {
+		return err
+	}
+	ev.Committed = time.Unix(0, committed)
+	if headers != nil {
+		if err := json.Unmarshal(headers, &ev.Metadata.Headers); err != nil {
+			return fmt.Errorf("decode headers of event %d: %v", ev.Position, err)
+		}
+	}
+	if !keyID.Valid {
+		return nil
+	}
+	if key == nil {
+		ev.Data = nil
+		ev.Shredded = true
+		return nil
+	}
+	aead, err := aesgcm.New(key)
+	if err != nil {
+		return err
+	}
+	if ev.Data, err = aesgcm.Open(aead, ev.Data); err != nil {
+		return fmt.Errorf("decrypt event %d: %v", ev.Position, err)
+	}
+	return nil
+}
+
+// streamVersion reads the current version of stream, which is 0 if it does
+// not exist, and whether it was hard deleted. The version is tracked
+// separately from the events, so it is kept when events are scavenged.
+func streamVersion(ctx context.Context, tx *sql.Tx, stream string) (version uint64, tombstoned bool, err error) {
+	err = tx.QueryRowContext(ctx, "SELECT version, tombstoned FROM streams WHERE stream = ?", stream).Scan(&version, &tombstoned)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("read stream version: %v", err)
+	}
+	return version, tombstoned, nil
+}
+
+// validateStream checks that stream can be appended to by clients. Names
+// starting with "$" are reserved for derived streams.
+func validateStream(stream string) error {
+	if stream == "" || IsDerived(stream) {
+		return fmt.Errorf("%q: %w", stream, ErrInvalidStream)
+	}
+	return nil
+}
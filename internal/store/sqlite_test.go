@@ -0,0 +1,520 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func openTestStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	s, err := OpenSQLite(filepath.Join(t.TempDir(), "events.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestSQLiteStoreAppendRead(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+
+	res, err := s.Append(ctx, "order-1", 0, []EventData{
+		{Type: "OrderPlaced", Data: []byte("a")},
+		{Type: "OrderShipped", Data: []byte("b")},
+	})
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if res.Version != 2 || res.Position != 2 {
+		t.Fatalf("unexpected append result %+v", res)
+	}
+	if _, err := s.Append(ctx, "order-2", AnyVersion, []EventData{{Type: "OrderPlaced"}}); err != nil {
+		t.Fatalf("append other stream: %v", err)
+	}
+
+	events, err := s.ReadStream(ctx, "order-1", 1, 10)
+	if err != nil {
+		t.Fatalf("read stream: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != "OrderShipped" || events[0].Version != 2 {
+		t.Fatalf("unexpected events %+v", events)
+	}
+
+	all, err := s.ReadAll(ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("read all: %v", err)
+	}
+	if len(all) != 3 || all[2].Stream != "order-2" || all[2].Position != 3 {
+		t.Fatalf("unexpected events %+v", all)
+	}
+}
+
+func TestSQLiteStoreWrongExpectedVersion(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+
+	if _, err := s.Append(ctx, "order-1", 0, []EventData{{Type: "OrderPlaced"}}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	_, err := s.Append(ctx, "order-1", 0, []EventData{{Type: "OrderPlaced"}})
+	if !errors.Is(err, ErrWrongExpectedVersion) {
+		t.Fatalf("expected ErrWrongExpectedVersion, got %v", err)
+	}
+	if _, err := s.Append(ctx, "$system", AnyVersion, nil); !errors.Is(err, ErrInvalidStream) {
+		t.Fatalf("expected ErrInvalidStream, got %v", err)
+	}
+}
+
+func TestSQLiteStoreExpectedVersionSentinels(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+
+	var werr *WrongExpectedVersionError
+	_, err := s.Append(ctx, "order-1", StreamExists, []EventData{{Type: "OrderPlaced"}})
+	if !errors.As(err, &werr) || werr.Actual != 0 || werr.Expected != StreamExists {
+		t.Fatalf("expected wrong expected version at 0, got %v", err)
+	}
+	if _, err := s.Append(ctx, "order-1", 0, []EventData{{Type: "OrderPlaced"}, {Type: "ItemAdded"}}); err != nil {
+		t.Fatalf("append to no stream: %v", err)
+	}
+	if _, err := s.Append(ctx, "order-1", StreamExists, []EventData{{Type: "ItemAdded"}}); err != nil {
+		t.Fatalf("append to existing stream: %v", err)
+	}
+	_, err = s.Append(ctx, "order-1", 0, []EventData{{Type: "OrderPlaced"}})
+	if !errors.As(err, &werr) || werr.Actual != 3 || !errors.Is(err, ErrWrongExpectedVersion) {
+		t.Fatalf("expected wrong expected version at 3, got %v", err)
+	}
+	if _, err := s.DeleteStream(ctx, "order-1", 2, false); !errors.As(err, &werr) || werr.Actual != 3 {
+		t.Fatalf("expected wrong expected version on delete, got %v", err)
+	}
+}
+
+func TestSQLiteStoreEventMetadata(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+
+	md := Metadata{CorrelationID: "c", CausationID: "d", Principal: "p", Headers: map[string]string{"k": "v"}}
+	res, err := s.Append(ctx, "order-1", 0, []EventData{{Type: "OrderPlaced", Metadata: md}, {Type: "ItemAdded"}})
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if !reflect.DeepEqual(res.Events[0].Metadata, md) {
+		t.Fatalf("unexpected appended metadata %+v", res.Events[0].Metadata)
+	}
+	events, err := s.ReadStream(ctx, "order-1", 0, 10)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !reflect.DeepEqual(events[0].Metadata, md) || !reflect.DeepEqual(events[1].Metadata, Metadata{}) {
+		t.Fatalf("unexpected metadata %+v, %+v", events[0].Metadata, events[1].Metadata)
+	}
+}
+
+func TestSQLiteStoreSnapshots(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+
+	if _, ok, err := s.ReadSnapshot(ctx, "order-1"); ok || err != nil {
+		t.Fatalf("expected no snapshot, got ok=%v err=%v", ok, err)
+	}
+	if _, err := s.Append(ctx, "order-1", 0, []EventData{{Type: "A"}, {Type: "B"}, {Type: "C"}}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := s.WriteSnapshot(ctx, Snapshot{Stream: "order-1", Version: 4, Type: "S"}); !errors.Is(err, ErrInvalidSnapshot) {
+		t.Fatalf("expected ErrInvalidSnapshot beyond stream version, got %v", err)
+	}
+	if err := s.WriteSnapshot(ctx, Snapshot{Stream: "order-1", Version: 3, Type: "S", Data: []byte("3")}); err != nil {
+		t.Fatalf("write snapshot: %v", err)
+	}
+	// Older snapshots do not replace newer ones
+	if err := s.WriteSnapshot(ctx, Snapshot{Stream: "order-1", Version: 2, Type: "S", Data: []byte("2")}); err != nil {
+		t.Fatalf("write snapshot: %v", err)
+	}
+
+	snap, ok, err := s.ReadSnapshot(ctx, "order-1")
+	if err != nil || !ok {
+		t.Fatalf("read snapshot: ok=%v err=%v", ok, err)
+	}
+	if snap.Version != 3 || string(snap.Data) != "3" {
+		t.Fatalf("unexpected snapshot %+v", snap)
+	}
+}
+
+func TestSQLiteStoreLinks(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+
+	res, err := s.Append(ctx, "order-1", 0, []EventData{
+		{Type: "OrderPlaced", Links: []string{"$ce-order"}},
+	})
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if len(res.Links) != 1 || res.Links[0].Link.Stream != "$ce-order" || res.Links[0].Link.Version != 1 {
+		t.Fatalf("unexpected links %+v", res.Links)
+	}
+	if _, err := s.Append(ctx, "order-2", 0, []EventData{{Type: "OrderPlaced", Links: []string{"$ce-order"}}}); err != nil {
+		t.Fatalf("append other stream: %v", err)
+	}
+	if _, err := s.Append(ctx, "order-3", 0, []EventData{{Type: "OrderPlaced", Links: []string{"ce-order"}}}); !errors.Is(err, ErrInvalidStream) {
+		t.Fatalf("expected ErrInvalidStream for non derived link, got %v", err)
+	}
+
+	events, err := s.ReadStream(ctx, "$ce-order", 1, 10)
+	if err != nil {
+		t.Fatalf("read stream: %v", err)
+	}
+	if len(events) != 1 || events[0].Stream != "order-2" || events[0].Version != 1 || events[0].Seq() != 2 {
+		t.Fatalf("unexpected events %+v", events)
+	}
+	all, err := s.ReadAll(ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("read all: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("expected links to be excluded from all, got %+v", all)
+	}
+}
+
+func TestSQLiteStoreCommitTime(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+	start := time.Unix(1000, 0)
+	now := start
+	s.now = func() time.Time { return now }
+
+	appendAt := func(at time.Time) {
+		t.Helper()
+		now = at
+		if _, err := s.Append(ctx, "order-1", AnyVersion, []EventData{{Type: "ItemAdded"}}); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	appendAt(start)
+	appendAt(start.Add(time.Minute))
+	// The clock going back does not reorder commits
+	appendAt(start.Add(time.Second))
+
+	events, err := s.ReadStream(ctx, "order-1", 0, 10)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	want := []time.Time{start, start.Add(time.Minute), start.Add(time.Minute)}
+	for i, ev := range events {
+		if !ev.Committed.Equal(want[i]) {
+			t.Fatalf("event %d: expected committed at %v, got %v", i, want[i], ev.Committed)
+		}
+	}
+
+	for _, tc := range []struct {
+		at   time.Time
+		want uint64
+	}{
+		{start, 0},
+		{start.Add(time.Second), 1},
+		{start.Add(time.Minute), 1},
+		{start.Add(time.Hour), 3},
+	} {
+		if pos, err := s.PositionAt(ctx, tc.at); err != nil || pos != tc.want {
+			t.Fatalf("position at %v: expected %d, got %d (err %v)", tc.at, tc.want, pos, err)
+		}
+	}
+}
+
+func TestSQLiteStoreStreamMetadata(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	appendN := func(stream string, n int) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			if _, err := s.Append(ctx, stream, AnyVersion, []EventData{{Type: "Measured", Links: []string{"$et-Measured"}}}); err != nil {
+				t.Fatalf("append: %v", err)
+			}
+		}
+	}
+	versions := func(stream string) []uint64 {
+		t.Helper()
+		events, err := s.ReadStream(ctx, stream, 0, 100)
+		if err != nil {
+			t.Fatalf("read stream: %v", err)
+		}
+		var vs []uint64
+		for _, ev := range events {
+			vs = append(vs, ev.Seq())
+		}
+		return vs
+	}
+
+	appendN("telemetry-1", 5)
+	appendN("audit-1", 2)
+	meta := StreamMetadata{MaxCount: 3, TruncateBefore: 2, Custom: map[string]string{"owner": "ops"}}
+	if err := s.WriteStreamMetadata(ctx, "telemetry-1", meta); err != nil {
+		t.Fatalf("write metadata: %v", err)
+	}
+	if got, err := s.ReadStreamMetadata(ctx, "telemetry-1"); err != nil || !reflect.DeepEqual(got, meta) {
+		t.Fatalf("expected metadata %+v, got %+v (err %v)", meta, got, err)
+	}
+	if got := versions("telemetry-1"); !reflect.DeepEqual(got, []uint64{3, 4, 5}) {
+		t.Fatalf("expected versions 3-5, got %v", got)
+	}
+	if got := versions("$et-Measured"); !reflect.DeepEqual(got, []uint64{3, 4, 5, 6, 7}) {
+		t.Fatalf("expected link versions 3-7, got %v", got)
+	}
+
+	// Age out everything appended so far
+	if err := s.WriteStreamMetadata(ctx, "telemetry-1", StreamMetadata{MaxAge: time.Minute}); err != nil {
+		t.Fatalf("write metadata: %v", err)
+	}
+	now = now.Add(2 * time.Minute)
+	appendN("telemetry-1", 1)
+	if got := versions("telemetry-1"); !reflect.DeepEqual(got, []uint64{6}) {
+		t.Fatalf("expected version 6, got %v", got)
+	}
+
+	n, err := s.Scavenge(ctx)
+	if err != nil || n != 5 {
+		t.Fatalf("expected 5 scavenged events, got %d (err %v)", n, err)
+	}
+	all, err := s.ReadAll(ctx, 0, 100)
+	if err != nil || len(all) != 3 {
+		t.Fatalf("expected 3 events left, got %+v (err %v)", all, err)
+	}
+
+	// The stream keeps its version after its events are deleted
+	if _, err := s.Append(ctx, "telemetry-1", 6, []EventData{{Type: "Measured"}}); err != nil {
+		t.Fatalf("append after scavenge: %v", err)
+	}
+}
+
+func TestSQLiteStoreScavengeLinks(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	if err := s.WriteStreamMetadata(ctx, "telemetry-1", StreamMetadata{MaxAge: time.Minute}); err != nil {
+		t.Fatalf("write metadata: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := s.Append(ctx, "telemetry-1", AnyVersion, []EventData{{Type: "Measured", Links: []string{"$ce-telemetry"}}}); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	now = now.Add(2 * time.Minute)
+	if n, err := s.Scavenge(ctx); err != nil || n != 3 {
+		t.Fatalf("expected 3 scavenged events, got %d (err %v)", n, err)
+	}
+
+	// The derived stream keeps its version after its links are deleted
+	res, err := s.Append(ctx, "telemetry-1", AnyVersion, []EventData{{Type: "Measured", Links: []string{"$ce-telemetry"}}})
+	if err != nil {
+		t.Fatalf("append after scavenge: %v", err)
+	}
+	if len(res.Links) != 1 || res.Links[0].Link.Version != 4 {
+		t.Fatalf("expected link version 4, got %+v", res.Links)
+	}
+}
+
+func TestSQLiteStoreDeleteStream(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+
+	for _, stream := range []string{"customer-1", "customer-2"} {
+		if _, err := s.Append(ctx, stream, 0, []EventData{{Type: "Registered"}, {Type: "Moved"}}); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	if _, err := s.DeleteStream(ctx, "customer-1", 1, false); !errors.Is(err, ErrWrongExpectedVersion) {
+		t.Fatalf("expected ErrWrongExpectedVersion, got %v", err)
+	}
+
+	// Soft deleted streams continue at their version
+	if v, err := s.DeleteStream(ctx, "customer-1", 2, false); err != nil || v != 2 {
+		t.Fatalf("expected soft delete at version 2, got %d (err %v)", v, err)
+	}
+	if events, err := s.ReadStream(ctx, "customer-1", 0, 10); err != nil || len(events) != 0 {
+		t.Fatalf("expected no events, got %+v (err %v)", events, err)
+	}
+	// Writing metadata afterwards does not bring the events back
+	if err := s.WriteStreamMetadata(ctx, "customer-1", StreamMetadata{Custom: map[string]string{"owner": "crm"}}); err != nil {
+		t.Fatalf("write metadata: %v", err)
+	}
+	if events, err := s.ReadStream(ctx, "customer-1", 0, 10); err != nil || len(events) != 0 {
+		t.Fatalf("expected no events after writing metadata, got %+v (err %v)", events, err)
+	}
+	if res, err := s.Append(ctx, "customer-1", 2, []EventData{{Type: "Registered"}}); err != nil || res.Version != 3 {
+		t.Fatalf("expected recreated stream at version 3, got %+v (err %v)", res, err)
+	}
+
+	// Hard deleted streams can never be appended to again
+	if _, err := s.DeleteStream(ctx, "customer-2", AnyVersion, true); err != nil {
+		t.Fatalf("hard delete: %v", err)
+	}
+	if _, err := s.Append(ctx, "customer-2", AnyVersion, []EventData{{Type: "Registered"}}); !errors.Is(err, ErrStreamDeleted) {
+		t.Fatalf("expected ErrStreamDeleted on append, got %v", err)
+	}
+	if _, err := s.ReadStream(ctx, "customer-2", 0, 10); !errors.Is(err, ErrStreamDeleted) {
+		t.Fatalf("expected ErrStreamDeleted on read, got %v", err)
+	}
+
+	n, err := s.Scavenge(ctx)
+	if err != nil || n != 4 {
+		t.Fatalf("expected 4 scavenged events, got %d (err %v)", n, err)
+	}
+	all, err := s.ReadAll(ctx, 0, 10)
+	if err != nil || len(all) != 1 || all[0].Stream != "customer-1" || all[0].Version != 3 {
+		t.Fatalf("unexpected events %+v (err %v)", all, err)
+	}
+}
+
+func TestSQLiteStoreForgetSubject(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+
+	_, err := s.Append(ctx, "customer-1", 0, []EventData{
+		{Type: "Registered", Data: []byte(`{"email":"a@example.com"}`), Subject: "customer-1"},
+		{Type: "Upgraded", Data: []byte(`{"plan":"pro"}`)},
+	})
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	var raw []byte
+	if err := s.db.QueryRow("SELECT data FROM events WHERE position = 1").Scan(&raw); err != nil {
+		t.Fatalf("read raw data: %v", err)
+	}
+	if bytes.Contains(raw, []byte("a@example.com")) {
+		t.Fatalf("expected data to be encrypted at rest, got %q", raw)
+	}
+	events, err := s.ReadStream(ctx, "customer-1", 0, 10)
+	if err != nil || len(events) != 2 || string(events[0].Data) != `{"email":"a@example.com"}` || events[0].Subject != "customer-1" {
+		t.Fatalf("unexpected events %+v (err %v)", events, err)
+	}
+
+	if _, err := s.Append(ctx, "customer-2", 0, []EventData{{Type: "Registered"}}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	for _, stream := range []string{"customer-1", "customer-2"} {
+		snap := Snapshot{Stream: stream, Version: 1, Type: "Customer", Data: []byte(`{"email":"a@example.com"}`)}
+		if err := s.WriteSnapshot(ctx, snap); err != nil {
+			t.Fatalf("write snapshot: %v", err)
+		}
+	}
+
+	if err := s.ForgetSubject(ctx, "customer-1"); err != nil {
+		t.Fatalf("forget subject: %v", err)
+	}
+	if _, ok, err := s.ReadSnapshot(ctx, "customer-1"); err != nil || ok {
+		t.Fatalf("expected snapshot of subject's stream to be deleted, got ok %v (err %v)", ok, err)
+	}
+	if _, ok, err := s.ReadSnapshot(ctx, "customer-2"); err != nil || !ok {
+		t.Fatalf("expected other snapshot to be kept, got ok %v (err %v)", ok, err)
+	}
+	events, err = s.ReadStream(ctx, "customer-1", 0, 10)
+	if err != nil || len(events) != 2 {
+		t.Fatalf("unexpected events %+v (err %v)", events, err)
+	}
+	if !events[0].Shredded || events[0].Data != nil {
+		t.Fatalf("expected shredded event, got %+v", events[0])
+	}
+	if events[1].Shredded || string(events[1].Data) != `{"plan":"pro"}` {
+		t.Fatalf("expected event without subject to be intact, got %+v", events[1])
+	}
+
+	// New events of a forgotten subject get a new key
+	if _, err := s.Append(ctx, "customer-1", 2, []EventData{{Type: "Registered", Data: []byte("b"), Subject: "customer-1"}}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	events, err = s.ReadStream(ctx, "customer-1", 2, 10)
+	if err != nil || len(events) != 1 || string(events[0].Data) != "b" {
+		t.Fatalf("unexpected events %+v (err %v)", events, err)
+	}
+}
+
+func TestSQLiteStoreIdempotentAppend(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "events.db")
+	s, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	batch := []EventData{
+		{ID: []byte("0123456789abcdef"), Type: "OrderPlaced"},
+		{ID: []byte("fedcba9876543210"), Type: "ItemAdded"},
+	}
+	orig, err := s.Append(ctx, "order-1", 0, batch)
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	s.Close()
+
+	// Deduplication survives reopening the store
+	s, err = OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("reopen store: %v", err)
+	}
+	defer s.Close()
+	res, err := s.Append(ctx, "order-1", 0, batch)
+	if err != nil {
+		t.Fatalf("retry append: %v", err)
+	}
+	if res.Version != orig.Version || res.Position != orig.Position || len(res.Events) != 0 {
+		t.Fatalf("expected original result %+v, got %+v", orig, res)
+	}
+	events, err := s.ReadStream(ctx, "order-1", 0, 10)
+	if err != nil || len(events) != 2 || !bytes.Equal(events[0].ID, batch[0].ID) {
+		t.Fatalf("unexpected events %+v (err %v)", events, err)
+	}
+
+	for name, retry := range map[string]struct {
+		stream string
+		events []EventData
+	}{
+		"other stream": {"order-2", batch},
+		"reordered":    {"order-1", []EventData{batch[1], batch[0]}},
+		"extended":     {"order-1", append(batch, EventData{ID: []byte("aaaaaaaaaaaaaaaa"), Type: "ItemAdded"})},
+	} {
+		if _, err := s.Append(ctx, retry.stream, AnyVersion, retry.events); !errors.Is(err, ErrEventIDConflict) {
+			t.Errorf("%s: expected ErrEventIDConflict, got %v", name, err)
+		}
+	}
+}
+
+func TestSQLiteStoreAppendMulti(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+
+	if _, err := s.Append(ctx, "account-1", 0, []EventData{{Type: "Opened"}}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	transfer := func(expected int64) ([]AppendResult, error) {
+		return s.AppendMulti(ctx, []StreamAppend{
+			{Stream: "account-1", ExpectedVersion: expected, Events: []EventData{{Type: "Debited"}}},
+			{Stream: "account-2", ExpectedVersion: AnyVersion, Events: []EventData{{Type: "Credited"}, {Type: "Notified"}}},
+		})
+	}
+
+	// A failing append rolls back all appends
+	if _, err := transfer(0); !errors.Is(err, ErrWrongExpectedVersion) {
+		t.Fatalf("expected ErrWrongExpectedVersion, got %v", err)
+	}
+	if events, err := s.ReadStream(ctx, "account-2", 0, 10); err != nil || len(events) != 0 {
+		t.Fatalf("expected rolled back append, got %+v (err %v)", events, err)
+	}
+
+	res, err := transfer(1)
+	if err != nil {
+		t.Fatalf("append multi: %v", err)
+	}
+	if res[0].Version != 2 || res[0].Position != 2 || res[1].Version != 2 || res[1].Position != 4 {
+		t.Fatalf("unexpected results %+v", res)
+	}
+}
@@ -0,0 +1,265 @@
+// Package store persists events appended to streams and reads them back in
+// stream or global order.
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Expected versions with special meaning. Other expected versions are exact
+// versions, where 0 means the stream must not exist.
+const (
+	// AnyVersion is used as expected version when appending regardless of
+	// the current version of the stream.
+	AnyVersion int64 = -1
+	// StreamExists is used as expected version when the stream must exist,
+	// regardless of its version.
+	StreamExists int64 = -2
+)
+
+var (
+	// ErrWrongExpectedVersion is returned when appending to a stream which is
+	// not at the expected version.
+	ErrWrongExpectedVersion = errors.New("wrong expected version")
+	// ErrInvalidStream is returned for stream names which are empty or
+	// reserved for system streams.
+	ErrInvalidStream = errors.New("invalid stream name")
+	// ErrInvalidSnapshot is returned when writing a snapshot at a version the
+	// stream has not reached.
+	ErrInvalidSnapshot = errors.New("invalid snapshot")
+	// ErrStreamDeleted is returned when accessing a stream which was hard
+	// deleted.
+	ErrStreamDeleted = errors.New("stream deleted")
+	// ErrEventIDConflict is returned when appending events with IDs which
+	// were already appended, but not in the same order to the same stream.
+	ErrEventIDConflict = errors.New("event id conflict")
+)
+
+// WrongExpectedVersionError is returned when a stream is not at the expected
+// version. It wraps ErrWrongExpectedVersion.
+type WrongExpectedVersionError struct {
+	Stream   string
+	Expected int64
+	// Actual is the current version of the stream, 0 if it does not exist.
+	Actual uint64
+}
+
+func (e *WrongExpectedVersionError) Error() string {
+	var expected string
+	switch e.Expected {
+	case StreamExists:
+		expected = "stream to exist"
+	case 0:
+		expected = "no stream"
+	default:
+		expected = fmt.Sprintf("version %d", e.Expected)
+	}
+	return fmt.Sprintf("stream %q at version %d, expected %s: %v", e.Stream, e.Actual, expected, ErrWrongExpectedVersion)
+}
+
+func (e *WrongExpectedVersionError) Unwrap() error {
+	return ErrWrongExpectedVersion
+}
+
+// checkExpectedVersion returns a *WrongExpectedVersionError unless version
+// satisfies expected.
+func checkExpectedVersion(stream string, expected int64, version uint64) error {
+	switch {
+	case expected == AnyVersion:
+	case expected == StreamExists && version > 0:
+	case expected >= 0 && uint64(expected) == version:
+	default:
+		return &WrongExpectedVersionError{Stream: stream, Expected: expected, Actual: version}
+	}
+	return nil
+}
+
+// EventData is an event to be appended to a stream.
+type EventData struct {
+	// ID optionally identifies the event. Appending a batch of events whose
+	// IDs were already appended to the stream returns the result of the
+	// original append instead of appending them again.
+	ID          []byte
+	Type        string
+	ContentType string
+	Data        []byte
+	// Links holds the derived streams the event is linked to, in addition
+	// to being appended to its own stream.
+	Links []string
+	// Subject identifies the data subject, such as a customer, the event
+	// holds personal data of. The data of events with a subject is encrypted
+	// with a key of the subject.
+	Subject  string
+	Metadata Metadata
+}
+
+// Metadata describes the context an event was appended in.
+type Metadata struct {
+	// CorrelationID identifies the chain of events the event is part of.
+	CorrelationID string
+	// CausationID identifies the event that caused the event.
+	CausationID string
+	// Principal identifies who appended the event.
+	Principal string
+	Headers   map[string]string
+}
+
+// Link identifies a link to an event in a derived stream.
+type Link struct {
+	Stream  string
+	Version uint64
+}
+
+// Event is an event persisted in a stream.
+type Event struct {
+	Stream string
+	// Version of the stream this event resulted in, starting at 1 for the
+	// first event of the stream.
+	Version uint64
+	// Position of the event in the global log of all streams, starting at 1.
+	Position    uint64
+	Type        string
+	ContentType string
+	Data        []byte
+	// Link is set when the event was read through a link in a derived
+	// stream, in which case the other fields describe the linked event.
+	Link *Link
+	// Subject is the data subject of the event, if any.
+	Subject string
+	// ID is the ID the event was appended with, if any.
+	ID []byte
+	// Shredded is set when the subject was forgotten, in which case Data is
+	// nil.
+	Shredded bool
+	Metadata Metadata
+	// Committed is the time the event was committed. Commit times increase
+	// with positions.
+	Committed time.Time
+}
+
+// Seq returns the version of ev in the stream it was read from. For events
+// read through a link, this is the version of the link.
+func (ev Event) Seq() uint64 {
+	if ev.Link != nil {
+		return ev.Link.Version
+	}
+	return ev.Version
+}
+
+// IsDerived reports whether stream is a derived stream, maintained by the
+// server from links to events of other streams.
+func IsDerived(stream string) bool {
+	return strings.HasPrefix(stream, "$")
+}
+
+// AppendResult describes the outcome of a successful append.
+type AppendResult struct {
+	// Version of the stream after the append.
+	Version uint64
+	// Position of the last appended event.
+	Position uint64
+	// Events holds the appended events as persisted.
+	Events []Event
+	// Links holds the appended events as read through the links created in
+	// derived streams.
+	Links []Event
+}
+
+// StreamAppend is the append of events to a single stream, as part of a
+// transaction spanning multiple streams.
+type StreamAppend struct {
+	Stream          string
+	ExpectedVersion int64
+	Events          []EventData
+}
+
+// Snapshot is the state of an aggregate at a version of its stream, saving
+// the need to read the events up to that version.
+type Snapshot struct {
+	Stream      string
+	Version     uint64
+	Type        string
+	ContentType string
+	Data        []byte
+}
+
+// StreamMetadata controls which events of a stream are kept. Events hidden
+// by the metadata are not returned by reads, and are deleted from storage by
+// Scavenge. Zero values mean no limit.
+type StreamMetadata struct {
+	// MaxAge hides events appended longer ago than MaxAge.
+	MaxAge time.Duration
+	// MaxCount hides all but the last MaxCount events.
+	MaxCount uint64
+	// TruncateBefore hides events with a version less than TruncateBefore.
+	// It is never lowered, as soft deleting a stream raises it.
+	TruncateBefore uint64
+	// Custom holds application defined key/values.
+	Custom map[string]string
+}
+
+type Store interface {
+	// Append adds events to the end of stream. The stream is created when it
+	// does not exist yet. The append fails with a *WrongExpectedVersionError
+	// unless the stream is at expectedVersion, which is either an exact
+	// version, AnyVersion or StreamExists. Events carrying IDs
+	// are deduplicated, see EventData.ID.
+	Append(ctx context.Context, stream string, expectedVersion int64, events []EventData) (AppendResult, error)
+	// AppendMulti performs appends to several streams atomically. Either all
+	// appends succeed, or none of them are persisted. Events are assigned
+	// consecutive positions, in the order of appends.
+	AppendMulti(ctx context.Context, appends []StreamAppend) ([]AppendResult, error)
+	// ReadStream reads at most max events of stream with a version greater
+	// than from, in ascending order. Links of derived streams are resolved
+	// to the events they point to. Events hidden by the metadata of their
+	// stream are skipped.
+	ReadStream(ctx context.Context, stream string, from uint64, max int) ([]Event, error)
+	// ReadAll reads at most max events of all streams with a position
+	// greater than from, in ascending order, skipping events hidden by the
+	// metadata of their stream.
+	ReadAll(ctx context.Context, from uint64, max int) ([]Event, error)
+	// ReadAllBackwards is like ReadAll, but reads events with a position less
+	// than before in descending order. A before of 0 reads from the end.
+	ReadAllBackwards(ctx context.Context, before uint64, max int) ([]Event, error)
+	// PositionAt returns the position of the last event committed before t,
+	// or 0 if there is none. Reading up to the returned position reads the
+	// log as it was at t.
+	PositionAt(ctx context.Context, t time.Time) (uint64, error)
+	// WriteSnapshot stores snap as the latest snapshot of its stream, unless
+	// a snapshot at a later version is already stored.
+	WriteSnapshot(ctx context.Context, snap Snapshot) error
+	// ReadSnapshot reads the latest snapshot of stream. ok is false when no
+	// snapshot has been written.
+	ReadSnapshot(ctx context.Context, stream string) (snap Snapshot, ok bool, err error)
+	// WriteCheckpoint stores the position up to which the checkpoint name has
+	// processed events, replacing the previous position.
+	WriteCheckpoint(ctx context.Context, name string, position uint64) error
+	// ReadCheckpoint reads the position of the checkpoint name, which is 0
+	// when none was written.
+	ReadCheckpoint(ctx context.Context, name string) (uint64, error)
+	// DeleteStream deletes stream, if it is at expectedVersion, returning the
+	// version it was deleted at. A soft delete truncates the stream, which
+	// can be appended to again with continuing versions. A hard delete leaves
+	// a tombstone, failing later appends and reads with ErrStreamDeleted.
+	// The events of deleted streams are removed from storage by Scavenge.
+	DeleteStream(ctx context.Context, stream string, expectedVersion int64, hard bool) (uint64, error)
+	// ForgetSubject destroys the key of subject, so the data of its events
+	// can no longer be read. Events appended for the subject afterwards are
+	// encrypted with a new key. The snapshots of streams with events of the
+	// subject are deleted.
+	ForgetSubject(ctx context.Context, subject string) error
+	// WriteStreamMetadata replaces the metadata of stream, except for
+	// lowering TruncateBefore.
+	WriteStreamMetadata(ctx context.Context, stream string, meta StreamMetadata) error
+	// ReadStreamMetadata reads the metadata of stream, which is the zero
+	// value when none was written.
+	ReadStreamMetadata(ctx context.Context, stream string) (StreamMetadata, error)
+	// Scavenge deletes the events hidden by stream metadata from storage,
+	// returning the number of deleted events.
+	Scavenge(ctx context.Context) (int64, error)
+	Close() error
+}
@@ -5,16 +5,17 @@ import (
 	"github.com/nohns/eventale/internal"
 )
 
-type uuidImpl struct {
-	id uuid.UUID
-}
+// UUID is an ID held in place. Its methods have pointer receivers, so an ID
+// can be stored in a larger struct, like a decoded frame, and be used as an
+// internal.ID without allocating.
+type UUID [16]byte
 
-func (impl uuidImpl) String() string {
-	return impl.String()
+func (id *UUID) String() string {
+	return uuid.UUID(*id).String()
 }
 
-func (impl uuidImpl) Bytes() []byte {
-	return impl.id[:]
+func (id *UUID) Bytes() []byte {
+	return id[:]
 }
 
 type ider func() (internal.ID, error)
@@ -28,7 +29,25 @@ func Gen() (internal.ID, error) {
 	if err != nil {
 		return nil, err
 	}
-	return uuidImpl{id: id}, nil
+	return (*UUID)(&id), nil
+}
+
+// FromBytes parses a 16 byte UUID, as returned from ID.Bytes().
+func FromBytes(b []byte) (internal.ID, error) {
+	id, err := uuid.FromBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	return (*UUID)(&id), nil
+}
+
+// Parse parses a UUID in its string form, as returned from ID.String().
+func Parse(s string) (internal.ID, error) {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	return (*UUID)(&id), nil
 }
 
 var IDer internal.IDer = ider(func() (internal.ID, error) {
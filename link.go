@@ -0,0 +1,65 @@
+package eventale
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Prefixes of the derived streams maintained for all events. An event
+// appended to "order-1" with type "OrderPlaced" is linked to "$ce-order" and
+// "$et-OrderPlaced".
+const (
+	CategoryStreamPrefix  = "$ce-"
+	EventTypeStreamPrefix = "$et-"
+)
+
+// LinkRule links events matching it into a derived stream. Empty criteria
+// match all events, but at least one must be set.
+type LinkRule struct {
+	// Stream is the derived stream to link to. It must start with "$".
+	Stream string `json:"stream"`
+	// StreamPrefix matches events appended to streams starting with it.
+	StreamPrefix string `json:"streamPrefix,omitempty"`
+	// EventType matches events of exactly this type.
+	EventType string `json:"eventType,omitempty"`
+}
+
+func (r LinkRule) validate() error {
+	if !strings.HasPrefix(r.Stream, "$") {
+		return fmt.Errorf("link rule stream %q must start with \"$\"", r.Stream)
+	}
+	if strings.HasPrefix(r.Stream, CategoryStreamPrefix) || strings.HasPrefix(r.Stream, EventTypeStreamPrefix) || r.Stream == AllStreams {
+		return fmt.Errorf("link rule stream %q is reserved", r.Stream)
+	}
+	if r.StreamPrefix == "" && r.EventType == "" {
+		return fmt.Errorf("link rule for %q matches all events", r.Stream)
+	}
+	return nil
+}
+
+func (r LinkRule) matches(stream, eventType string) bool {
+	if r.StreamPrefix != "" && !strings.HasPrefix(stream, r.StreamPrefix) {
+		return false
+	}
+	if r.EventType != "" && r.EventType != eventType {
+		return false
+	}
+	return true
+}
+
+// linksFor returns the derived streams an event of eventType appended to
+// stream is linked to. The category of a stream is the part before the first
+// "-", and streams without one have no category.
+func (s *Server) linksFor(stream, eventType string) []string {
+	var links []string
+	if category, _, ok := strings.Cut(stream, "-"); ok && category != "" {
+		links = append(links, CategoryStreamPrefix+category)
+	}
+	links = append(links, EventTypeStreamPrefix+eventType)
+	for _, rule := range s.LinkRules {
+		if rule.matches(stream, eventType) {
+			links = append(links, rule.Stream)
+		}
+	}
+	return links
+}
@@ -0,0 +1,73 @@
+package eventale_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/nohns/eventale"
+)
+
+func TestDerivedStreams(t *testing.T) {
+	ctx := context.Background()
+	lnr, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := eventale.NewServer(lnr.Addr().String())
+	srv.Logger = discardLogger
+	srv.LinkRules = []eventale.LinkRule{{Stream: "$big-orders", StreamPrefix: "order-big"}}
+	go srv.Serve(lnr)
+	t.Cleanup(func() { srv.Close() })
+	c := orderClient(t, lnr.Addr().String())
+
+	sub, err := c.Subscribe(ctx, "$ce-order")
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	c.Append(ctx, "order-1", 0, &orderPlaced{OrderID: "1"}, &itemAdded{SKU: "a"})
+	c.Append(ctx, "customer-1", 0, &itemAdded{SKU: "b"})
+	c.Append(ctx, "order-big-2", 0, &orderPlaced{OrderID: "2"})
+
+	events, err := c.ReadStream(ctx, "$ce-order")
+	if err != nil {
+		t.Fatalf("read category: %v", err)
+	}
+	if len(events) != 3 || events[2].Stream != "order-big-2" || events[2].Link.Version != 3 {
+		t.Fatalf("unexpected category events %+v", events)
+	}
+	if _, ok := events[0].Value.(*orderPlaced); !ok {
+		t.Fatalf("expected linked event to be decoded, got %T", events[0].Value)
+	}
+
+	events, err = c.ReadStream(ctx, "$et-ItemAdded", eventale.FromVersion(1))
+	if err != nil {
+		t.Fatalf("read event type: %v", err)
+	}
+	if len(events) != 1 || events[0].Stream != "customer-1" {
+		t.Fatalf("unexpected event type events %+v", events)
+	}
+
+	events, err = c.ReadStream(ctx, "$big-orders")
+	if err != nil {
+		t.Fatalf("read rule stream: %v", err)
+	}
+	if len(events) != 1 || events[0].Stream != "order-big-2" {
+		t.Fatalf("unexpected rule events %+v", events)
+	}
+
+	rctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	for _, want := range []string{"order-1", "order-1", "order-big-2"} {
+		ev, err := sub.Recv(rctx)
+		if err != nil {
+			t.Fatalf("recv: %v", err)
+		}
+		if ev.Stream != want || ev.Link == nil || ev.Link.Stream != "$ce-order" {
+			t.Fatalf("expected linked event of %s, got %+v", want, ev)
+		}
+	}
+}
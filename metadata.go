@@ -0,0 +1,62 @@
+package eventale
+
+import (
+	"context"
+	"time"
+
+	eventalepb "github.com/nohns/eventale/gen/v1"
+	"github.com/nohns/eventale/internal/frame"
+	"github.com/nohns/eventale/internal/uuid"
+)
+
+// StreamMetadata controls how long the events of a stream are kept. Expired
+// events are skipped by reads and subscriptions, and are eventually deleted
+// by the server. Zero values mean no limit, so streams without metadata are
+// kept forever.
+type StreamMetadata struct {
+	// MaxAge expires events appended longer ago than MaxAge. It has
+	// millisecond precision.
+	MaxAge time.Duration
+	// MaxCount expires all but the last MaxCount events.
+	MaxCount uint64
+	// TruncateBefore expires events with a version less than TruncateBefore.
+	// Setting a lower value than the current one, which deleting the stream
+	// raises, has no effect.
+	TruncateBefore uint64
+	// Custom holds application defined key/values.
+	Custom map[string]string
+}
+
+// SetStreamMetadata replaces the metadata of stream. Metadata can be set
+// before the stream is created.
+func (c *Client) SetStreamMetadata(ctx context.Context, stream string, meta StreamMetadata) error {
+	req := &eventalepb.WireStreamMetadata{
+		Stream:         stream,
+		MaxAge:         uint64(meta.MaxAge / time.Millisecond),
+		MaxCount:       meta.MaxCount,
+		TruncateBefore: meta.TruncateBefore,
+		Custom:         meta.Custom,
+	}
+	frm, err := frame.Make(frame.FrameKindWriteStreamMetadata, frame.WithID(uuid.IDer), frame.WithProto(req))
+	if err != nil {
+		return err
+	}
+	_, err = c.roundtrip(ctx, frm)
+	return err
+}
+
+// ReadStreamMetadata reads the metadata of stream, which is the zero value
+// when none was set.
+func (c *Client) ReadStreamMetadata(ctx context.Context, stream string) (StreamMetadata, error) {
+	var res eventalepb.WireStreamMetadata
+	req := &eventalepb.WireReadStreamMetadataRequest{Stream: stream}
+	if err := c.call(ctx, frame.FrameKindReadStreamMetadata, req, frame.FrameKindReadStreamMetadataResult, &res); err != nil {
+		return StreamMetadata{}, err
+	}
+	return StreamMetadata{
+		MaxAge:         time.Duration(res.MaxAge) * time.Millisecond,
+		MaxCount:       res.MaxCount,
+		TruncateBefore: res.TruncateBefore,
+		Custom:         res.Custom,
+	}, nil
+}
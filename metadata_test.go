@@ -0,0 +1,50 @@
+package eventale_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nohns/eventale"
+)
+
+func TestStreamMetadata(t *testing.T) {
+	ctx := context.Background()
+	_, addr := startServer(t)
+	c := orderClient(t, addr)
+
+	if meta, err := c.ReadStreamMetadata(ctx, "telemetry-1"); err != nil || !reflect.DeepEqual(meta, eventale.StreamMetadata{}) {
+		t.Fatalf("expected no metadata, got %+v (err %v)", meta, err)
+	}
+	meta := eventale.StreamMetadata{MaxAge: time.Hour, MaxCount: 2, Custom: map[string]string{"kind": "telemetry"}}
+	if err := c.SetStreamMetadata(ctx, "telemetry-1", meta); err != nil {
+		t.Fatalf("set metadata: %v", err)
+	}
+	if got, err := c.ReadStreamMetadata(ctx, "telemetry-1"); err != nil || !reflect.DeepEqual(got, meta) {
+		t.Fatalf("expected metadata %+v, got %+v (err %v)", meta, got, err)
+	}
+
+	c.Append(ctx, "telemetry-1", 0, &itemAdded{SKU: "a"}, &itemAdded{SKU: "b"}, &itemAdded{SKU: "c"})
+	events, err := c.ReadStream(ctx, "telemetry-1")
+	if err != nil {
+		t.Fatalf("read stream: %v", err)
+	}
+	if len(events) != 2 || events[0].Version != 2 {
+		t.Fatalf("expected versions 2-3, got %+v", events)
+	}
+
+	sub, err := c.Subscribe(ctx, "telemetry-1", eventale.After(0))
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	defer sub.Close()
+	rctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	for _, want := range []uint64{2, 3} {
+		ev, err := sub.Recv(rctx)
+		if err != nil || ev.Version != want {
+			t.Fatalf("expected version %d, got %+v (err %v)", want, ev, err)
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package eventale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// _defaultLeaseTTL is how long a runner holds the lease of its consumer group
+// without renewing it.
+const _defaultLeaseTTL = 10 * time.Second
+
+// EventHandler handles a single event of a projection. Events appended with
+// ctx are caused by ev, see CausedBy.
+type EventHandler func(ctx context.Context, ev *Event) error
+
+// Projection builds a read model from the events of all streams, by calling
+// the handler registered for the type of each event.
+type Projection struct {
+	// Name identifies the projection, and is used as the name of its
+	// checkpoint.
+	Name string
+
+	handlers  map[string]EventHandler
+	reset     func(ctx context.Context) error
+	processed func(ctx context.Context, ev *Event) (bool, error)
+}
+
+func NewProjection(name string) *Projection {
+	return &Projection{
+		Name:     name,
+		handlers: make(map[string]EventHandler),
+	}
+}
+
+// Handle registers fn as handler of events of eventType. Events of types
+// without a handler are skipped.
+func (p *Projection) Handle(eventType string, fn EventHandler) *Projection {
+	p.handlers[eventType] = fn
+	return p
+}
+
+// OnReset registers fn to clear the read model before the projection is
+// rebuilt from the first event.
+func (p *Projection) OnReset(fn func(ctx context.Context) error) *Projection {
+	p.reset = fn
+	return p
+}
+
+// Idempotent registers fn to tell whether an event was already processed.
+// Events are delivered at least once, so an event handled right before a
+// crash, but after the last saved checkpoint, is delivered again on restart.
+// Events for which fn returns true are skipped.
+func (p *Projection) Idempotent(fn func(ctx context.Context, ev *Event) (bool, error)) *Projection {
+	p.processed = fn
+	return p
+}
+
+// handle runs the handler for ev, if any. Events appended by the handler are
+// caused by ev.
+func (p *Projection) handle(ctx context.Context, ev *Event) error {
+	fn, ok := p.handlers[ev.Type]
+	if !ok {
+		return nil
+	}
+	if p.processed != nil {
+		done, err := p.processed(ctx, ev)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+	}
+	return fn(CausedBy(ctx, ev), ev)
+}
+
+// ProjectionRunner runs a projection against a catch-up subscription to all
+// streams, continuing from the last saved checkpoint.
+type ProjectionRunner struct {
+	client          *Client
+	projection      *Projection
+	checkpoints     CheckpointStore
+	checkpointEvery int
+	leaseTTL        time.Duration
+}
+
+func NewProjectionRunner(c *Client, p *Projection, checkpoints CheckpointStore, options ...runnerOpt) *ProjectionRunner {
+	opts := runnerOpts{checkpointEvery: 1, leaseTTL: _defaultLeaseTTL}
+	for _, opt := range options {
+		opt.apply(&opts)
+	}
+	return &ProjectionRunner{
+		client:          c,
+		projection:      p,
+		checkpoints:     checkpoints,
+		checkpointEvery: opts.checkpointEvery,
+		leaseTTL:        opts.leaseTTL,
+	}
+}
+
+// Run processes events until ctx is done or a handler fails. A checkpoint is
+// saved every CheckpointEvery events, by default after each event, and when
+// stopping. Run returns nil when stopped by cancelling ctx.
+//
+// When the checkpoint store is a CheckpointLeaser, the runner processes
+// events only while holding the lease of its consumer group, and otherwise
+// waits to take over from the member holding it.
+func (r *ProjectionRunner) Run(ctx context.Context) error {
+	return r.run(ctx, false)
+}
+
+// Rebuild resets the read model and checkpoint of the projection, and runs it
+// from the first event. In a consumer group, the reset waits for the lease.
+func (r *ProjectionRunner) Rebuild(ctx context.Context) error {
+	return r.run(ctx, true)
+}
+
+func (r *ProjectionRunner) run(ctx context.Context, rebuild bool) error {
+	leaser, ok := r.checkpoints.(CheckpointLeaser)
+	if !ok {
+		if rebuild {
+			if err := r.reset(ctx); err != nil {
+				return err
+			}
+		}
+		return r.process(ctx)
+	}
+
+	name := r.projection.Name
+	for {
+		held, err := leaser.AcquireLease(ctx, name, r.leaseTTL)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("projection %s: %w", name, err)
+		}
+		if !held {
+			select {
+			case <-time.After(r.leaseTTL / 3):
+				continue
+			case <-ctx.Done():
+				return nil
+			}
+		}
+
+		lost, err := r.processLeased(ctx, leaser, rebuild)
+		if ctx.Err() != nil {
+			if rerr := leaser.ReleaseLease(context.WithoutCancel(ctx), name); rerr != nil {
+				r.client.logger.Warn("Failed to release lease", slog.String("projection", name), slog.String("error", rerr.Error()))
+			}
+			return err
+		}
+		if !lost {
+			return err
+		}
+		r.client.logger.Warn("Lost lease of consumer group", slog.String("projection", name))
+		rebuild = false
+	}
+}
+
+// processLeased processes events while the lease of the consumer group is
+// renewed, and reports whether it stopped because the lease was lost. The
+// read model is reset first when rebuild is set.
+func (r *ProjectionRunner) processLeased(ctx context.Context, leaser CheckpointLeaser, rebuild bool) (bool, error) {
+	lctx, cancel := context.WithCancel(ctx)
+	lost := make(chan struct{})
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		ticker := time.NewTicker(r.leaseTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-lctx.Done():
+				return
+			}
+			held, err := leaser.AcquireLease(lctx, r.projection.Name, r.leaseTTL)
+			if lctx.Err() != nil {
+				return
+			}
+			if err != nil || !held {
+				close(lost)
+				cancel()
+				return
+			}
+		}
+	}()
+	// Wait for renewals to stop, so none outlives a release of the lease
+	defer func() {
+		cancel()
+		<-renewed
+	}()
+
+	if rebuild {
+		if err := r.reset(lctx); err != nil {
+			return false, err
+		}
+	}
+	err := r.process(lctx)
+	select {
+	case <-lost:
+		return true, err
+	default:
+		return false, err
+	}
+}
+
+// reset clears the read model and checkpoint of the projection.
+func (r *ProjectionRunner) reset(ctx context.Context) error {
+	if r.projection.reset != nil {
+		if err := r.projection.reset(ctx); err != nil {
+			return fmt.Errorf("projection %s: reset: %w", r.projection.Name, err)
+		}
+	}
+	return r.checkpoints.SaveCheckpoint(ctx, r.projection.Name, 0)
+}
+
+// process runs the projection from the saved checkpoint until ctx is done.
+func (r *ProjectionRunner) process(ctx context.Context) error {
+	name := r.projection.Name
+	pos, err := r.checkpoints.LoadCheckpoint(ctx, name)
+	if err != nil {
+		return err
+	}
+	sub, err := r.client.Subscribe(ctx, AllStreams, After(pos))
+	if err != nil {
+		return fmt.Errorf("projection %s: %w", name, err)
+	}
+	defer sub.Close()
+
+	saved := pos
+	save := func(ctx context.Context) error {
+		if pos == saved {
+			return nil
+		}
+		if err := r.checkpoints.SaveCheckpoint(ctx, name, pos); err != nil {
+			return err
+		}
+		saved = pos
+		return nil
+	}
+
+	var unsaved int
+	for {
+		ev, err := sub.Recv(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				return fmt.Errorf("projection %s: %w", name, err)
+			}
+			// Save progress on graceful stop, using a fresh context as ctx
+			// is already done.
+			if err := save(context.WithoutCancel(ctx)); err != nil {
+				return fmt.Errorf("projection %s: %w", name, err)
+			}
+			return nil
+		}
+
+		if err := r.projection.handle(ctx, ev); err != nil {
+			if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+				continue
+			}
+			return fmt.Errorf("projection %s: handle event %d: %w", name, ev.Position, err)
+		}
+		// Notifications such as StreamDeleted have no position
+		if ev.Position == 0 {
+			continue
+		}
+		pos = ev.Position
+		unsaved++
+		if unsaved >= r.checkpointEvery {
+			if err := save(ctx); err != nil {
+				r.client.logger.Warn("Failed to save checkpoint", slog.String("projection", name), slog.String("error", err.Error()))
+				continue
+			}
+			unsaved = 0
+		}
+	}
+}
+
+// CheckpointEvery makes the runner save the checkpoint after every n events
+// instead of after each event.
+func CheckpointEvery(n int) runnerOpt {
+	return runnerOptFunc(func(opts *runnerOpts) {
+		if n > 0 {
+			opts.checkpointEvery = n
+		}
+	})
+}
+
+// LeaseTTL sets how long a runner in a consumer group holds the lease without
+// renewing it, which bounds how long the group stalls when its holder dies.
+// The lease is renewed every third of d. Defaults to 10 seconds.
+func LeaseTTL(d time.Duration) runnerOpt {
+	return runnerOptFunc(func(opts *runnerOpts) {
+		if d >= 3*time.Millisecond {
+			opts.leaseTTL = d
+		}
+	})
+}
+
+type runnerOpts struct {
+	checkpointEvery int
+	leaseTTL        time.Duration
+}
+
+type runnerOpt interface {
+	apply(opts *runnerOpts)
+}
+
+type runnerOptFunc func(opts *runnerOpts)
+
+func (f runnerOptFunc) apply(opts *runnerOpts) {
+	f(opts)
+}
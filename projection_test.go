@@ -0,0 +1,174 @@
+package eventale_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/nohns/eventale"
+)
+
+func TestProjectionRunner(t *testing.T) {
+	ctx := context.Background()
+	_, addr := startServer(t)
+	c := orderClient(t, addr)
+	checkpoints := eventale.FileCheckpointStore{Dir: t.TempDir()}
+
+	handled := make(chan string, 10)
+	proj := eventale.NewProjection("orders").
+		Handle("OrderPlaced", func(ctx context.Context, ev *eventale.Event) error {
+			handled <- ev.Value.(*orderPlaced).OrderID
+			return nil
+		})
+
+	run := func() (stop func() error) {
+		ctx, cancel := context.WithCancel(ctx)
+		errc := make(chan error, 1)
+		go func() { errc <- eventale.NewProjectionRunner(c, proj, checkpoints).Run(ctx) }()
+		return func() error {
+			cancel()
+			return <-errc
+		}
+	}
+	expect := func(want string) {
+		t.Helper()
+		select {
+		case got := <-handled:
+			if got != want {
+				t.Fatalf("expected order %s handled, got %s", want, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for order %s", want)
+		}
+	}
+
+	c.Append(ctx, "order-1", 0, &orderPlaced{OrderID: "1"}, &itemAdded{SKU: "a"})
+	stop := run()
+	expect("1")
+	c.Append(ctx, "order-2", 0, &orderPlaced{OrderID: "2"})
+	expect("2")
+	if err := stop(); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	pos, err := checkpoints.LoadCheckpoint(ctx, "orders")
+	if err != nil || pos != 3 {
+		t.Fatalf("expected checkpoint 3, got %d (err %v)", pos, err)
+	}
+
+	// Restarting continues after the checkpoint
+	c.Append(ctx, "order-3", 0, &orderPlaced{OrderID: "3"})
+	stop = run()
+	expect("3")
+	if err := stop(); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+}
+
+func TestCheckpointStores(t *testing.T) {
+	ctx := context.Background()
+	_, addr := startServer(t)
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	sqlStore, err := eventale.NewSQLiteCheckpointStore(db)
+	if err != nil {
+		t.Fatalf("new sqlite store: %v", err)
+	}
+
+	for name, cs := range map[string]eventale.CheckpointStore{
+		"file":   eventale.FileCheckpointStore{Dir: t.TempDir()},
+		"sqlite": sqlStore,
+		"server": dial(t, addr).Checkpoints(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if pos, err := cs.LoadCheckpoint(ctx, "p"); err != nil || pos != 0 {
+				t.Fatalf("expected no checkpoint, got %d (err %v)", pos, err)
+			}
+			for _, want := range []uint64{5, 7} {
+				if err := cs.SaveCheckpoint(ctx, "p", want); err != nil {
+					t.Fatalf("save: %v", err)
+				}
+				if pos, err := cs.LoadCheckpoint(ctx, "p"); err != nil || pos != want {
+					t.Fatalf("expected checkpoint %d, got %d (err %v)", want, pos, err)
+				}
+			}
+		})
+	}
+}
+
+func TestConsumerGroup(t *testing.T) {
+	ctx := context.Background()
+	_, addr := startServer(t)
+	c := orderClient(t, addr)
+
+	type handledBy struct{ member, order string }
+	handled := make(chan handledBy, 10)
+	run := func(member string) (stop func() error) {
+		proj := eventale.NewProjection("orders").
+			Handle("OrderPlaced", func(ctx context.Context, ev *eventale.Event) error {
+				handled <- handledBy{member, ev.Value.(*orderPlaced).OrderID}
+				return nil
+			})
+		runner := eventale.NewProjectionRunner(c, proj, c.ConsumerGroup(member), eventale.LeaseTTL(300*time.Millisecond))
+		ctx, cancel := context.WithCancel(ctx)
+		errc := make(chan error, 1)
+		go func() { errc <- runner.Run(ctx) }()
+		return func() error {
+			cancel()
+			return <-errc
+		}
+	}
+	expect := func(order string) string {
+		t.Helper()
+		select {
+		case got := <-handled:
+			if got.order != order {
+				t.Fatalf("expected order %s handled, got %s by %s", order, got.order, got.member)
+			}
+			return got.member
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for order %s", order)
+		}
+		return ""
+	}
+
+	stops := map[string]func() error{"a": run("a"), "b": run("b")}
+	c.Append(ctx, "order-1", 0, &orderPlaced{OrderID: "1"})
+	leader := expect("1")
+	c.Append(ctx, "order-2", 0, &orderPlaced{OrderID: "2"})
+	if member := expect("2"); member != leader {
+		t.Fatalf("expected order 2 handled by leader %s, got %s", leader, member)
+	}
+
+	// Members outside the lease can not overwrite the checkpoint
+	follower := map[string]string{"a": "b", "b": "a"}[leader]
+	if err := c.ConsumerGroup(follower).SaveCheckpoint(ctx, "orders", 0); !errors.Is(err, eventale.ErrLeaseLost) {
+		t.Fatalf("expected ErrLeaseLost saving as follower, got %v", err)
+	}
+	if err := c.Checkpoints().SaveCheckpoint(ctx, "orders", 0); !errors.Is(err, eventale.ErrLeaseLost) {
+		t.Fatalf("expected ErrLeaseLost saving outside the group, got %v", err)
+	}
+
+	// The follower takes over from the checkpoint of the stopped leader
+	if err := stops[leader](); err != nil {
+		t.Fatalf("run %s: %v", leader, err)
+	}
+	c.Append(ctx, "order-3", 0, &orderPlaced{OrderID: "3"})
+	if member := expect("3"); member != follower {
+		t.Fatalf("expected order 3 handled by %s, got %s", follower, member)
+	}
+	if err := stops[follower](); err != nil {
+		t.Fatalf("run %s: %v", follower, err)
+	}
+	select {
+	case got := <-handled:
+		t.Fatalf("unexpected order %s handled again by %s", got.order, got.member)
+	default:
+	}
+}
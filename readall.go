@@ -0,0 +1,208 @@
+package eventale
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	eventalepb "github.com/nohns/eventale/gen/v1"
+	"github.com/nohns/eventale/internal/frame"
+	"github.com/nohns/eventale/internal/store"
+	"google.golang.org/protobuf/proto"
+)
+
+// The maximum number of events scanned by the server for a single ReadAll
+// request, so sparse filters do not hold up the server.
+const _readAllScanLimit = 10 * _readPageSize
+
+// Direction is the order in which the log of all streams is read.
+type Direction int
+
+const (
+	Forwards Direction = iota
+	Backwards
+)
+
+// Filter selects events when reading all streams. An event matches if it
+// matches all criteria set. Prefixes match if any of the prefixes match, and
+// regular expressions use RE2 syntax. The zero Filter matches all events.
+type Filter struct {
+	StreamPrefixes    []string
+	EventTypePrefixes []string
+	StreamRegex       string
+	EventTypeRegex    string
+}
+
+// ReadAllResult is a page of events read from the log of all streams.
+type ReadAllResult struct {
+	// Events holds the events matching the filter.
+	Events []*Event
+	// Position is the position of the last event scanned by the server,
+	// whether it matched the filter or not. Pass it as fromPosition to
+	// continue reading after this page. It is set even when no events
+	// matched.
+	Position uint64
+	// End is set when the end of the log was reached, in the direction read.
+	End bool
+}
+
+// ReadAll reads a page of events of all streams, in the order they were
+// appended. Reading forwards returns events after fromPosition, and reading
+// backwards returns events before it, where 0 means the end of the log. Events
+// are filtered by the server, see Filter. A page holds at most 500 events, see
+// MaxCount to read fewer, and AsOf to read the log as it was at an earlier
+// time.
+func (c *Client) ReadAll(ctx context.Context, fromPosition uint64, direction Direction, filter Filter, options ...readOpt) (*ReadAllResult, error) {
+	var opts readOpts
+	for _, opt := range options {
+		opt.apply(&opts)
+	}
+
+	var res eventalepb.WireReadAllResult
+	req := &eventalepb.WireReadAllRequest{
+		FromPosition: fromPosition,
+		Backwards:    direction == Backwards,
+		MaxCount:     uint32(opts.maxCount),
+		AsOf:         opts.asOfWire(),
+		Filter: &eventalepb.WireFilter{
+			StreamPrefixes:    filter.StreamPrefixes,
+			EventTypePrefixes: filter.EventTypePrefixes,
+			StreamRegex:       filter.StreamRegex,
+			EventTypeRegex:    filter.EventTypeRegex,
+		},
+	}
+	if err := c.call(ctx, frame.FrameKindReadAll, req, frame.FrameKindReadAllResult, &res); err != nil {
+		return nil, err
+	}
+	result := &ReadAllResult{
+		Events:   make([]*Event, len(res.Events)),
+		Position: res.Checkpoint,
+		End:      res.End,
+	}
+	for i, pb := range res.Events {
+		ev := eventFromWire(pb)
+		if err := c.registry.decode(ev); err != nil {
+			return nil, err
+		}
+		result.Events[i] = ev
+	}
+	return result, nil
+}
+
+// eventFilter is a compiled Filter.
+type eventFilter struct {
+	streamPrefixes []string
+	typePrefixes   []string
+	streamRe       *regexp.Regexp
+	typeRe         *regexp.Regexp
+}
+
+func newEventFilter(pb *eventalepb.WireFilter) (*eventFilter, error) {
+	f := &eventFilter{
+		streamPrefixes: pb.GetStreamPrefixes(),
+		typePrefixes:   pb.GetEventTypePrefixes(),
+	}
+	var err error
+	if re := pb.GetStreamRegex(); re != "" {
+		if f.streamRe, err = regexp.Compile(re); err != nil {
+			return nil, fmt.Errorf("stream regex: %v: %w", err, errBadRequest)
+		}
+	}
+	if re := pb.GetEventTypeRegex(); re != "" {
+		if f.typeRe, err = regexp.Compile(re); err != nil {
+			return nil, fmt.Errorf("event type regex: %v: %w", err, errBadRequest)
+		}
+	}
+	return f, nil
+}
+
+func (f *eventFilter) match(ev store.Event) bool {
+	if len(f.streamPrefixes) > 0 && !hasAnyPrefix(ev.Stream, f.streamPrefixes) {
+		return false
+	}
+	if len(f.typePrefixes) > 0 && !hasAnyPrefix(ev.Type, f.typePrefixes) {
+		return false
+	}
+	if f.streamRe != nil && !f.streamRe.MatchString(ev.Stream) {
+		return false
+	}
+	if f.typeRe != nil && !f.typeRe.MatchString(ev.Type) {
+		return false
+	}
+	return true
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) handleReadAll(frm *frame.Frame) (*eventalepb.WireReadAllResult, error) {
+	var req eventalepb.WireReadAllRequest
+	if err := proto.Unmarshal(frm.Payload, &req); err != nil {
+		return nil, fmt.Errorf("decode read all: %v: %w", err, errBadRequest)
+	}
+	return s.readAll(context.TODO(), &req)
+}
+
+func (s *Server) readAll(ctx context.Context, req *eventalepb.WireReadAllRequest) (*eventalepb.WireReadAllResult, error) {
+	filter, err := newEventFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	max := int(req.MaxCount)
+	if max == 0 || max > _readPageSize {
+		max = _readPageSize
+	}
+
+	res := &eventalepb.WireReadAllResult{Checkpoint: req.FromPosition}
+	// Reading as of a time reads the log up to the last event committed
+	// before it.
+	last := uint64(math.MaxUint64)
+	if req.AsOf != 0 {
+		if last, err = s.store.PositionAt(ctx, time.Unix(0, req.AsOf)); err != nil {
+			return nil, err
+		}
+		if req.Backwards && (res.Checkpoint == 0 || res.Checkpoint > last+1) {
+			res.Checkpoint = last + 1
+		}
+	}
+	for scanned := 0; scanned < _readAllScanLimit; {
+		var events []store.Event
+		if req.Backwards {
+			events, err = s.store.ReadAllBackwards(ctx, res.Checkpoint, _readPageSize)
+		} else {
+			events, err = s.store.ReadAll(ctx, res.Checkpoint, _readPageSize)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, ev := range events {
+			if ev.Position > last {
+				res.End = true
+				return res, nil
+			}
+			res.Checkpoint = ev.Position
+			if !filter.match(ev) {
+				continue
+			}
+			res.Events = append(res.Events, eventToWire(ev))
+			if len(res.Events) == max {
+				return res, nil
+			}
+		}
+		scanned += len(events)
+		if len(events) < _readPageSize {
+			res.End = true
+			break
+		}
+	}
+	return res, nil
+}
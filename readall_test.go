@@ -0,0 +1,66 @@
+package eventale_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nohns/eventale"
+)
+
+func TestReadAll(t *testing.T) {
+	ctx := context.Background()
+	_, addr := startServer(t)
+	c := orderClient(t, addr)
+
+	c.Append(ctx, "order-1", 0, &orderPlaced{OrderID: "1"}, &itemAdded{SKU: "a"})
+	c.Append(ctx, "customer-1", 0, &itemAdded{SKU: "b"})
+	c.Append(ctx, "order-2", 0, &orderPlaced{OrderID: "2"})
+
+	positions := func(res *eventale.ReadAllResult) []uint64 {
+		var ps []uint64
+		for _, ev := range res.Events {
+			ps = append(ps, ev.Position)
+		}
+		return ps
+	}
+
+	res, err := c.ReadAll(ctx, 0, eventale.Forwards, eventale.Filter{StreamPrefixes: []string{"order-"}}, eventale.MaxCount(2))
+	if err != nil {
+		t.Fatalf("read all: %v", err)
+	}
+	if got := positions(res); len(got) != 2 || got[0] != 1 || got[1] != 2 || res.Position != 2 || res.End {
+		t.Fatalf("unexpected first page %v at %d (end %v)", got, res.Position, res.End)
+	}
+	res, err = c.ReadAll(ctx, res.Position, eventale.Forwards, eventale.Filter{StreamPrefixes: []string{"order-"}}, eventale.MaxCount(2))
+	if err != nil {
+		t.Fatalf("read all: %v", err)
+	}
+	if got := positions(res); len(got) != 1 || got[0] != 4 || !res.End {
+		t.Fatalf("unexpected second page %v (end %v)", got, res.End)
+	}
+	if _, ok := res.Events[0].Value.(*orderPlaced); !ok {
+		t.Fatalf("expected decoded event, got %T", res.Events[0].Value)
+	}
+
+	res, err = c.ReadAll(ctx, 0, eventale.Backwards, eventale.Filter{EventTypeRegex: "^Item"})
+	if err != nil {
+		t.Fatalf("read all backwards: %v", err)
+	}
+	if got := positions(res); len(got) != 2 || got[0] != 3 || got[1] != 2 || res.Position != 1 || !res.End {
+		t.Fatalf("unexpected backwards page %v at %d (end %v)", got, res.Position, res.End)
+	}
+
+	// Pages without matches still advance the position
+	res, err = c.ReadAll(ctx, 0, eventale.Forwards, eventale.Filter{EventTypePrefixes: []string{"Nothing"}})
+	if err != nil {
+		t.Fatalf("read all: %v", err)
+	}
+	if len(res.Events) != 0 || res.Position != 4 {
+		t.Fatalf("expected no events at position 4, got %v at %d", positions(res), res.Position)
+	}
+
+	if _, err := c.ReadAll(ctx, 0, eventale.Forwards, eventale.Filter{StreamRegex: "("}); !errors.Is(err, eventale.ErrBadRequest) {
+		t.Fatalf("expected ErrBadRequest for invalid regex, got %v", err)
+	}
+}
@@ -0,0 +1,248 @@
+package eventale
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	// ErrEventTypeRegistered is returned when registering an event type name
+	// which is already taken by another Go type.
+	ErrEventTypeRegistered = errors.New("event type already registered")
+	// ErrEventTypeUnknown is returned when looking up an event type name or Go
+	// type that has not been registered.
+	ErrEventTypeUnknown = errors.New("event type unknown")
+)
+
+// ProtoEvent is a protobuf message known to Eventale as an event. Code
+// generated by protoc-gen-go-internal implements it for all messages
+// annotated with the (eventale.event) option.
+type ProtoEvent interface {
+	proto.Message
+	// EventType returns the stable name of the event type, as stored in the
+	// event log.
+	EventType() string
+}
+
+// Codec encodes and decodes the payload of events.
+type Codec interface {
+	// ContentType is stored alongside every event encoded by the codec.
+	ContentType() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+var (
+	// ProtoCodec encodes events which are protobuf messages in the protobuf
+	// wire format.
+	ProtoCodec Codec = protoCodec{}
+	// JSONCodec encodes events using encoding/json.
+	JSONCodec Codec = jsonCodec{}
+)
+
+type protoCodec struct{}
+
+func (protoCodec) ContentType() string { return "application/protobuf" }
+
+func (protoCodec) Marshal(v any) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("proto codec: %T is not a proto.Message", v)
+	}
+	return proto.Marshal(msg)
+}
+
+func (protoCodec) Unmarshal(data []byte, v any) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("proto codec: %T is not a proto.Message", v)
+	}
+	return proto.Unmarshal(data, msg)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string { return "application/json" }
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// registeredType is a Go type registered for an event type name.
+type registeredType struct {
+	name  string
+	typ   reflect.Type
+	codec Codec
+}
+
+// EventRegistry maps event type names to the Go types representing them, and
+// the codecs used to encode them. It is safe for concurrent use.
+type EventRegistry struct {
+	mu     sync.RWMutex
+	byName map[string]registeredType
+	byType map[reflect.Type]registeredType
+}
+
+func NewEventRegistry() *EventRegistry {
+	return &EventRegistry{
+		byName: make(map[string]registeredType),
+		byType: make(map[reflect.Type]registeredType),
+	}
+}
+
+// Register registers the Go type of prototype under eventType, encoding it
+// with codec. Values of the type can be given either as a value or a pointer,
+// but are always decoded into a pointer. Registering the same type again under
+// the same name is a no-op.
+func (r *EventRegistry) Register(eventType string, prototype any, codec Codec) error {
+	typ := baseType(reflect.TypeOf(prototype))
+	if typ == nil {
+		return fmt.Errorf("register %q: nil prototype", eventType)
+	}
+	rt := registeredType{name: eventType, typ: typ, codec: codec}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.byName[eventType]; ok {
+		if existing.typ == typ {
+			return nil
+		}
+		return fmt.Errorf("register %q: %w", eventType, ErrEventTypeRegistered)
+	}
+	if existing, ok := r.byType[typ]; ok {
+		return fmt.Errorf("register %q: %s already registered as %q: %w", eventType, typ, existing.name, ErrEventTypeRegistered)
+	}
+	r.byName[eventType] = rt
+	r.byType[typ] = rt
+	return nil
+}
+
+// RegisterProto registers the protobuf message type of ev under the event type
+// name returned by ev.EventType().
+func (r *EventRegistry) RegisterProto(ev ProtoEvent) error {
+	return r.Register(ev.EventType(), ev, ProtoCodec)
+}
+
+// RegisterJSON registers the Go type of prototype under eventType, encoding it
+// as JSON.
+func (r *EventRegistry) RegisterJSON(eventType string, prototype any) error {
+	return r.Register(eventType, prototype, JSONCodec)
+}
+
+// New returns a pointer to a new zero value of the Go type registered under
+// eventType.
+func (r *EventRegistry) New(eventType string) (any, error) {
+	rt, ok := r.lookupName(eventType)
+	if !ok {
+		return nil, fmt.Errorf("new %q: %w", eventType, ErrEventTypeUnknown)
+	}
+	return reflect.New(rt.typ).Interface(), nil
+}
+
+// Marshal encodes v using the codec of its registered Go type. If v
+// implements DataSubject, the subject of the event is set.
+func (r *EventRegistry) Marshal(v any) (EventData, error) {
+	typ := baseType(reflect.TypeOf(v))
+	r.mu.RLock()
+	rt, ok := r.byType[typ]
+	r.mu.RUnlock()
+	if !ok {
+		return EventData{}, fmt.Errorf("marshal %v: %w", typ, ErrEventTypeUnknown)
+	}
+	data, err := rt.codec.Marshal(v)
+	if err != nil {
+		return EventData{}, fmt.Errorf("marshal %q: %v", rt.name, err)
+	}
+	ev := EventData{Type: rt.name, ContentType: rt.codec.ContentType(), Data: data}
+	if ds, ok := v.(DataSubject); ok {
+		ev.Subject = ds.DataSubject()
+	}
+	return ev, nil
+}
+
+// Unmarshal decodes data into a new value of the Go type registered under
+// eventType. ErrEventTypeUnknown is returned when no type is registered.
+func (r *EventRegistry) Unmarshal(eventType string, data []byte) (any, error) {
+	rt, ok := r.lookupName(eventType)
+	if !ok {
+		return nil, fmt.Errorf("unmarshal %q: %w", eventType, ErrEventTypeUnknown)
+	}
+	v := reflect.New(rt.typ).Interface()
+	if err := rt.codec.Unmarshal(data, v); err != nil {
+		return nil, fmt.Errorf("unmarshal %q: %v", eventType, err)
+	}
+	return v, nil
+}
+
+// decode sets the Value of ev, leaving it nil for unregistered event types.
+func (r *EventRegistry) decode(ev *Event) error {
+	if ev.Shredded {
+		return nil
+	}
+	v, err := r.decodeData(ev.Type, ev.Data)
+	if err != nil {
+		return err
+	}
+	ev.Value = v
+	return nil
+}
+
+// decodeData is like Unmarshal, but returns nil for unregistered types.
+func (r *EventRegistry) decodeData(eventType string, data []byte) (any, error) {
+	if r == nil {
+		return nil, nil
+	}
+	v, err := r.Unmarshal(eventType, data)
+	if errors.Is(err, ErrEventTypeUnknown) {
+		return nil, nil
+	}
+	return v, err
+}
+
+// encode turns v into raw event data. EventData values are passed through as
+// is, everything else must be of a registered type or wrapped by WithEventID
+// or WithMetadata.
+func (r *EventRegistry) encode(v any) (EventData, error) {
+	switch v := v.(type) {
+	case EventData:
+		return v, nil
+	case *EventData:
+		return *v, nil
+	case identifiedEvent:
+		data, err := r.encode(v.event)
+		data.ID = v.id
+		return data, err
+	case annotatedEvent:
+		data, err := r.encode(v.event)
+		data.Metadata = v.metadata.merge(data.Metadata)
+		return data, err
+	}
+	if r == nil {
+		return EventData{}, fmt.Errorf("marshal %T: %w", v, ErrEventTypeUnknown)
+	}
+	return r.Marshal(v)
+}
+
+func (r *EventRegistry) lookupName(eventType string) (registeredType, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rt, ok := r.byName[eventType]
+	return rt, ok
+}
+
+// baseType dereferences pointer types, so T and *T register as the same type.
+func baseType(typ reflect.Type) reflect.Type {
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ
+}
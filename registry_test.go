@@ -0,0 +1,49 @@
+package eventale_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nohns/eventale"
+	eventalepb "github.com/nohns/eventale/gen/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestEventRegistryProto(t *testing.T) {
+	reg := eventale.NewEventRegistry()
+	if err := reg.Register("SemanticVersion", (*eventalepb.SemanticVersion)(nil), eventale.ProtoCodec); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	data, err := reg.Marshal(&eventalepb.SemanticVersion{Major: 1})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if data.Type != "SemanticVersion" || data.ContentType != "application/protobuf" {
+		t.Fatalf("unexpected event data %+v", data)
+	}
+	v, err := reg.Unmarshal(data.Type, data.Data)
+	if err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !proto.Equal(v.(*eventalepb.SemanticVersion), &eventalepb.SemanticVersion{Major: 1}) {
+		t.Fatalf("unexpected decoded value %v", v)
+	}
+}
+
+func TestEventRegistryConflicts(t *testing.T) {
+	reg := eventale.NewEventRegistry()
+	if err := reg.RegisterJSON("OrderPlaced", orderPlaced{}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	// Registering the same type again is fine
+	if err := reg.RegisterJSON("OrderPlaced", &orderPlaced{}); err != nil {
+		t.Fatalf("register again: %v", err)
+	}
+	if err := reg.RegisterJSON("OrderPlaced", struct{}{}); !errors.Is(err, eventale.ErrEventTypeRegistered) {
+		t.Fatalf("expected ErrEventTypeRegistered, got %v", err)
+	}
+	if _, err := reg.Unmarshal("OrderShipped", nil); !errors.Is(err, eventale.ErrEventTypeUnknown) {
+		t.Fatalf("expected ErrEventTypeUnknown, got %v", err)
+	}
+}
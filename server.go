@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log/slog"
 	"net"
 	"os"
@@ -15,8 +16,12 @@ import (
 	"time"
 
 	eventalepb "github.com/nohns/eventale/gen/v1"
+	"github.com/nohns/eventale/internal"
+	"github.com/nohns/eventale/internal/auth"
+	"github.com/nohns/eventale/internal/broker"
 	"github.com/nohns/eventale/internal/connection"
 	"github.com/nohns/eventale/internal/frame"
+	"github.com/nohns/eventale/internal/store"
 	"github.com/nohns/eventale/internal/uuid"
 	"google.golang.org/protobuf/proto"
 )
@@ -24,6 +29,20 @@ import (
 const (
 	// The time period before closing a connection to a client due to timeout.
 	_serverConnTimeout = 30 * time.Second
+	// The maximum number of events read from storage at a time.
+	_readPageSize = 500
+	// The maximum number of events pushed to a subscriber in a single frame.
+	_maxSubscriptionBatch = _readPageSize
+	// The default time period between deleting events expired by stream
+	// metadata from storage.
+	_defaultScavengeInterval = 10 * time.Minute
+	// The default time period a transaction is kept open without requests.
+	_defaultTransactionTimeout = 30 * time.Second
+	// The default maximum number of appends buffered by a transaction.
+	_defaultMaxTransactionAppends = 1000
+	// The default maximum size in bytes of the appends buffered by a
+	// transaction.
+	_defaultMaxTransactionSize = 64 << 20
 )
 
 var (
@@ -32,6 +51,9 @@ var (
 	ErrServerClosed      = errors.New("server closed")
 	ErrTest              = errors.New("test")
 	ErrConnectionTimeout = errors.New("connection timeout")
+	// ErrUnauthorized is returned when a client is not allowed to connect, or
+	// sends requests before completing the handshake.
+	ErrUnauthorized = errors.New("unauthorized")
 )
 
 func ListenAndServe(address string) error {
@@ -52,22 +74,127 @@ type Server struct {
 	Addr string
 	// Logger is the structured logger used when writing to stdout
 	Logger *slog.Logger
+	// DBPath is the path of the SQLite database events are persisted in. When
+	// empty, events are kept in memory only.
+	DBPath string
+	// LinkRules define derived streams maintained in addition to the
+	// category and event type streams.
+	LinkRules []LinkRule
+	// ScavengeInterval is the time period between deleting events expired by
+	// stream metadata from storage. Defaults to 10 minutes, and a negative
+	// interval disables scavenging.
+	ScavengeInterval time.Duration
+	// TransactionTimeout is the time period after which a transaction is
+	// rolled back, when no requests have been made in it. Defaults to 30
+	// seconds.
+	TransactionTimeout time.Duration
+	// MaxTransactionAppends is the maximum number of appends buffered by a
+	// transaction until it is committed. Appends past it are rejected.
+	// Defaults to 1000.
+	MaxTransactionAppends int
+	// MaxTransactionSize is the maximum total size in bytes of the appends
+	// buffered by a transaction, as encoded on the wire. Appends past it are
+	// rejected. Defaults to 64 MiB.
+	MaxTransactionSize int
+	// DisableCompression makes the server decline compression advertised by
+	// clients.
+	DisableCompression bool
+	// CompressionThreshold is the size in bytes below which payloads sent to
+	// clients are not compressed. Defaults to 512 bytes.
+	CompressionThreshold int
+	// MaxFrameSize is the maximum payload size in bytes of frames received
+	// from clients. Clients split larger payloads into chunks, and
+	// connections sending larger frames are closed. Defaults to 1 MiB.
+	MaxFrameSize int
+	// MaxPayloadSize is the maximum size in bytes of a payload a client
+	// splits into chunks, which is reassembled in memory before handling it.
+	// Connections sending larger payloads are closed. Defaults to 64 MiB.
+	MaxPayloadSize int
+	// FlushInterval is how long frames sent to a client are buffered to be
+	// coalesced with following frames into a single write. By default frames
+	// are written once no more are queued, and never delayed.
+	FlushInterval time.Duration
+	// FlushSize is the size in bytes of the write buffer of connections.
+	// Defaults to 32 KiB.
+	FlushSize int
+	// SubscriptionBuffer is the number of live events buffered for a
+	// subscription not keeping up with them. Defaults to 10000.
+	SubscriptionBuffer int
+	// SlowSubscriptions decides what happens to subscriptions falling more
+	// than SubscriptionBuffer events behind. By default they catch up from
+	// storage.
+	SlowSubscriptions SlowSubscriptionPolicy
+	// WebSocketOrigins are the host patterns of the origins allowed to
+	// connect over WebSocket, in addition to the host of the server, e.g.
+	// for browser dashboards served elsewhere. See ServeHTTP.
+	WebSocketOrigins []string
 
-	lnr        net.Listener
+	lnrs       []net.Listener
 	conns      []*connection.Conn
 	authedkeys map[[32]byte]rsa.PublicKey
+	authedUIDs map[uint32]bool
+	tokens     *auth.TokenVerifier
 	state      serverStatus
 	mu         sync.RWMutex
 	nextid     int
+	done       chan struct{}
+
+	store  store.Store
+	broker *broker.Broker
+	// appendMu serializes appends, so events are published to subscribers in
+	// the order they were persisted.
+	appendMu sync.Mutex
+	// leaseMu guards the leases of consumer groups, and serializes writes of
+	// their checkpoints with changes of their holders.
+	leaseMu sync.Mutex
+	leases  map[string]checkpointLease
 }
 
 func NewServer(addr string) *Server {
-	return &Server{
+	s := &Server{
 		Addr:   addr,
 		Logger: slog.New(slog.NewTextHandler(os.Stdout, nil)),
 		conns:  make([]*connection.Conn, 0),
 		nextid: 1,
+		done:   make(chan struct{}),
+		broker: broker.New(),
+	}
+	s.tokens = &auth.TokenVerifier{Lookup: s.authorizedKey, MaxAge: _tokenMaxAge}
+	return s
+}
+
+// AuthorizeKey allows clients holding the private key of pub to connect. Once
+// a key is authorized, clients without a key are rejected, unless authorized
+// with AuthorizeUID.
+func (s *Server) AuthorizeKey(pub *rsa.PublicKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.authedkeys == nil {
+		s.authedkeys = make(map[[32]byte]rsa.PublicKey)
 	}
+	s.authedkeys[auth.Fingerprint(pub)] = *pub
+}
+
+// authorizedKey returns the authorized key with fingerprint.
+func (s *Server) authorizedKey(fingerprint [32]byte) (*rsa.PublicKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	pub, ok := s.authedkeys[fingerprint]
+	return &pub, ok
+}
+
+// AuthorizeUID allows clients connecting over a Unix socket to connect without
+// a key, when their process runs as the user uid. They are identified by its
+// SO_PEERCRED credentials, see ListenAndServeUnix. Once a user is authorized,
+// clients of other users are rejected unless holding an authorized key, as
+// are clients of every other listener, such as TCP, the gateway and gRPC.
+func (s *Server) AuthorizeUID(uid uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.authedUIDs == nil {
+		s.authedUIDs = make(map[uint32]bool)
+	}
+	s.authedUIDs[uid] = true
 }
 
 func (s *Server) ListenAndServe() error {
@@ -76,11 +203,35 @@ func (s *Server) ListenAndServe() error {
 	if err != nil {
 		return err
 	}
-	defer s.Close()
+	return s.Serve(lnr)
+}
 
-	s.mu.Lock()
-	s.state = serverStatusServing
-	s.mu.Unlock()
+// ListenAndServeUnix listens on the Unix socket at path, and serves
+// connections on it like Serve, for clients on the same host dialing
+// "unix://" addresses. A socket left at path by a previous server is
+// replaced. Access to the socket is governed by its file permissions, while
+// clients are identified by the user their process runs as.
+func (s *Server) ListenAndServeUnix(path string) error {
+	if fi, err := os.Stat(path); err == nil && fi.Mode().Type() == fs.ModeSocket {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	s.Logger.Info("Listening for Unix socket traffic", slog.String("path", path))
+	lnr, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	return s.Serve(lnr)
+}
+
+// Serve accepts connections on lnr, and serves each of them in a new
+// goroutine. Serve always returns a non-nil error and closes lnr.
+func (s *Server) Serve(lnr net.Listener) error {
+	if err := s.listen(lnr); err != nil {
+		return err
+	}
+	defer s.Close()
 
 	for {
 		s.Logger.Debug("Waiting for connection...")
@@ -96,39 +247,163 @@ func (s *Server) ListenAndServe() error {
 		}
 
 		s.Logger.Debug("Connecting to client")
-		s.mu.Lock()
-		c := &connection.Conn{
-			ID:      s.nextid,
-			NetConn: conn,
-			Logger:  s.Logger,
+		c := s.addConn(conn)
+		if c == nil {
+			return ErrServerClosed
 		}
-		s.conns = append(s.conns, c)
-		s.mu.Unlock()
-
 		go s.listenOnConn(c)
 		s.Logger.Debug("client connected", slog.Int("id", c.ID))
 	}
 }
 
-func (s *Server) Close() error {
-	if err := s.lnr.Close(); err != nil {
+// listen starts serving on lnr, so it is closed with the server. The store is
+// opened when the server starts serving on its first listener.
+func (s *Server) listen(lnr net.Listener) error {
+	if err := s.open(); err != nil {
+		lnr.Close()
 		return err
 	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state == serverStatusClosed {
+		lnr.Close()
+		return ErrServerClosed
+	}
+	s.lnrs = append(s.lnrs, lnr)
+	return nil
+}
 
+// open validates the configuration and opens the store the first time it is
+// called, after which the server is serving until closed.
+func (s *Server) open() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.state = serverStatusClosed
+	switch s.state {
+	case serverStatusServing:
+		return nil
+	case serverStatusClosed:
+		return ErrServerClosed
+	}
 
-	for _, conn := range s.conns {
-		if err := conn.Close(); err != nil {
+	for _, rule := range s.LinkRules {
+		if err := rule.validate(); err != nil {
 			return err
 		}
 	}
+	if s.store == nil {
+		st, err := store.OpenSQLite(s.DBPath)
+		if err != nil {
+			return fmt.Errorf("open store: %v", err)
+		}
+		s.store = st
+	}
+	s.state = serverStatusServing
+	go s.scavengeLoop()
 	return nil
 }
 
+// addConn registers a connection over nc to be closed with the server. When
+// the server is already closed, nc is closed and nil is returned.
+func (s *Server) addConn(nc net.Conn) *connection.Conn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state == serverStatusClosed {
+		nc.Close()
+		return nil
+	}
+	c := &connection.Conn{
+		ID:             s.nextid,
+		NetConn:        nc,
+		Logger:         s.Logger,
+		MaxFrameSize:   s.MaxFrameSize,
+		MaxPayloadSize: s.MaxPayloadSize,
+		FlushInterval:  s.FlushInterval,
+		FlushSize:      s.FlushSize,
+	}
+	s.nextid++
+	s.conns = append(s.conns, c)
+	return c
+}
+
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state == serverStatusClosed {
+		return nil
+	}
+	s.state = serverStatusClosed
+	close(s.done)
+
+	for _, lnr := range s.lnrs {
+		lnr.Close()
+	}
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	if s.store != nil {
+		return s.store.Close()
+	}
+	return nil
+}
+
+// scavengeLoop periodically deletes events expired by stream metadata, until
+// the server is closed.
+func (s *Server) scavengeLoop() {
+	interval := s.ScavengeInterval
+	if interval == 0 {
+		interval = _defaultScavengeInterval
+	}
+	if interval < 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+		n, err := s.store.Scavenge(context.Background())
+		if err != nil {
+			s.Logger.Error("Failed to scavenge", slog.String("error", err.Error()))
+			continue
+		}
+		if n > 0 {
+			s.Logger.Info("Scavenged expired events", slog.Int64("count", n))
+		}
+	}
+}
+
+// session holds the state of a single client connection.
+type session struct {
+	conn *connection.Conn
+
+	mu      sync.Mutex
+	helloed bool
+	// principal identifies the authenticated client, if any.
+	principal string
+	subs      map[string]*serverSubscription
+	txs       map[string]*transaction
+	// peer holds the credentials of clients connected over a Unix socket.
+	peer *auth.PeerCred
+}
+
 func (s *Server) listenOnConn(conn *connection.Conn) {
-	defer conn.Close()
+	sess := &session{
+		conn: conn,
+		subs: make(map[string]*serverSubscription),
+		txs:  make(map[string]*transaction),
+	}
+	defer s.closeSession(sess)
+	if uc, ok := conn.NetConn.(*net.UnixConn); ok {
+		cred, err := auth.PeerCredentials(uc)
+		if err != nil {
+			s.Logger.Warn("Failed to read peer credentials", slog.Int("connID", conn.ID), slog.String("error", err.Error()))
+		} else {
+			sess.peer = &cred
+		}
+	}
 	for {
 		ctx, cancel := context.WithTimeoutCause(context.Background(), _serverConnTimeout, ErrConnectionTimeout)
 		frm, err := conn.Recv(ctx)
@@ -137,43 +412,200 @@ func (s *Server) listenOnConn(conn *connection.Conn) {
 			s.Logger.Info("Connection timeout")
 			return
 		}
-		if errors.Is(err, io.EOF) {
+		if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
 			s.Logger.Info(fmt.Sprintf("Quit listen on connection %d - EOF", conn.ID))
 			return
 		}
+		var tooLarge *frame.TooLargeError
+		if errors.As(err, &tooLarge) {
+			// The rest of the frame is not read, so the connection can not
+			// be used after rejecting it
+			s.Logger.Warn("Rejecting frame", slog.Int("connID", conn.ID), slog.String("error", err.Error()))
+			s.respond(sess, tooLarge.Frame, 0, nil, err)
+			return
+		}
 		if err != nil {
 			s.Logger.Error("Failed to read frame", slog.String("error", err.Error()))
-			continue
+			return
 		}
-		if err := s.handleFrame(conn, frm); err != nil {
+		if err := s.handleFrame(sess, frm); err != nil {
 			s.Logger.Error("Failed to handle frame", slog.String("error", err.Error()))
 		}
 	}
 }
 
-func (s *Server) handleFrame(conn *connection.Conn, frm *frame.Frame) error {
+// authPrincipal returns the principal the client of sess authenticated as.
+func (sess *session) authPrincipal() string {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.principal
+}
+
+func (s *Server) closeSession(sess *session) {
+	sess.mu.Lock()
+	for _, sub := range sess.subs {
+		sub.cancel()
+	}
+	for _, tx := range sess.txs {
+		tx.timer.Stop()
+	}
+	sess.mu.Unlock()
+	sess.conn.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, c := range s.conns {
+		if c == sess.conn {
+			s.conns = append(s.conns[:i], s.conns[i+1:]...)
+			break
+		}
+	}
+}
+
+func (s *Server) handleFrame(sess *session, frm *frame.Frame) error {
+	if frm.Kind != frame.FrameKindClientHello {
+		sess.mu.Lock()
+		helloed := sess.helloed
+		sess.mu.Unlock()
+		if !helloed {
+			return s.respond(sess, frm, 0, nil, ErrUnauthorized)
+		}
+	}
+
 	switch frm.Kind {
 	case frame.FrameKindClientHello:
-		// Respond with server hello
-		var msg eventalepb.WireClientHello
-		if err := proto.Unmarshal(frm.Payload, &msg); err != nil {
-			return fmt.Errorf("decode client hello: %v", err)
-		}
+		return s.handleClientHello(sess, frm)
 
-		// Gen secret symmetric encryption key, and encrypt using the connect
-		// clients associated public key. This way, the client and decrypt it
-		// and also use it when communicating.
+	case frame.FrameKindHeartbeat:
+		// Respond with heartbeat again
+		frm, err := frame.Make(frame.FrameKindHeartbeat)
+		if err != nil {
+			return fmt.Errorf("frame make: %v", err)
+		}
+		if err := sess.conn.Send(context.TODO(), frm); err != nil {
+			return fmt.Errorf("conn send: %v", err)
+		}
+	case frame.FrameKindSecretPublish:
 
-		if len(msg.Signature) != 32 {
-			return fmt.Errorf("incorrect key length")
+	case frame.FrameKindAppend:
+		res, err := s.handleAppend(sess, frm)
+		return s.respond(sess, frm, frame.FrameKindAppendResult, res, err)
+	case frame.FrameKindReadStream:
+		res, err := s.handleReadStream(frm)
+		return s.respond(sess, frm, frame.FrameKindReadStreamResult, res, err)
+	case frame.FrameKindReadAll:
+		res, err := s.handleReadAll(frm)
+		return s.respond(sess, frm, frame.FrameKindReadAllResult, res, err)
+	case frame.FrameKindWriteSnapshot:
+		err := s.handleWriteSnapshot(frm)
+		return s.respond(sess, frm, frame.FrameKindSnapshotWritten, nil, err)
+	case frame.FrameKindReadSnapshot:
+		res, err := s.handleReadSnapshot(frm)
+		return s.respond(sess, frm, frame.FrameKindReadSnapshotResult, res, err)
+	case frame.FrameKindWriteCheckpoint:
+		var req eventalepb.WireCheckpoint
+		if err := proto.Unmarshal(frm.Payload, &req); err != nil {
+			return s.respond(sess, frm, 0, nil, fmt.Errorf("decode write checkpoint: %v: %w", err, errBadRequest))
+		}
+		err := s.writeCheckpoint(context.TODO(), &req)
+		return s.respond(sess, frm, frame.FrameKindCheckpointWritten, nil, err)
+	case frame.FrameKindReadCheckpoint:
+		var req eventalepb.WireReadCheckpointRequest
+		if err := proto.Unmarshal(frm.Payload, &req); err != nil {
+			return s.respond(sess, frm, 0, nil, fmt.Errorf("decode read checkpoint: %v: %w", err, errBadRequest))
 		}
+		pos, err := s.store.ReadCheckpoint(context.TODO(), req.Name)
+		return s.respond(sess, frm, frame.FrameKindReadCheckpointResult, &eventalepb.WireCheckpoint{Name: req.Name, Position: pos}, err)
+	case frame.FrameKindAcquireCheckpointLease:
+		res, err := s.handleCheckpointLease(frm)
+		return s.respond(sess, frm, frame.FrameKindCheckpointLease, res, err)
+	case frame.FrameKindDeleteStream:
+		res, err := s.handleDeleteStream(frm)
+		return s.respond(sess, frm, frame.FrameKindStreamDeleted, res, err)
+	case frame.FrameKindBeginTransaction:
+		res, err := s.handleBeginTransaction(sess)
+		return s.respond(sess, frm, frame.FrameKindTransactionBegun, res, err)
+	case frame.FrameKindTransactionAppend:
+		err := s.handleTransactionAppend(sess, frm)
+		return s.respond(sess, frm, frame.FrameKindTransactionAppended, nil, err)
+	case frame.FrameKindCommitTransaction:
+		res, err := s.handleCommitTransaction(sess, frm)
+		return s.respond(sess, frm, frame.FrameKindTransactionCommitted, res, err)
+	case frame.FrameKindRollbackTransaction:
+		err := s.handleRollbackTransaction(sess, frm)
+		return s.respond(sess, frm, frame.FrameKindTransactionRolledBack, nil, err)
+	case frame.FrameKindForgetSubject:
+		var req eventalepb.WireForgetSubjectRequest
+		if err := proto.Unmarshal(frm.Payload, &req); err != nil {
+			return s.respond(sess, frm, 0, nil, fmt.Errorf("decode forget subject: %v: %w", err, errBadRequest))
+		}
+		if req.Subject == "" {
+			return s.respond(sess, frm, 0, nil, fmt.Errorf("forget subject without subject: %w", errBadRequest))
+		}
+		err := s.store.ForgetSubject(context.TODO(), req.Subject)
+		return s.respond(sess, frm, frame.FrameKindSubjectForgotten, nil, err)
+	case frame.FrameKindWriteStreamMetadata:
+		err := s.handleWriteStreamMetadata(frm)
+		return s.respond(sess, frm, frame.FrameKindStreamMetadataWritten, nil, err)
+	case frame.FrameKindReadStreamMetadata:
+		res, err := s.handleReadStreamMetadata(frm)
+		return s.respond(sess, frm, frame.FrameKindReadStreamMetadataResult, res, err)
+	case frame.FrameKindSubscribe:
+		return s.handleSubscribe(sess, frm)
+	case frame.FrameKindUnsubscribe:
+		if frm.RespondsTo == nil {
+			return fmt.Errorf("unsubscribe without subscription id")
+		}
+		sess.mu.Lock()
+		sub, ok := sess.subs[frm.RespondsTo.String()]
+		sess.mu.Unlock()
+		if ok {
+			sub.cancel()
+		}
+	case frame.FrameKindSubscriptionCredit:
+		return s.handleSubscriptionCredit(sess, frm)
+	default:
+		return fmt.Errorf("unexpected frame kind %d", frm.Kind)
+	}
+	return nil
+}
+
+func (s *Server) handleClientHello(sess *session, frm *frame.Frame) error {
+	// Respond with server hello
+	var msg eventalepb.WireClientHello
+	if err := proto.Unmarshal(frm.Payload, &msg); err != nil {
+		return fmt.Errorf("decode client hello: %v", err)
+	}
+
+	// Gen secret symmetric encryption key, and encrypt using the connect
+	// clients associated public key. This way, the client and decrypt it
+	// and also use it when communicating. Clients without a key are only
+	// allowed when no keys or users have been authorized, or when connected
+	// over a Unix socket by an authorized user.
+	s.mu.RLock()
+	anonymous := len(s.authedkeys) == 0 && len(s.authedUIDs) == 0
+	peerAuthed := sess.peer != nil && s.authedUIDs[sess.peer.UID]
+	s.mu.RUnlock()
+
+	var (
+		plainkey, cipherkey []byte
+		principal           string
+	)
+	switch {
+	case len(msg.Signature) == 0 && sess.peer != nil && (anonymous || peerAuthed):
+		principal = auth.UIDPrincipal(sess.peer.UID)
+	case len(msg.Signature) == 0 && anonymous:
+	case len(msg.Signature) != 32:
+		return s.respond(sess, frm, 0, nil, fmt.Errorf("incorrect key length: %w", ErrUnauthorized))
+	default:
 		s.mu.RLock()
 		pubkey, ok := s.authedkeys[[32]byte(msg.Signature)]
 		s.mu.RUnlock()
 		if !ok {
-			return fmt.Errorf("unauthorized")
+			return s.respond(sess, frm, 0, nil, ErrUnauthorized)
 		}
-		plainkey := make([]byte, 32)
+		principal = auth.KeyPrincipal([32]byte(msg.Signature))
+		plainkey = make([]byte, 32)
 		n, err := rand.Reader.Read(plainkey)
 		if err != nil {
 			return fmt.Errorf("rand read enc key: %v", err)
@@ -182,43 +614,616 @@ func (s *Server) handleFrame(conn *connection.Conn, frm *frame.Frame) error {
 			return fmt.Errorf("could not read enough bytes for enc key")
 		}
 		h := sha256.New()
-		cipherkey, err := rsa.EncryptOAEP(h, rand.Reader, &pubkey, plainkey, nil)
+		cipherkey, err = rsa.EncryptOAEP(h, rand.Reader, &pubkey, plainkey, nil)
 		if err != nil {
 			return fmt.Errorf("rsa encrypt: %v", err)
 		}
+	}
 
-		s.Logger.Info("Client hello - replying with server hello...", slog.Int("connID", conn.ID))
-		frm, err := frame.Make(frame.FrameKindServerHello, frame.WithID(uuid.IDer), frame.WithRespondTo(frm.ID), frame.WithProto(&eventalepb.WireServerHello{
-			ServerVersion: &eventalepb.SemanticVersion{
-				Major: 0,
-				Minor: 0,
-				Patch: 1,
-			},
-			EncryptionKey: cipherkey,
-		}))
-		if err != nil {
-			return fmt.Errorf("frame make: %v", err)
+	compression := s.negotiateCompression(msg.Compressions)
+	s.Logger.Info("Client hello - replying with server hello...", slog.Int("connID", sess.conn.ID), slog.String("compression", compression.String()))
+	if err := s.respond(sess, frm, frame.FrameKindServerHello, &eventalepb.WireServerHello{
+		ServerVersion: &eventalepb.SemanticVersion{
+			Major: 0,
+			Minor: 0,
+			Patch: 1,
+		},
+		EncryptionKey: cipherkey,
+		Compression:   eventalepb.WireCompression(compression),
+		MaxFrameSize:  uint32(s.MaxFrameSize),
+	}, nil); err != nil {
+		return err
+	}
+
+	// Everything after the server hello is encrypted, if a key was exchanged,
+	// and compressed, if the client supports it
+	if plainkey != nil {
+		if err := sess.conn.Upgrade(plainkey); err != nil {
+			return fmt.Errorf("conn upgrade: %v", err)
 		}
+	}
+	if err := sess.conn.SetCompression(compression, s.compressionThreshold()); err != nil {
+		return fmt.Errorf("conn set compression: %v", err)
+	}
+	if msg.MaxFrameSize > 0 {
+		sess.conn.SetPeerMaxFrameSize(int(msg.MaxFrameSize))
+	}
+	sess.mu.Lock()
+	sess.helloed = true
+	sess.principal = principal
+	sess.mu.Unlock()
+	return nil
+}
 
-		if err := conn.Send(context.TODO(), frm); err != nil {
-			return fmt.Errorf("conn send: %v", err)
+func (s *Server) handleAppend(sess *session, frm *frame.Frame) (*eventalepb.WireAppendResult, error) {
+	var req eventalepb.WireAppendRequest
+	if err := proto.Unmarshal(frm.Payload, &req); err != nil {
+		return nil, fmt.Errorf("decode append: %v", err)
+	}
+	return s.append(context.TODO(), sess.authPrincipal(), &req)
+}
+
+// append appends the events of req, and publishes them to subscribers. The
+// requests of every protocol served end up here.
+func (s *Server) append(ctx context.Context, principal string, req *eventalepb.WireAppendRequest) (*eventalepb.WireAppendResult, error) {
+	a, err := s.streamAppend(principal, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.appendMu.Lock()
+	defer s.appendMu.Unlock()
+	res, err := s.store.Append(ctx, a.Stream, a.ExpectedVersion, a.Events)
+	if err != nil {
+		return nil, err
+	}
+	s.publish(a.Stream, res)
+	return &eventalepb.WireAppendResult{Version: res.Version, Position: res.Position}, nil
+}
+
+// streamAppend validates req, and turns it into an append to the store. The
+// principal of the events is set to principal, which is empty for anonymous
+// clients.
+func (s *Server) streamAppend(principal string, req *eventalepb.WireAppendRequest) (store.StreamAppend, error) {
+	events := make([]store.EventData, len(req.Events))
+	for i, ev := range req.Events {
+		if ev.Type == "" {
+			return store.StreamAppend{}, fmt.Errorf("event %d has no type: %w", i, errBadRequest)
+		}
+		if len(ev.Id) != 0 && len(ev.Id) != 16 {
+			return store.StreamAppend{}, fmt.Errorf("event %d has an id of %d bytes: %w", i, len(ev.Id), errBadRequest)
+		}
+		events[i] = store.EventData{
+			Type:        ev.Type,
+			ContentType: ev.ContentType,
+			Data:        ev.Data,
+			Links:       s.linksFor(req.Stream, ev.Type),
+			Subject:     ev.Subject,
+			ID:          ev.Id,
+			Metadata:    metadataToStore(ev.Metadata),
+		}
+		// Clients can not claim a principal, so it is cleared for
+		// anonymous clients.
+		events[i].Metadata.Principal = principal
+	}
+	return store.StreamAppend{Stream: req.Stream, ExpectedVersion: req.ExpectedVersion, Events: events}, nil
+}
+
+// publish publishes the events appended to stream to subscribers. appendMu
+// must be held. The events are already persisted, so failing to read the
+// metadata of stream is logged instead of failing the append, and live
+// subscribers miss the events.
+func (s *Server) publish(stream string, res store.AppendResult) {
+	meta, err := s.store.ReadStreamMetadata(context.TODO(), stream)
+	if err != nil {
+		s.Logger.Error("Failed to publish appended events", slog.String("stream", stream), slog.String("error", err.Error()))
+		return
+	}
+	s.broker.Publish(unexpired(append(res.Events, res.Links...), meta, res.Version))
+}
+
+// unexpired filters out the events hidden by the metadata of their stream,
+// which is at version. Appended events never exceed the maximum age, but may
+// be truncated right away.
+func unexpired(events []store.Event, meta store.StreamMetadata, version uint64) []store.Event {
+	min := meta.TruncateBefore
+	if meta.MaxCount > 0 && version >= meta.MaxCount && version-meta.MaxCount+1 > min {
+		min = version - meta.MaxCount + 1
+	}
+	if min == 0 {
+		return events
+	}
+	kept := events[:0]
+	for _, ev := range events {
+		if ev.Version >= min {
+			kept = append(kept, ev)
 		}
+	}
+	return kept
+}
 
-	case frame.FrameKindHeartbeat:
-		// Respond with heartbeat again
-		frm, err := frame.Make(frame.FrameKindHeartbeat)
+func (s *Server) handleReadStream(frm *frame.Frame) (*eventalepb.WireReadStreamResult, error) {
+	var req eventalepb.WireReadStreamRequest
+	if err := proto.Unmarshal(frm.Payload, &req); err != nil {
+		return nil, fmt.Errorf("decode read stream: %v", err)
+	}
+	return s.readStream(context.TODO(), &req)
+}
+
+func (s *Server) readStream(ctx context.Context, req *eventalepb.WireReadStreamRequest) (*eventalepb.WireReadStreamResult, error) {
+	max := int(req.MaxCount)
+	if max == 0 || max > _readPageSize {
+		max = _readPageSize
+	}
+	events, err := s.store.ReadStream(ctx, req.Stream, req.FromVersion, max)
+	if err != nil {
+		return nil, err
+	}
+	if req.AsOf != 0 {
+		// Events of a stream are ordered by position, so the events
+		// committed later are at the end
+		last, err := s.store.PositionAt(ctx, time.Unix(0, req.AsOf))
 		if err != nil {
-			return fmt.Errorf("frame make: %v", err)
+			return nil, err
 		}
-		if err := conn.Send(context.TODO(), frm); err != nil {
-			return fmt.Errorf("conn send: %v", err)
+		for i, ev := range events {
+			if ev.Position > last {
+				events = events[:i]
+				break
+			}
 		}
-	case frame.FrameKindSecretPublish:
+	}
+	res := &eventalepb.WireReadStreamResult{Events: make([]*eventalepb.WireEvent, len(events))}
+	for i, ev := range events {
+		res.Events[i] = eventToWire(ev)
+	}
+	return res, nil
+}
+
+func (s *Server) handleWriteSnapshot(frm *frame.Frame) error {
+	var req eventalepb.WireWriteSnapshotRequest
+	if err := proto.Unmarshal(frm.Payload, &req); err != nil {
+		return fmt.Errorf("decode write snapshot: %v", err)
+	}
+	snap := req.Snapshot
+	if snap == nil || snap.State == nil || snap.State.Type == "" {
+		return fmt.Errorf("snapshot without state: %w", errBadRequest)
+	}
+	return s.store.WriteSnapshot(context.TODO(), store.Snapshot{
+		Stream:      snap.Stream,
+		Version:     snap.Version,
+		Type:        snap.State.Type,
+		ContentType: snap.State.ContentType,
+		Data:        snap.State.Data,
+	})
+}
+
+func (s *Server) handleReadSnapshot(frm *frame.Frame) (*eventalepb.WireReadSnapshotResult, error) {
+	var req eventalepb.WireReadSnapshotRequest
+	if err := proto.Unmarshal(frm.Payload, &req); err != nil {
+		return nil, fmt.Errorf("decode read snapshot: %v", err)
+	}
+	snap, ok, err := s.store.ReadSnapshot(context.TODO(), req.Stream)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return &eventalepb.WireReadSnapshotResult{}, nil
+	}
+	return &eventalepb.WireReadSnapshotResult{
+		Snapshot: &eventalepb.WireSnapshot{
+			Stream:  snap.Stream,
+			Version: snap.Version,
+			State: &eventalepb.WireEventData{
+				Type:        snap.Type,
+				ContentType: snap.ContentType,
+				Data:        snap.Data,
+			},
+		},
+	}, nil
+}
+
+func (s *Server) handleDeleteStream(frm *frame.Frame) (*eventalepb.WireStreamDeleted, error) {
+	var req eventalepb.WireDeleteStreamRequest
+	if err := proto.Unmarshal(frm.Payload, &req); err != nil {
+		return nil, fmt.Errorf("decode delete stream: %v: %w", err, errBadRequest)
+	}
+	return s.deleteStream(context.TODO(), &req)
+}
+
+func (s *Server) deleteStream(ctx context.Context, req *eventalepb.WireDeleteStreamRequest) (*eventalepb.WireStreamDeleted, error) {
+	// Deletions are published in order with appends, so subscribers are
+	// notified after the last event of the stream.
+	s.appendMu.Lock()
+	defer s.appendMu.Unlock()
+	version, err := s.store.DeleteStream(ctx, req.Stream, req.ExpectedVersion, req.Hard)
+	if err != nil {
+		return nil, err
+	}
+	s.broker.PublishDeletion(broker.Deletion{Stream: req.Stream, Version: version, Hard: req.Hard})
+	return &eventalepb.WireStreamDeleted{Stream: req.Stream, Version: version, Hard: req.Hard}, nil
+}
+
+func (s *Server) handleWriteStreamMetadata(frm *frame.Frame) error {
+	var req eventalepb.WireStreamMetadata
+	if err := proto.Unmarshal(frm.Payload, &req); err != nil {
+		return fmt.Errorf("decode write stream metadata: %v: %w", err, errBadRequest)
+	}
+	return s.writeStreamMetadata(context.TODO(), &req)
+}
+
+func (s *Server) writeStreamMetadata(ctx context.Context, req *eventalepb.WireStreamMetadata) error {
+	return s.store.WriteStreamMetadata(ctx, req.Stream, store.StreamMetadata{
+		MaxAge:         time.Duration(req.MaxAge) * time.Millisecond,
+		MaxCount:       req.MaxCount,
+		TruncateBefore: req.TruncateBefore,
+		Custom:         req.Custom,
+	})
+}
+
+func (s *Server) handleReadStreamMetadata(frm *frame.Frame) (*eventalepb.WireStreamMetadata, error) {
+	var req eventalepb.WireReadStreamMetadataRequest
+	if err := proto.Unmarshal(frm.Payload, &req); err != nil {
+		return nil, fmt.Errorf("decode read stream metadata: %v: %w", err, errBadRequest)
+	}
+	return s.readStreamMetadata(context.TODO(), req.Stream)
+}
+
+func (s *Server) readStreamMetadata(ctx context.Context, stream string) (*eventalepb.WireStreamMetadata, error) {
+	meta, err := s.store.ReadStreamMetadata(ctx, stream)
+	if err != nil {
+		return nil, err
+	}
+	return &eventalepb.WireStreamMetadata{
+		Stream:         stream,
+		MaxAge:         uint64(meta.MaxAge / time.Millisecond),
+		MaxCount:       meta.MaxCount,
+		TruncateBefore: meta.TruncateBefore,
+		Custom:         meta.Custom,
+	}, nil
+}
+
+func (s *Server) handleSubscribe(sess *session, frm *frame.Frame) error {
+	var req eventalepb.WireSubscribeRequest
+	if err := proto.Unmarshal(frm.Payload, &req); err != nil {
+		return s.respond(sess, frm, 0, nil, fmt.Errorf("decode subscribe: %v: %w", err, errBadRequest))
+	}
+	if frm.ID == nil {
+		return s.respond(sess, frm, 0, nil, fmt.Errorf("subscribe without id: %w", errBadRequest))
+	}
+
+	// Register with the broker before catching up, so no events appended in
+	// between are missed.
+	stream := req.Stream
+	if stream == AllStreams {
+		stream = ""
+	}
+	sub := s.broker.Subscribe(stream, s.subscriptionBuffer())
+	ctx, cancel := context.WithCancel(context.Background())
+	active := &serverSubscription{
+		stream: stream,
+		from:   req.From,
+		batch:  min(max(int(req.MaxBatch), 1), _maxSubscriptionBatch),
+		credit: newCredit(req.Credit),
+		cancel: cancel,
+		sink:   &frameSink{conn: sess.conn, id: frm.ID, single: req.MaxBatch <= 1},
+	}
+	sess.mu.Lock()
+	sess.subs[frm.ID.String()] = active
+	sess.mu.Unlock()
+
+	if err := s.respond(sess, frm, frame.FrameKindSubscribed, nil, nil); err != nil {
+		cancel()
+		s.broker.Unsubscribe(sub)
+		return err
+	}
+
+	go func() {
+		defer func() {
+			s.broker.Unsubscribe(sub)
+			sess.mu.Lock()
+			delete(sess.subs, frm.ID.String())
+			sess.mu.Unlock()
+			cancel()
+		}()
+		if err := s.runSubscription(ctx, active, sub); err != nil && ctx.Err() == nil {
+			s.Logger.Error("Subscription failed", slog.String("error", err.Error()))
+			s.respond(sess, &frame.Frame{ID: frm.ID}, 0, nil, err)
+		}
+	}()
+	return nil
+}
+
+// serverSubscription is a subscription of a session.
+type serverSubscription struct {
+	stream string
+	// from is set when the subscription catches up on events after it.
+	from *uint64
+	// batch is the maximum number of events pushed per frame.
+	batch  int
+	credit *credit
+	cancel context.CancelFunc
+	sink   subscriptionSink
+}
+
+// subscriptionSink delivers the events of a subscription to its client, over
+// whichever protocol it subscribed with.
+type subscriptionSink interface {
+	// push delivers events, no more than the batch size of the subscription.
+	push(ctx context.Context, events []store.Event) error
+	// deleted notifies the client of a deleted stream.
+	deleted(ctx context.Context, del *eventalepb.WireStreamDeleted) error
+}
+
+// frameSink pushes events in frames responding to the subscribe frame. Frames
+// are queued without waiting for each to be written, so they can be coalesced
+// into fewer writes.
+type frameSink struct {
+	conn *connection.Conn
+	id   internal.ID
+	// single is set for clients receiving a single event per frame.
+	single bool
+}
 
+func (fs *frameSink) push(ctx context.Context, events []store.Event) error {
+	var msg proto.Message
+	kind := frame.FrameKindSubscriptionEvents
+	if fs.single {
+		kind, msg = frame.FrameKindSubscriptionEvent, &eventalepb.WireSubscriptionEvent{Event: eventToWire(events[0])}
+	} else {
+		pb := &eventalepb.WireSubscriptionEvents{Events: make([]*eventalepb.WireEvent, len(events))}
+		for i, ev := range events {
+			pb.Events[i] = eventToWire(ev)
+		}
+		msg = pb
+	}
+	frm, err := frame.Make(kind, frame.WithRespondTo(fs.id), frame.WithProto(msg))
+	if err != nil {
+		return err
+	}
+	return fs.conn.Queue(ctx, frm)
+}
+
+func (fs *frameSink) deleted(ctx context.Context, del *eventalepb.WireStreamDeleted) error {
+	frm, err := frame.Make(frame.FrameKindStreamDeleted, frame.WithRespondTo(fs.id), frame.WithProto(del))
+	if err != nil {
+		return err
+	}
+	return fs.conn.Queue(ctx, frm)
+}
+
+// handleSubscriptionCredit grants credit to the subscription frm responds to.
+// Credit for subscriptions which already ended is ignored.
+func (s *Server) handleSubscriptionCredit(sess *session, frm *frame.Frame) error {
+	var req eventalepb.WireSubscriptionCredit
+	if err := proto.Unmarshal(frm.Payload, &req); err != nil {
+		return fmt.Errorf("decode subscription credit: %v", err)
+	}
+	if frm.RespondsTo == nil {
+		return fmt.Errorf("subscription credit without subscription id")
+	}
+	sess.mu.Lock()
+	sub, ok := sess.subs[frm.RespondsTo.String()]
+	sess.mu.Unlock()
+	if ok {
+		sub.credit.grant(int(req.Credit))
 	}
 	return nil
 }
 
+// runSubscription pushes events to the sink of as until ctx is done. If from is
+// set, events after it are read from storage first. Up to batch events are
+// pushed per frame, when the client supports it, and never more than the
+// client granted credit for.
+//
+// Live events are buffered until pushed. When the subscription falls too far
+// behind, it is either dropped or switched to catch up from storage again,
+// depending on the SlowSubscriptions policy.
+func (s *Server) runSubscription(ctx context.Context, as *serverSubscription, sub *broker.Subscriber) error {
+	// Events are identified by their version when subscribing to a stream,
+	// and by their position when subscribing to all streams.
+	key := func(ev store.Event) uint64 {
+		if as.stream == "" {
+			return ev.Position
+		}
+		return ev.Seq()
+	}
+	send := func(events []store.Event) error {
+		for len(events) > 0 {
+			n, err := as.credit.take(ctx, min(len(events), as.batch))
+			if err != nil {
+				return err
+			}
+			if err := as.sink.push(ctx, events[:n]); err != nil {
+				return err
+			}
+			events = events[n:]
+		}
+		return nil
+	}
+
+	// last is the key of the last event pushed, once known is set. Live
+	// events up to it were already read from storage, and are skipped.
+	var (
+		last  uint64
+		known bool
+	)
+	catchUp := func() error {
+		known = true
+		for {
+			var (
+				events []store.Event
+				err    error
+			)
+			if as.stream == "" {
+				events, err = s.store.ReadAll(ctx, last, _readPageSize)
+			} else {
+				events, err = s.store.ReadStream(ctx, as.stream, last, _readPageSize)
+			}
+			if err != nil {
+				return err
+			}
+			if err := send(events); err != nil {
+				return err
+			}
+			if len(events) > 0 {
+				last = key(events[len(events)-1])
+			}
+			if len(events) < _readPageSize {
+				return nil
+			}
+		}
+	}
+	if as.from != nil {
+		last = *as.from
+		if err := catchUp(); err != nil {
+			return err
+		}
+	}
+
+	var events []store.Event
+	for {
+		msgs, err := sub.NextBatch(ctx, as.batch)
+		var overflow *broker.OverflowError
+		if errors.As(err, &overflow) {
+			// Deletions are not kept in storage, so the subscription can
+			// not catch up on them
+			if s.SlowSubscriptions == DropSlowSubscriptions || overflow.Deletions > 0 {
+				return fmt.Errorf("subscription fell more than %d events behind: %w", s.subscriptionBuffer(), ErrSubscriptionTooSlow)
+			}
+			// Read the dropped events from storage, starting after the last
+			// event pushed, or right before the first dropped
+			s.Logger.Info("Subscription fell behind, catching up from storage", slog.String("stream", as.stream))
+			if !known {
+				last = key(overflow.First) - 1
+			}
+			if err := catchUp(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		events = events[:0]
+		for _, msg := range msgs {
+			if del := msg.Deletion; del != nil {
+				// Events before the deletion are pushed first
+				if err := send(events); err != nil {
+					return err
+				}
+				events = events[:0]
+				if err := as.sink.deleted(ctx, &eventalepb.WireStreamDeleted{
+					Stream:  del.Stream,
+					Version: del.Version,
+					Hard:    del.Hard,
+				}); err != nil {
+					return err
+				}
+				continue
+			}
+			ev := msg.Event
+			if known && key(ev) <= last {
+				continue
+			}
+			events = append(events, ev)
+			last, known = key(ev), true
+		}
+		if err := send(events); err != nil {
+			return err
+		}
+	}
+}
+
+var errBadRequest = errors.New("bad request")
+
+// respond replies to req with a frame of kind carrying msg, or an error frame
+// if err is non-nil.
+func (s *Server) respond(sess *session, req *frame.Frame, kind frame.FrameKind, msg proto.Message, err error) error {
+	if err != nil {
+		kind = frame.FrameKindError
+		msg = errorToWire(err)
+	}
+	frm, ferr := frame.Make(kind, frame.WithID(uuid.IDer), frame.WithRespondTo(req.ID), frame.WithProto(msg))
+	if ferr != nil {
+		return fmt.Errorf("frame make: %v", ferr)
+	}
+	if serr := sess.conn.Send(context.TODO(), frm); serr != nil {
+		return fmt.Errorf("conn send: %v", serr)
+	}
+	return err
+}
+
+func errorToWire(err error) *eventalepb.WireError {
+	code := eventalepb.WireErrorCode_WIRE_ERROR_CODE_UNKNOWN
+	switch {
+	case errors.Is(err, store.ErrWrongExpectedVersion):
+		code = eventalepb.WireErrorCode_WIRE_ERROR_CODE_WRONG_EXPECTED_VERSION
+	case errors.Is(err, frame.ErrFrameTooLarge):
+		code = eventalepb.WireErrorCode_WIRE_ERROR_CODE_FRAME_TOO_LARGE
+	case errors.Is(err, ErrSubscriptionTooSlow):
+		code = eventalepb.WireErrorCode_WIRE_ERROR_CODE_SUBSCRIPTION_TOO_SLOW
+	case errors.Is(err, ErrTransactionNotFound):
+		code = eventalepb.WireErrorCode_WIRE_ERROR_CODE_TRANSACTION_NOT_FOUND
+	case errors.Is(err, store.ErrStreamDeleted):
+		code = eventalepb.WireErrorCode_WIRE_ERROR_CODE_STREAM_DELETED
+	case errors.Is(err, ErrUnauthorized):
+		code = eventalepb.WireErrorCode_WIRE_ERROR_CODE_UNAUTHORIZED
+	case errors.Is(err, ErrLeaseLost):
+		code = eventalepb.WireErrorCode_WIRE_ERROR_CODE_LEASE_LOST
+	case errors.Is(err, errBadRequest), errors.Is(err, store.ErrInvalidStream), errors.Is(err, store.ErrInvalidSnapshot),
+		errors.Is(err, store.ErrEventIDConflict):
+		code = eventalepb.WireErrorCode_WIRE_ERROR_CODE_BAD_REQUEST
+	}
+	pb := &eventalepb.WireError{Code: code, Message: err.Error()}
+	var werr *store.WrongExpectedVersionError
+	if errors.As(err, &werr) {
+		pb.ActualVersion = werr.Actual
+	}
+	return pb
+}
+
+func eventToWire(ev store.Event) *eventalepb.WireEvent {
+	pb := &eventalepb.WireEvent{
+		Stream:      ev.Stream,
+		Version:     ev.Version,
+		Position:    ev.Position,
+		Type:        ev.Type,
+		ContentType: ev.ContentType,
+		Data:        ev.Data,
+		Subject:     ev.Subject,
+		Shredded:    ev.Shredded,
+		Id:          ev.ID,
+		Metadata:    metadataToWire(ev.Metadata),
+		Committed:   ev.Committed.UnixNano(),
+	}
+	if ev.Link != nil {
+		pb.Link = &eventalepb.WireLink{Stream: ev.Link.Stream, Version: ev.Link.Version}
+	}
+	return pb
+}
+
+func metadataToWire(md store.Metadata) *eventalepb.WireEventMetadata {
+	if md.CorrelationID == "" && md.CausationID == "" && md.Principal == "" && len(md.Headers) == 0 {
+		return nil
+	}
+	return &eventalepb.WireEventMetadata{
+		CorrelationId: md.CorrelationID,
+		CausationId:   md.CausationID,
+		Principal:     md.Principal,
+		Headers:       md.Headers,
+	}
+}
+
+func metadataToStore(pb *eventalepb.WireEventMetadata) store.Metadata {
+	return store.Metadata{
+		CorrelationID: pb.GetCorrelationId(),
+		CausationID:   pb.GetCausationId(),
+		Principal:     pb.GetPrincipal(),
+		Headers:       pb.GetHeaders(),
+	}
+}
+
 func (s *Server) readState() serverStatus {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -0,0 +1,35 @@
+package eventale
+
+import (
+	"context"
+
+	eventalepb "github.com/nohns/eventale/gen/v1"
+	"github.com/nohns/eventale/internal/frame"
+	"github.com/nohns/eventale/internal/uuid"
+)
+
+// DataSubject is implemented by event types holding personal data of a data
+// subject, such as a customer. The server encrypts the data of such events
+// with a key of the subject, which is destroyed by Client.ForgetSubject. The
+// whole data of an event is encrypted rather than single fields, so all of
+// it is forgotten with the subject: data to keep, such as order totals,
+// belongs in events without a subject.
+type DataSubject interface {
+	DataSubject() string
+}
+
+// ForgetSubject destroys the key of subject on the server. The data of events
+// of the subject can no longer be decrypted, so they are read as shredded
+// events without data, while the rest of the log stays intact. Events
+// appended for the subject afterwards are encrypted with a new key. Snapshots
+// of streams with events of the subject are deleted, so aggregates are
+// rebuilt from the shredded events.
+func (c *Client) ForgetSubject(ctx context.Context, subject string) error {
+	req := &eventalepb.WireForgetSubjectRequest{Subject: subject}
+	frm, err := frame.Make(frame.FrameKindForgetSubject, frame.WithID(uuid.IDer), frame.WithProto(req))
+	if err != nil {
+		return err
+	}
+	_, err = c.roundtrip(ctx, frm)
+	return err
+}
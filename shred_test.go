@@ -0,0 +1,51 @@
+package eventale_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nohns/eventale"
+)
+
+type customerRegistered struct {
+	CustomerID string `json:"customerId"`
+	Email      string `json:"email"`
+}
+
+func (e *customerRegistered) DataSubject() string { return e.CustomerID }
+
+func TestForgetSubject(t *testing.T) {
+	ctx := context.Background()
+	_, addr := startServer(t)
+	reg := eventale.NewEventRegistry()
+	reg.RegisterJSON("CustomerRegistered", &customerRegistered{})
+	reg.RegisterJSON("OrderPlaced", &orderPlaced{})
+	c, err := eventale.Dial(addr, eventale.WithLogger(discardLogger), eventale.WithRegistry(reg))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer c.Close()
+
+	c.Append(ctx, "customer-1", 0, &customerRegistered{CustomerID: "1", Email: "a@example.com"}, &orderPlaced{OrderID: "1"})
+	events, err := c.ReadStream(ctx, "customer-1")
+	if err != nil {
+		t.Fatalf("read stream: %v", err)
+	}
+	if ev, ok := events[0].Value.(*customerRegistered); !ok || ev.Email != "a@example.com" || events[0].Subject != "1" {
+		t.Fatalf("expected decrypted event of subject 1, got %+v", events[0])
+	}
+
+	if err := c.ForgetSubject(ctx, "1"); err != nil {
+		t.Fatalf("forget subject: %v", err)
+	}
+	events, err = c.ReadStream(ctx, "customer-1")
+	if err != nil {
+		t.Fatalf("read stream: %v", err)
+	}
+	if !events[0].Shredded || events[0].Data != nil || events[0].Value != nil {
+		t.Fatalf("expected shredded event, got %+v", events[0])
+	}
+	if _, ok := events[1].Value.(*orderPlaced); !ok || events[1].Shredded {
+		t.Fatalf("expected event without subject to be intact, got %+v", events[1])
+	}
+}
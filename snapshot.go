@@ -0,0 +1,98 @@
+package eventale
+
+import (
+	"context"
+
+	eventalepb "github.com/nohns/eventale/gen/v1"
+	"github.com/nohns/eventale/internal/frame"
+	"github.com/nohns/eventale/internal/uuid"
+)
+
+// Snapshot is the state of an aggregate at a version of its stream. Loading
+// the snapshot and the events after it is equivalent to reading the entire
+// stream.
+type Snapshot struct {
+	Stream string
+	// Version of the stream the state was taken at.
+	Version     uint64
+	Type        string
+	ContentType string
+	// Data is the raw encoded state.
+	Data []byte
+	// Value is the decoded state, or nil when the snapshot type is not
+	// registered in the client's EventRegistry.
+	Value any
+}
+
+// Snapshotter is implemented by aggregates supporting snapshots. The snapshot
+// state must be of a type registered in the client's EventRegistry.
+type Snapshotter interface {
+	// Snapshot returns the current state of the aggregate.
+	Snapshot() (any, error)
+	// RestoreSnapshot replaces the state of the aggregate with state.
+	RestoreSnapshot(state any) error
+}
+
+// WriteSnapshot stores state as the snapshot of stream at version. state is
+// either an EventData, or a value of a Go type registered in the client's
+// registry. The server keeps only the latest snapshot of each stream.
+func (c *Client) WriteSnapshot(ctx context.Context, stream string, version uint64, state any) error {
+	data, err := c.registry.encode(state)
+	if err != nil {
+		return err
+	}
+	req := &eventalepb.WireWriteSnapshotRequest{
+		Snapshot: &eventalepb.WireSnapshot{
+			Stream:  stream,
+			Version: version,
+			State: &eventalepb.WireEventData{
+				Type:        data.Type,
+				ContentType: data.ContentType,
+				Data:        data.Data,
+			},
+		},
+	}
+	frm, err := frame.Make(frame.FrameKindWriteSnapshot, frame.WithID(uuid.IDer), frame.WithProto(req))
+	if err != nil {
+		return err
+	}
+	_, err = c.roundtrip(ctx, frm)
+	return err
+}
+
+// LoadSnapshot reads the latest snapshot of stream and the events appended
+// after it. If no snapshot was written, the snapshot is nil and all events of
+// the stream are returned.
+func (c *Client) LoadSnapshot(ctx context.Context, stream string) (*Snapshot, []*Event, error) {
+	var res eventalepb.WireReadSnapshotResult
+	req := &eventalepb.WireReadSnapshotRequest{Stream: stream}
+	if err := c.call(ctx, frame.FrameKindReadSnapshot, req, frame.FrameKindReadSnapshotResult, &res); err != nil {
+		return nil, nil, err
+	}
+
+	var snap *Snapshot
+	if pb := res.Snapshot; pb != nil {
+		snap = &Snapshot{
+			Stream:      pb.Stream,
+			Version:     pb.Version,
+			Type:        pb.State.GetType(),
+			ContentType: pb.State.GetContentType(),
+			Data:        pb.State.GetData(),
+		}
+		v, err := c.registry.decodeData(snap.Type, snap.Data)
+		if err != nil {
+			return nil, nil, err
+		}
+		snap.Value = v
+	}
+
+	var from uint64
+	if snap != nil {
+		from = snap.Version
+	}
+	events, err := c.ReadStream(ctx, stream, FromVersion(from))
+	if err != nil {
+		return nil, nil, err
+	}
+	return snap, events, nil
+}
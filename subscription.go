@@ -0,0 +1,243 @@
+package eventale
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	eventalepb "github.com/nohns/eventale/gen/v1"
+	"github.com/nohns/eventale/internal/frame"
+	"google.golang.org/protobuf/proto"
+)
+
+// Subscription receives events pushed by the server. Events are queued until
+// received with Recv. The server only pushes as many events as the
+// subscription has credit for, which is granted again as events are received,
+// so a slow receiver does not queue events without bound.
+type Subscription struct {
+	c   *Client
+	frm *frame.Frame
+	// credit is the number of events the server may push before more is
+	// granted, and received counts the events received since last granted.
+	credit   int
+	received int
+
+	mu     sync.Mutex
+	queue  []*Event
+	err    error
+	notify chan struct{}
+}
+
+func newSubscription(c *Client, frm *frame.Frame, credit int) *Subscription {
+	return &Subscription{
+		c:      c,
+		frm:    frm,
+		credit: credit,
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// Recv returns the next event of the subscription, blocking until one is
+// available. Once the subscription ends, the error that ended it is returned.
+func (s *Subscription) Recv(ctx context.Context) (*Event, error) {
+	for {
+		s.mu.Lock()
+		if len(s.queue) > 0 {
+			ev := s.queue[0]
+			s.queue = s.queue[1:]
+			grant := s.receive(ev)
+			s.mu.Unlock()
+			if grant > 0 {
+				s.grant(grant)
+			}
+			return ev, nil
+		}
+		err := s.err
+		s.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+
+		select {
+		case <-s.notify:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Close ends the subscription. Events already queued can still be received.
+func (s *Subscription) Close() error {
+	s.c.mu.Lock()
+	_, active := s.c.subs[s.frm.ID.String()]
+	delete(s.c.subs, s.frm.ID.String())
+	s.c.mu.Unlock()
+	s.fail(ErrSubscriptionClosed)
+	if !active {
+		return nil
+	}
+
+	frm, err := frame.Make(frame.FrameKindUnsubscribe, frame.WithRespondTo(s.frm.ID))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), _networkTimeout)
+	defer cancel()
+	return s.c.conn.Send(ctx, frm)
+}
+
+// handleFrame queues the events carried by frm, or ends the subscription if
+// frm is an error.
+func (s *Subscription) handleFrame(frm *frame.Frame) {
+	switch frm.Kind {
+	case frame.FrameKindSubscriptionEvents:
+		var pb eventalepb.WireSubscriptionEvents
+		if err := proto.Unmarshal(frm.Payload, &pb); err != nil {
+			s.end(fmt.Errorf("decode subscription events: %v", err))
+			return
+		}
+		events := make([]*Event, len(pb.Events))
+		for i, wev := range pb.Events {
+			events[i] = eventFromWire(wev)
+			if err := s.c.registry.decode(events[i]); err != nil {
+				s.end(err)
+				return
+			}
+		}
+		s.push(events...)
+	case frame.FrameKindSubscriptionEvent:
+		var pb eventalepb.WireSubscriptionEvent
+		if err := proto.Unmarshal(frm.Payload, &pb); err != nil {
+			s.end(fmt.Errorf("decode subscription event: %v", err))
+			return
+		}
+		ev := eventFromWire(pb.Event)
+		if err := s.c.registry.decode(ev); err != nil {
+			s.end(err)
+			return
+		}
+		s.push(ev)
+	case frame.FrameKindStreamDeleted:
+		var pb eventalepb.WireStreamDeleted
+		if err := proto.Unmarshal(frm.Payload, &pb); err != nil {
+			s.end(fmt.Errorf("decode stream deleted: %v", err))
+			return
+		}
+		s.push(&Event{
+			Stream:  pb.Stream,
+			Version: pb.Version,
+			Type:    StreamDeletedEventType,
+			Value:   &StreamDeleted{Stream: pb.Stream, Version: pb.Version, Hard: pb.Hard},
+		})
+	case frame.FrameKindError:
+		s.end(errorFromFrame(frm))
+	}
+}
+
+// receive counts ev as received, returning the credit to grant the server.
+// Credit is granted once half of it was used, so the server can keep pushing
+// while the queue is drained. mu must be held.
+func (s *Subscription) receive(ev *Event) int {
+	if s.credit <= 0 || ev.Type == StreamDeletedEventType {
+		return 0
+	}
+	s.received++
+	if s.received < max(s.credit/2, 1) {
+		return 0
+	}
+	grant := s.received
+	s.received = 0
+	return grant
+}
+
+// grant grants the server credit for n more events. The subscription stalls
+// if credit is lost, so the frame is queued without a context which could
+// make the connection drop it.
+func (s *Subscription) grant(n int) {
+	frm, err := frame.Make(frame.FrameKindSubscriptionCredit, frame.WithRespondTo(s.frm.ID), frame.WithProto(&eventalepb.WireSubscriptionCredit{
+		Credit: uint32(n),
+	}))
+	if err == nil {
+		err = s.c.conn.Queue(context.Background(), frm)
+	}
+	if err != nil {
+		s.c.logger.Debug("grant subscription credit failed", slog.String("error", err.Error()))
+	}
+}
+
+func (s *Subscription) push(events ...*Event) {
+	s.mu.Lock()
+	s.queue = append(s.queue, events...)
+	s.mu.Unlock()
+	s.wake()
+}
+
+// end removes the subscription from the client and fails it with err.
+func (s *Subscription) end(err error) {
+	s.c.mu.Lock()
+	delete(s.c.subs, s.frm.ID.String())
+	s.c.mu.Unlock()
+	s.fail(err)
+}
+
+func (s *Subscription) fail(err error) {
+	s.mu.Lock()
+	if s.err == nil {
+		s.err = err
+	}
+	s.mu.Unlock()
+	s.wake()
+}
+
+func (s *Subscription) wake() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// After makes a subscription catch up on events after v before receiving live
+// events. v is a stream version, or a position when subscribing to
+// AllStreams. After(0) receives all events from the start.
+func After(v uint64) subscribeOpt {
+	return subscribeOptFunc(func(opts *subscribeOpts) {
+		opts.after = &v
+	})
+}
+
+// BatchSize sets the maximum number of events the server pushes to the
+// subscription in a single frame. Batching events lets the server keep up
+// with high event rates. Defaults to 256, and BatchSize(1) pushes every event
+// in its own frame.
+func BatchSize(n int) subscribeOpt {
+	return subscribeOptFunc(func(opts *subscribeOpts) {
+		opts.batchSize = n
+	})
+}
+
+// Credit sets the number of events the server may push to the subscription
+// before it receives them. Credit is granted again as events are received
+// with Recv. Defaults to 1024, and Credit(0) lets the server push events
+// without limit.
+func Credit(n int) subscribeOpt {
+	return subscribeOptFunc(func(opts *subscribeOpts) {
+		opts.credit = n
+	})
+}
+
+type subscribeOpts struct {
+	after     *uint64
+	batchSize int
+	credit    int
+}
+
+type subscribeOpt interface {
+	apply(opts *subscribeOpts)
+}
+
+type subscribeOptFunc func(opts *subscribeOpts)
+
+func (f subscribeOptFunc) apply(opts *subscribeOpts) {
+	f(opts)
+}
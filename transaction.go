@@ -0,0 +1,228 @@
+package eventale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	eventalepb "github.com/nohns/eventale/gen/v1"
+	"github.com/nohns/eventale/internal/frame"
+	"github.com/nohns/eventale/internal/store"
+	"github.com/nohns/eventale/internal/uuid"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrTransactionNotFound is returned for requests in a transaction which was
+// already committed or rolled back, or which timed out on the server.
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+// Transaction groups appends to several streams, which are committed
+// atomically: either all appends succeed, or none of them are persisted.
+// Appends are buffered by the server until Commit, so expected versions are
+// checked when committing. The server rolls back transactions without
+// requests for some time, see Server.TransactionTimeout, and rejects appends
+// past Server.MaxTransactionAppends or Server.MaxTransactionSize.
+type Transaction struct {
+	c  *Client
+	id string
+}
+
+// TransactionResult describes the outcome of a committed transaction.
+type TransactionResult struct {
+	// Results of the appends, in the order they were made.
+	Results []AppendResult
+	// FirstPosition and LastPosition are the range of global positions the
+	// events of the transaction were committed at.
+	FirstPosition uint64
+	LastPosition  uint64
+}
+
+// BeginTransaction starts a transaction on the server.
+func (c *Client) BeginTransaction(ctx context.Context) (*Transaction, error) {
+	var res eventalepb.WireTransaction
+	if err := c.call(ctx, frame.FrameKindBeginTransaction, &eventalepb.WireTransaction{}, frame.FrameKindTransactionBegun, &res); err != nil {
+		return nil, err
+	}
+	return &Transaction{c: c, id: res.Id}, nil
+}
+
+// Append adds events to the end of stream when the transaction commits. See
+// Client.Append for the accepted events and expected versions.
+func (tx *Transaction) Append(ctx context.Context, stream string, expectedVersion ExpectedVersion, events ...any) error {
+	req, err := tx.c.appendRequest(ctx, stream, expectedVersion, events)
+	if err != nil {
+		return err
+	}
+	frm, err := frame.Make(frame.FrameKindTransactionAppend, frame.WithID(uuid.IDer), frame.WithProto(&eventalepb.WireTransactionAppendRequest{
+		TransactionId: tx.id,
+		Append:        req,
+	}))
+	if err != nil {
+		return err
+	}
+	_, err = tx.c.roundtrip(ctx, frm)
+	return err
+}
+
+// Commit persists all appends of the transaction atomically. The transaction
+// is ended, even if committing fails.
+func (tx *Transaction) Commit(ctx context.Context) (*TransactionResult, error) {
+	var res eventalepb.WireTransactionCommitted
+	req := &eventalepb.WireTransaction{Id: tx.id}
+	if err := tx.c.call(ctx, frame.FrameKindCommitTransaction, req, frame.FrameKindTransactionCommitted, &res); err != nil {
+		return nil, err
+	}
+	result := &TransactionResult{
+		Results:       make([]AppendResult, len(res.Results)),
+		FirstPosition: res.FirstPosition,
+		LastPosition:  res.LastPosition,
+	}
+	for i, r := range res.Results {
+		result.Results[i] = AppendResult{Version: r.Version, Position: r.Position}
+	}
+	return result, nil
+}
+
+// Rollback discards all appends of the transaction.
+func (tx *Transaction) Rollback(ctx context.Context) error {
+	frm, err := frame.Make(frame.FrameKindRollbackTransaction, frame.WithID(uuid.IDer), frame.WithProto(&eventalepb.WireTransaction{Id: tx.id}))
+	if err != nil {
+		return err
+	}
+	_, err = tx.c.roundtrip(ctx, frm)
+	return err
+}
+
+// transaction is a transaction of a session, buffering appends until it is
+// committed.
+type transaction struct {
+	appends []store.StreamAppend
+	// size is the total size of the appends on the wire.
+	size int
+	// timer rolls back the transaction when it expires.
+	timer *time.Timer
+}
+
+func (s *Server) transactionTimeout() time.Duration {
+	if s.TransactionTimeout > 0 {
+		return s.TransactionTimeout
+	}
+	return _defaultTransactionTimeout
+}
+
+func (s *Server) maxTransactionAppends() int {
+	if s.MaxTransactionAppends > 0 {
+		return s.MaxTransactionAppends
+	}
+	return _defaultMaxTransactionAppends
+}
+
+func (s *Server) maxTransactionSize() int {
+	if s.MaxTransactionSize > 0 {
+		return s.MaxTransactionSize
+	}
+	return _defaultMaxTransactionSize
+}
+
+func (s *Server) handleBeginTransaction(sess *session) (*eventalepb.WireTransaction, error) {
+	id, err := uuid.Gen()
+	if err != nil {
+		return nil, fmt.Errorf("generate transaction id: %v", err)
+	}
+	txid := id.String()
+	tx := &transaction{}
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	tx.timer = time.AfterFunc(s.transactionTimeout(), func() {
+		sess.mu.Lock()
+		defer sess.mu.Unlock()
+		if sess.txs[txid] == tx {
+			delete(sess.txs, txid)
+			s.Logger.Info("Transaction timed out", slog.String("transaction", txid))
+		}
+	})
+	sess.txs[txid] = tx
+	return &eventalepb.WireTransaction{Id: txid}, nil
+}
+
+func (s *Server) handleTransactionAppend(sess *session, frm *frame.Frame) error {
+	var req eventalepb.WireTransactionAppendRequest
+	if err := proto.Unmarshal(frm.Payload, &req); err != nil {
+		return fmt.Errorf("decode transaction append: %v: %w", err, errBadRequest)
+	}
+	if req.Append == nil {
+		return fmt.Errorf("transaction append without append: %w", errBadRequest)
+	}
+	a, err := s.streamAppend(sess.authPrincipal(), req.Append)
+	if err != nil {
+		return err
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	tx, ok := sess.txs[req.TransactionId]
+	if !ok {
+		return fmt.Errorf("%s: %w", req.TransactionId, ErrTransactionNotFound)
+	}
+	tx.timer.Reset(s.transactionTimeout())
+	if len(tx.appends) >= s.maxTransactionAppends() {
+		return fmt.Errorf("transaction %s exceeds %d appends: %w", req.TransactionId, s.maxTransactionAppends(), errBadRequest)
+	}
+	size := proto.Size(req.Append)
+	if tx.size+size > s.maxTransactionSize() {
+		return fmt.Errorf("transaction %s exceeds %d bytes: %w", req.TransactionId, s.maxTransactionSize(), errBadRequest)
+	}
+	tx.appends = append(tx.appends, a)
+	tx.size += size
+	return nil
+}
+
+func (s *Server) handleCommitTransaction(sess *session, frm *frame.Frame) (*eventalepb.WireTransactionCommitted, error) {
+	tx, err := s.endTransaction(sess, frm)
+	if err != nil {
+		return nil, err
+	}
+
+	s.appendMu.Lock()
+	defer s.appendMu.Unlock()
+	results, err := s.store.AppendMulti(context.TODO(), tx.appends)
+	if err != nil {
+		return nil, err
+	}
+	res := &eventalepb.WireTransactionCommitted{Results: make([]*eventalepb.WireAppendResult, len(results))}
+	for i, r := range results {
+		s.publish(tx.appends[i].Stream, r)
+		res.Results[i] = &eventalepb.WireAppendResult{Version: r.Version, Position: r.Position}
+		for _, ev := range r.Events {
+			if res.FirstPosition == 0 || ev.Position < res.FirstPosition {
+				res.FirstPosition = ev.Position
+			}
+			res.LastPosition = max(res.LastPosition, ev.Position)
+		}
+	}
+	return res, nil
+}
+
+func (s *Server) handleRollbackTransaction(sess *session, frm *frame.Frame) error {
+	_, err := s.endTransaction(sess, frm)
+	return err
+}
+
+// endTransaction removes the transaction requested by frm from sess.
+func (s *Server) endTransaction(sess *session, frm *frame.Frame) (*transaction, error) {
+	var req eventalepb.WireTransaction
+	if err := proto.Unmarshal(frm.Payload, &req); err != nil {
+		return nil, fmt.Errorf("decode transaction: %v: %w", err, errBadRequest)
+	}
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	tx, ok := sess.txs[req.Id]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", req.Id, ErrTransactionNotFound)
+	}
+	tx.timer.Stop()
+	delete(sess.txs, req.Id)
+	return tx, nil
+}
@@ -0,0 +1,130 @@
+package eventale_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nohns/eventale"
+)
+
+func TestTransaction(t *testing.T) {
+	ctx := context.Background()
+	_, addr := startServer(t)
+	c := orderClient(t, addr)
+	c.Append(ctx, "order-1", 0, &orderPlaced{OrderID: "1"})
+
+	tx, err := c.BeginTransaction(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	if err := tx.Append(ctx, "order-1", 1, &itemAdded{SKU: "a"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := tx.Append(ctx, "order-2", 0, &orderPlaced{OrderID: "2"}, &itemAdded{SKU: "b"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	// Nothing is visible before committing
+	if events, err := c.ReadStream(ctx, "order-2"); err != nil || len(events) != 0 {
+		t.Fatalf("expected no events before commit, got %+v (err %v)", events, err)
+	}
+	res, err := tx.Commit(ctx)
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if res.FirstPosition != 2 || res.LastPosition != 4 || len(res.Results) != 2 || res.Results[1].Version != 2 {
+		t.Fatalf("unexpected commit result %+v", res)
+	}
+	if _, err := tx.Commit(ctx); !errors.Is(err, eventale.ErrTransactionNotFound) {
+		t.Fatalf("expected ErrTransactionNotFound committing twice, got %v", err)
+	}
+
+	// A conflicting append fails the entire transaction
+	tx, err = c.BeginTransaction(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	tx.Append(ctx, "order-3", 0, &orderPlaced{OrderID: "3"})
+	tx.Append(ctx, "order-1", 1, &itemAdded{SKU: "c"})
+	if _, err := tx.Commit(ctx); !errors.Is(err, eventale.ErrWrongExpectedVersion) {
+		t.Fatalf("expected ErrWrongExpectedVersion, got %v", err)
+	}
+	if events, err := c.ReadStream(ctx, "order-3"); err != nil || len(events) != 0 {
+		t.Fatalf("expected no events after failed commit, got %+v (err %v)", events, err)
+	}
+
+	tx, err = c.BeginTransaction(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	tx.Append(ctx, "order-3", 0, &orderPlaced{OrderID: "3"})
+	if err := tx.Rollback(ctx); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if events, err := c.ReadStream(ctx, "order-3"); err != nil || len(events) != 0 {
+		t.Fatalf("expected no events after rollback, got %+v (err %v)", events, err)
+	}
+}
+
+func TestTransactionTimeout(t *testing.T) {
+	ctx := context.Background()
+	lnr, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := eventale.NewServer(lnr.Addr().String())
+	srv.Logger = discardLogger
+	srv.TransactionTimeout = 50 * time.Millisecond
+	go srv.Serve(lnr)
+	t.Cleanup(func() { srv.Close() })
+	c := orderClient(t, lnr.Addr().String())
+
+	tx, err := c.BeginTransaction(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	tx.Append(ctx, "order-1", 0, &orderPlaced{OrderID: "1"})
+	time.Sleep(200 * time.Millisecond)
+	if _, err := tx.Commit(ctx); !errors.Is(err, eventale.ErrTransactionNotFound) {
+		t.Fatalf("expected ErrTransactionNotFound after timeout, got %v", err)
+	}
+}
+
+func TestTransactionLimits(t *testing.T) {
+	ctx := context.Background()
+	lnr, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := eventale.NewServer(lnr.Addr().String())
+	srv.Logger = discardLogger
+	srv.MaxTransactionAppends = 2
+	srv.MaxTransactionSize = 1 << 10
+	go srv.Serve(lnr)
+	t.Cleanup(func() { srv.Close() })
+	c := orderClient(t, lnr.Addr().String())
+
+	tx, err := c.BeginTransaction(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	if err := tx.Append(ctx, "order-1", 0, &orderPlaced{OrderID: strings.Repeat("1", 2<<10)}); !errors.Is(err, eventale.ErrBadRequest) {
+		t.Fatalf("expected ErrBadRequest past the size limit, got %v", err)
+	}
+	for _, id := range []string{"1", "2"} {
+		if err := tx.Append(ctx, "order-"+id, 0, &orderPlaced{OrderID: id}); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	if err := tx.Append(ctx, "order-3", 0, &orderPlaced{OrderID: "3"}); !errors.Is(err, eventale.ErrBadRequest) {
+		t.Fatalf("expected ErrBadRequest past the append limit, got %v", err)
+	}
+	// Rejected appends leave the transaction usable
+	res, err := tx.Commit(ctx)
+	if err != nil || len(res.Results) != 2 {
+		t.Fatalf("expected 2 appends committed, got %+v (err %v)", res, err)
+	}
+}
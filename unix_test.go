@@ -0,0 +1,70 @@
+package eventale_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/nohns/eventale"
+	"github.com/nohns/eventale/internal/auth"
+)
+
+// startUnixServer serves srv on a Unix socket, returning the address to dial.
+func startUnixServer(t *testing.T, srv *eventale.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "taled.sock")
+	lnr, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go srv.Serve(lnr)
+	return "unix://" + path
+}
+
+func TestUnixSocket(t *testing.T) {
+	ctx := context.Background()
+	srv, addr := startServer(t)
+	c := dial(t, startUnixServer(t, srv))
+
+	if _, err := c.Append(ctx, "order-1", eventale.NoStream, eventale.EventData{Type: "OrderPlaced"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	events, err := dial(t, addr).ReadStream(ctx, "order-1")
+	if err != nil || len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d (err %v)", len(events), err)
+	}
+	if runtime.GOOS != "linux" {
+		return
+	}
+	// Events are appended by the user of the client process
+	if p := events[0].Metadata.Principal; p != auth.UIDPrincipal(uint32(os.Getuid())) {
+		t.Fatalf("expected principal of the current user, got %q", p)
+	}
+}
+
+func TestUnixSocketPeerCredentials(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("SO_PEERCRED is only supported on Linux")
+	}
+	srv, addr := startServer(t)
+	unix := startUnixServer(t, srv)
+	srv.AuthorizeUID(uint32(os.Getuid()) + 1)
+
+	// Only authorized users connect without a key, and never over TCP
+	if _, err := eventale.Dial(unix, eventale.WithLogger(discardLogger)); !errors.Is(err, eventale.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized for another user, got %v", err)
+	}
+	srv.AuthorizeUID(uint32(os.Getuid()))
+	c, err := eventale.Dial(unix, eventale.WithLogger(discardLogger))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	c.Close()
+	if _, err := eventale.Dial(addr, eventale.WithLogger(discardLogger)); !errors.Is(err, eventale.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized over tcp, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package eventale
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/coder/websocket"
+	"github.com/nohns/eventale/internal/connection"
+)
+
+// WebSocketSubprotocol is the WebSocket subprotocol of connections carrying
+// eventale frames. Clients not requesting it are served all the same.
+const WebSocketSubprotocol = "eventale.v1"
+
+// ServeHTTP upgrades the request to a WebSocket connection, and serves it like
+// a TCP connection accepted by Serve until it is closed. Frames are sent in a
+// binary message each, and shared with the TCP protocol, including the
+// handshake. The server can thereby be mounted on an existing HTTP mux.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := s.open(); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	ws, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+		Subprotocols:   []string{WebSocketSubprotocol},
+		OriginPatterns: s.WebSocketOrigins,
+	})
+	if err != nil {
+		// Accept has already responded to the request
+		s.Logger.Warn("Rejecting WebSocket connection", slog.String("remote", r.RemoteAddr), slog.String("error", err.Error()))
+		return
+	}
+
+	c := s.addConn(connection.WebSocket(ws))
+	if c == nil {
+		return
+	}
+	s.Logger.Debug("client connected over WebSocket", slog.Int("id", c.ID))
+	s.listenOnConn(c)
+}
+
+// ListenAndServeWebSocket listens on the TCP address addr, and serves
+// WebSocket connections on it, see ServeWebSocket.
+func (s *Server) ListenAndServeWebSocket(addr string) error {
+	s.Logger.Info("Listening for WebSocket traffic", slog.String("addr", addr))
+	lnr, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.ServeWebSocket(lnr)
+}
+
+// ServeWebSocket accepts HTTP connections on lnr, and serves every request on
+// them with ServeHTTP. It can be used alongside Serve, sharing the events,
+// authorized keys and subscriptions of the server. ServeWebSocket always
+// returns a non-nil error and closes lnr.
+func (s *Server) ServeWebSocket(lnr net.Listener) error {
+	return s.serveHTTP(lnr, s)
+}
+
+// WithWebSocket makes the client connect over WebSocket rather than TCP, for
+// when only HTTP is let through. The address given to Dial is then the ws://
+// or wss:// URL the server is served on.
+func WithWebSocket() dialOpt {
+	return dialOptFunc(func(opts *dialOpts) {
+		opts.dial = dialWebSocket
+	})
+}
+
+func dialWebSocket(ctx context.Context, url string) (net.Conn, error) {
+	ws, _, err := websocket.Dial(ctx, url, &websocket.DialOptions{
+		Subprotocols: []string{WebSocketSubprotocol},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return connection.WebSocket(ws), nil
+}
@@ -0,0 +1,138 @@
+package eventale_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/nohns/eventale"
+	eventalepb "github.com/nohns/eventale/gen/v1"
+	"github.com/nohns/eventale/internal/frame"
+	"github.com/nohns/eventale/internal/uuid"
+	"google.golang.org/protobuf/proto"
+)
+
+// startWebSocketServer serves srv over WebSocket, returning the URL to dial.
+func startWebSocketServer(t *testing.T, srv *eventale.Server) string {
+	t.Helper()
+	hs := httptest.NewServer(srv)
+	t.Cleanup(hs.Close)
+	return "ws" + strings.TrimPrefix(hs.URL, "http")
+}
+
+func TestWebSocket(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	srv, addr := startServer(t)
+	url := startWebSocketServer(t, srv)
+
+	ws, err := eventale.Dial(url, eventale.WithLogger(discardLogger), eventale.WithWebSocket(),
+		eventale.WithCompression(eventale.Zstd), eventale.WithMaxFrameSize(frame.MinMaxFrameSize))
+	if err != nil {
+		t.Fatalf("dial websocket: %v", err)
+	}
+	defer ws.Close()
+	sub, err := ws.Subscribe(ctx, "blob-1")
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	// Events appended over TCP reach subscriptions over WebSocket, and the
+	// other way around, including payloads split into chunks
+	tcp := dial(t, addr)
+	data := make([]byte, 64<<10)
+	rand.Read(data)
+	if _, err := tcp.Append(ctx, "blob-1", eventale.NoStream, eventale.EventData{Type: "Uploaded", Data: data}); err != nil {
+		t.Fatalf("append over tcp: %v", err)
+	}
+	if _, err := ws.Append(ctx, "blob-1", eventale.Exact(1), eventale.EventData{Type: "Uploaded", Data: data}); err != nil {
+		t.Fatalf("append over websocket: %v", err)
+	}
+	for i := 1; i <= 2; i++ {
+		ev, err := sub.Recv(ctx)
+		if err != nil {
+			t.Fatalf("recv: %v", err)
+		}
+		if ev.Version != uint64(i) || !bytes.Equal(ev.Data, data) {
+			t.Fatalf("expected event %d, got version %d with %d bytes", i, ev.Version, len(ev.Data))
+		}
+	}
+	events, err := tcp.ReadStream(ctx, "blob-1")
+	if err != nil || len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d (err %v)", len(events), err)
+	}
+}
+
+func TestWebSocketWithKey(t *testing.T) {
+	srv, _ := startServer(t)
+	url := startWebSocketServer(t, srv)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	srv.AuthorizeKey(&key.PublicKey)
+
+	if _, err := eventale.Dial(url, eventale.WithLogger(discardLogger), eventale.WithWebSocket()); !errors.Is(err, eventale.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized without key, got %v", err)
+	}
+	c, err := eventale.Dial(url, eventale.WithLogger(discardLogger), eventale.WithWebSocket(), eventale.WithKey(key))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer c.Close()
+	if _, err := c.Append(context.Background(), "order-1", eventale.NoStream, eventale.EventData{Type: "A"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+}
+
+func TestWebSocketMessages(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	srv, _ := startServer(t)
+	url := startWebSocketServer(t, srv)
+
+	conn, _, err := websocket.Dial(ctx, url, &websocket.DialOptions{Subprotocols: []string{eventale.WebSocketSubprotocol}})
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.CloseNow()
+	if conn.Subprotocol() != eventale.WebSocketSubprotocol {
+		t.Fatalf("expected subprotocol %q, got %q", eventale.WebSocketSubprotocol, conn.Subprotocol())
+	}
+
+	// A frame sent in a binary message is answered with a frame in a
+	// message of its own
+	hello, err := frame.Make(frame.FrameKindClientHello, frame.WithID(uuid.IDer), frame.WithProto(&eventalepb.WireClientHello{}))
+	if err != nil {
+		t.Fatalf("make: %v", err)
+	}
+	var msg bytes.Buffer
+	if err := frame.NewEncoder(&msg, nil).Encode(hello); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if err := conn.Write(ctx, websocket.MessageBinary, msg.Bytes()); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	typ, b, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	r := bytes.NewReader(b)
+	res, err := frame.NewDecoder(r, nil).Decode()
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if typ != websocket.MessageBinary || res.Kind != frame.FrameKindServerHello || r.Len() != 0 {
+		t.Fatalf("expected a single server hello in a binary message, got %v frame %d with %d bytes left", typ, res.Kind, r.Len())
+	}
+	if err := proto.Unmarshal(res.Payload, new(eventalepb.WireServerHello)); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+}